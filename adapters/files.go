@@ -27,14 +27,14 @@ func (c FilesAPIClient) UploadFile(ctx context.Context, fileID, fileContent stri
 		return fmt.Errorf("failed to put file content: %w", err)
 	}
 
-	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
-		return fmt.Errorf("failed to put file content: unexpected status code %d", resp.StatusCode())
-	}
-
 	if resp.StatusCode() == http.StatusConflict {
 		log.FromContext(ctx).With("file", fileID).Info("file already exists")
 		return nil
 	}
 
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("failed to put file content: unexpected status code %d", resp.StatusCode())
+	}
+
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"tickets/entities"
 
 	"github.com/ThreeDotsLabs/go-event-driven/v2/common/clients"
 	"github.com/ThreeDotsLabs/go-event-driven/v2/common/clients/payments"
@@ -22,12 +23,13 @@ func NewPaymentsServiceClient(clients *clients.Clients) *PaymentsServiceClient {
 	return &PaymentsServiceClient{clients: clients}
 }
 
-func (c PaymentsServiceClient) RefundPayment(ctx context.Context, ticketID, idempotencyKey string) error {
+func (c PaymentsServiceClient) RefundPayment(ctx context.Context, request entities.RefundPaymentRequest) error {
 	resp, err := c.clients.Payments.PutRefundsWithResponse(ctx, payments.PaymentRefundRequest{
 		// we use TicketID as the payment reference
-		PaymentReference: ticketID,
-		Reason:           "customer requested refund",
-		DeduplicationId:  &idempotencyKey,
+		PaymentReference: request.TicketID,
+		// payments API has no amount field, the partial amount is a part of the reason
+		Reason:          entities.RefundDescription(request.Reason, request.Amount),
+		DeduplicationId: &request.IdempotencyKey,
 	})
 
 	if err != nil {
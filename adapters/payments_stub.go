@@ -3,6 +3,7 @@ package adapters
 import (
 	"context"
 	"sync"
+	"tickets/entities"
 )
 
 type PaymentsServiceStub struct {
@@ -11,10 +12,7 @@ type PaymentsServiceStub struct {
 	usedIdempotencyKeys map[string]struct{}
 }
 
-type RefundPaymentRequest struct {
-	TicketID       string
-	IdempotencyKey string
-}
+type RefundPaymentRequest = entities.RefundPaymentRequest
 
 func NewPaymentsServiceStub() *PaymentsServiceStub {
 	return &PaymentsServiceStub{
@@ -22,19 +20,16 @@ func NewPaymentsServiceStub() *PaymentsServiceStub {
 	}
 }
 
-func (s *PaymentsServiceStub) RefundPayment(ctx context.Context, ticketID, idempotencyKey string) error {
+func (s *PaymentsServiceStub) RefundPayment(ctx context.Context, request entities.RefundPaymentRequest) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	if _, exists := s.usedIdempotencyKeys[idempotencyKey]; exists {
+	if _, exists := s.usedIdempotencyKeys[request.IdempotencyKey]; exists {
 		return nil
 	}
 
-	s.usedIdempotencyKeys[idempotencyKey] = struct{}{}
-	s.RefundedPayments = append(s.RefundedPayments, RefundPaymentRequest{
-		TicketID:       ticketID,
-		IdempotencyKey: idempotencyKey,
-	})
+	s.usedIdempotencyKeys[request.IdempotencyKey] = struct{}{}
+	s.RefundedPayments = append(s.RefundedPayments, request)
 
 	return nil
 }
@@ -51,6 +46,19 @@ func (s *PaymentsServiceStub) FindRefundedPayment(ticketID string) (RefundPaymen
 	return RefundPaymentRequest{}, false
 }
 
+func (s *PaymentsServiceStub) FindRefundedPayments(ticketID string) []RefundPaymentRequest {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var payments []RefundPaymentRequest
+	for _, p := range s.RefundedPayments {
+		if p.TicketID == ticketID {
+			payments = append(payments, p)
+		}
+	}
+	return payments
+}
+
 func (s *PaymentsServiceStub) RefundedPaymentsCount() int {
 	s.lock.Lock()
 	defer s.lock.Unlock()
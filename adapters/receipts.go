@@ -30,7 +30,7 @@ func (c ReceiptsServiceClient) IssueReceipt(ctx context.Context, request entitie
 		IdempotencyKey: &key,
 		TicketId:       request.TicketID,
 		Price: receipts.Money{
-			MoneyAmount:   request.Price.Amount,
+			MoneyAmount:   request.Price.Amount.String(),
 			MoneyCurrency: request.Price.Currency,
 		},
 	})
@@ -57,11 +57,11 @@ func (c ReceiptsServiceClient) IssueReceipt(ctx context.Context, request entitie
 	}
 }
 
-func (c ReceiptsServiceClient) VoidReceipt(ctx context.Context, ticketID, idempotencyKey string) error {
+func (c ReceiptsServiceClient) VoidReceipt(ctx context.Context, request entities.VoidReceipt) error {
 	resp, err := c.clients.Receipts.PutVoidReceiptWithResponse(ctx, receipts.VoidReceiptRequest{
-		TicketId:     ticketID,
-		Reason:       "customer requested refund",
-		IdempotentId: &idempotencyKey,
+		TicketId:     request.TicketID,
+		Reason:       entities.RefundDescription(request.Reason, request.Amount),
+		IdempotentId: &request.IdempotencyKey,
 	})
 
 	if err != nil {
@@ -15,10 +15,7 @@ type ReceiptsServiceStub struct {
 	ReceiptResponses    map[string]entities.IssueReceiptResponse
 }
 
-type VoidReceiptRequest struct {
-	TicketID       string
-	IdempotencyKey string
-}
+type VoidReceiptRequest = entities.VoidReceipt
 
 func NewReceiptsServiceStub() *ReceiptsServiceStub {
 	return &ReceiptsServiceStub{
@@ -52,19 +49,16 @@ func (s *ReceiptsServiceStub) IssueReceipt(ctx context.Context, request entities
 	return response, nil
 }
 
-func (s *ReceiptsServiceStub) VoidReceipt(ctx context.Context, ticketID, idempotencyKey string) error {
+func (s *ReceiptsServiceStub) VoidReceipt(ctx context.Context, request entities.VoidReceipt) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	if _, exists := s.usedIdempotencyKeys[idempotencyKey]; exists {
+	if _, exists := s.usedIdempotencyKeys[request.IdempotencyKey]; exists {
 		return nil
 	}
 
-	s.usedIdempotencyKeys[idempotencyKey] = struct{}{}
-	s.VoidedReceipts = append(s.VoidedReceipts, VoidReceiptRequest{
-		TicketID:       ticketID,
-		IdempotencyKey: idempotencyKey,
-	})
+	s.usedIdempotencyKeys[request.IdempotencyKey] = struct{}{}
+	s.VoidedReceipts = append(s.VoidedReceipts, request)
 
 	return nil
 }
@@ -0,0 +1,74 @@
+// Command contracts writes the JSON Schemas of events and commands, or checks them in CI:
+//
+//	go run ./cmd/contracts                                  # regenerate contracts/schemas
+//	go run ./cmd/contracts -check                           # fail on outdated schemas or breaking changes
+//	go run ./cmd/contracts -check -against /tmp/main-schemas  # compare with the schemas of another branch
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"tickets/contracts"
+)
+
+func main() {
+	dir := flag.String("dir", "contracts/schemas", "directory with the schema files")
+	check := flag.Bool("check", false, "check the schema files instead of writing them")
+	against := flag.String("against", "", "directory with the schema files to check breaking changes against, defaults to -dir")
+	flag.Parse()
+
+	if err := run(*dir, *check, *against); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, check bool, against string) error {
+	catalog, err := contracts.DefaultCatalog()
+	if err != nil {
+		return err
+	}
+
+	if !check {
+		return catalog.WriteDir(dir)
+	}
+
+	if against == "" {
+		against = dir
+	}
+
+	previous, err := contracts.ReadCatalog(against)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+
+	if changes := catalog.BreakingChanges(previous); len(changes) > 0 {
+		failed = true
+		fmt.Fprintf(os.Stderr, "breaking changes of contracts in %s, publish a new version of the message instead:\n", against)
+		for _, change := range changes {
+			fmt.Fprintln(os.Stderr, "  "+change)
+		}
+	}
+
+	outdated, err := catalog.OutdatedFiles(dir)
+	if err != nil {
+		return err
+	}
+	if len(outdated) > 0 {
+		failed = true
+		fmt.Fprintf(os.Stderr, "outdated schema files in %s, run go generate ./contracts:\n", dir)
+		for _, file := range outdated {
+			fmt.Fprintln(os.Stderr, "  "+file)
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("contracts check failed")
+	}
+
+	return nil
+}
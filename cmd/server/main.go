@@ -14,6 +14,7 @@ import (
 
 	"github.com/ThreeDotsLabs/go-event-driven/v2/common/clients"
 	"github.com/ThreeDotsLabs/go-event-driven/v2/common/log"
+	"github.com/ThreeDotsLabs/watermill"
 	"github.com/jmoiron/sqlx"
 	"github.com/uptrace/opentelemetry-go-extra/otelsql"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -28,12 +29,6 @@ func main() {
 	defer cancel()
 
 	logger := slog.Default()
-	rdb := message.NewRedisClient(os.Getenv("REDIS_ADDR"))
-	defer func() {
-		if err := rdb.Close(); err != nil {
-			logger.Error("failed to close redis client", "error", err)
-		}
-	}()
 
 	traceDB, err := otelsql.Open("postgres", os.Getenv("POSTGRES_URL"),
 		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
@@ -48,6 +43,16 @@ func main() {
 		}
 	}()
 
+	broker, err := message.NewBroker(message.BrokerKind(os.Getenv("MESSAGE_BROKER")), os.Getenv("REDIS_ADDR"), db, watermill.NewSlogLogger(logger))
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := broker.Close(); err != nil {
+			logger.Error("failed to close message broker", "error", err)
+		}
+	}()
+
 	traceHTTPClient := &http.Client{
 		Transport: otelhttp.NewTransport(
 			http.DefaultTransport,
@@ -75,7 +80,7 @@ func main() {
 	fileAPI := adapters.NewFilesAPIClient(apiClients)
 	deadnationAPI := adapters.NewDeadNationClient(apiClients)
 
-	svc, err := service.New(db, rdb, spreadsheetsAPI, receiptsService, paymentsService, fileAPI, deadnationAPI)
+	svc, err := service.New(db, broker, spreadsheetsAPI, receiptsService, paymentsService, fileAPI, deadnationAPI)
 	if err != nil {
 		panic(err)
 	}
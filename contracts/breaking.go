@@ -0,0 +1,70 @@
+package contracts
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// BreakingChanges lists changes of the schema which break consumers or producers still using the previous
+// schema: changed types and formats, and properties which are required now or stopped being required.
+// Adding an optional property isn't breaking. Breaking changes need a new version of the message.
+func BreakingChanges(previous, current *Schema) []string {
+	var changes []string
+	breakingChanges("$", previous, current, &changes)
+	return changes
+}
+
+func breakingChanges(path string, previous, current *Schema, changes *[]string) {
+	if !slices.Equal(previous.Type, current.Type) {
+		*changes = append(*changes, fmt.Sprintf("%s: type changed from %s to %s", path, describeTypes(previous.Type), describeTypes(current.Type)))
+		return
+	}
+	if previous.Format != current.Format {
+		*changes = append(*changes, fmt.Sprintf("%s: format changed from %q to %q", path, previous.Format, current.Format))
+	}
+	if previous.Pattern != current.Pattern {
+		*changes = append(*changes, fmt.Sprintf("%s: pattern changed from %q to %q", path, previous.Pattern, current.Pattern))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(previous.Properties)) {
+		wasRequired := slices.Contains(previous.Required, name)
+		isRequired := slices.Contains(current.Required, name)
+
+		property, ok := current.Properties[name]
+		switch {
+		case !ok && wasRequired:
+			*changes = append(*changes, fmt.Sprintf("%s.%s: required property was removed", path, name))
+		case !ok:
+			// optional properties can be removed, consumers get along without them
+		case wasRequired && !isRequired:
+			*changes = append(*changes, fmt.Sprintf("%s.%s: property is optional now", path, name))
+		case !wasRequired && isRequired:
+			*changes = append(*changes, fmt.Sprintf("%s.%s: property is required now", path, name))
+		}
+
+		if ok {
+			breakingChanges(path+"."+name, previous.Properties[name], property, changes)
+		}
+	}
+
+	for _, name := range current.Required {
+		if _, ok := previous.Properties[name]; !ok {
+			*changes = append(*changes, fmt.Sprintf("%s.%s: required property was added", path, name))
+		}
+	}
+
+	if previous.Items != nil && current.Items != nil {
+		breakingChanges(path+"[]", previous.Items, current.Items, changes)
+	}
+	if previous.AdditionalProperties != nil && current.AdditionalProperties != nil {
+		breakingChanges(path+".*", previous.AdditionalProperties, current.AdditionalProperties, changes)
+	}
+}
+
+func describeTypes(types Types) string {
+	if len(types) == 0 {
+		return "any"
+	}
+	return types.String()
+}
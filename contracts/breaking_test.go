@@ -0,0 +1,83 @@
+package contracts_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/contracts"
+	"tickets/entities"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	type event_v1 struct {
+		Header    entities.MessageHeader `json:"header"`
+		TicketID  string                 `json:"ticket_id"`
+		ShowID    uuid.UUID              `json:"show_id"`
+		Note      string                 `json:"note,omitempty"`
+		Seats     []entities.Seat        `json:"seats,omitempty"`
+		Count     int                    `json:"count"`
+		Reference string                 `json:"reference"`
+	}
+
+	testCases := []struct {
+		Name            string
+		Event           any
+		ExpectedChanges []string
+	}{
+		{
+			Name:  "unchanged",
+			Event: event_v1{},
+		},
+		{
+			Name: "optional_property_added_and_removed",
+			Event: struct {
+				Header    entities.MessageHeader `json:"header"`
+				TicketID  string                 `json:"ticket_id"`
+				ShowID    uuid.UUID              `json:"show_id"`
+				Seats     []entities.Seat        `json:"seats,omitempty"`
+				Count     int                    `json:"count"`
+				Reference string                 `json:"reference"`
+				Price     *entities.Money        `json:"price,omitempty"`
+			}{},
+		},
+		{
+			Name: "breaking",
+			Event: struct {
+				Header    entities.MessageHeader `json:"header"`
+				ShowID    string                 `json:"show_id"`
+				Note      string                 `json:"note"`
+				Seats     []struct{}             `json:"seats,omitempty"`
+				Count     *int                   `json:"count"`
+				Reference string                 `json:"reference,omitempty"`
+				CheckedIn time.Time              `json:"checked_in"`
+			}{},
+			ExpectedChanges: []string{
+				`$.count: type changed from integer to integer|null`,
+				`$.note: property is required now`,
+				`$.reference: property is optional now`,
+				`$.seats[].number: required property was removed`,
+				`$.seats[].row: required property was removed`,
+				`$.seats[].section: required property was removed`,
+				`$.show_id: format changed from "uuid" to ""`,
+				`$.ticket_id: required property was removed`,
+				`$.checked_in: required property was added`,
+			},
+		},
+	}
+
+	previous, err := contracts.SchemaOf(event_v1{})
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			current, err := contracts.SchemaOf(tc.Event)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.ExpectedChanges, contracts.BreakingChanges(previous, current))
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Package contracts describes events and commands with JSON Schemas generated from their structs,
+// so messages are validated when they are published and consumed.
+package contracts
+
+//go:generate go run ../cmd/contracts -dir schemas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"tickets/entities"
+)
+
+type Kind string
+
+const (
+	KindEvent   Kind = "event"
+	KindCommand Kind = "command"
+)
+
+const catalogFileName = "catalog.json"
+
+type Contract struct {
+	Name   string  `json:"name"`
+	Kind   Kind    `json:"kind"`
+	Topic  string  `json:"topic"`
+	File   string  `json:"schema"`
+	Schema *Schema `json:"-"`
+}
+
+// Catalog holds the contracts of messages by their name, the name is in the "name" metadata of messages.
+type Catalog struct {
+	contracts map[string]Contract
+}
+
+// Default is the catalog of all events and commands of the service.
+var Default = mustDefaultCatalog()
+
+func mustDefaultCatalog() Catalog {
+	catalog, err := DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+}
+
+func DefaultCatalog() (Catalog, error) {
+	messages := []struct {
+		kind    Kind
+		message any
+	}{
+		{KindEvent, entities.TicketBookingConfirmed_v1{}},
+		{KindEvent, entities.TicketBookingCanceled_v1{}},
+		{KindEvent, entities.TicketPrinted_v1{}},
+		{KindEvent, entities.BookingMade_v1{}},
+		{KindEvent, entities.TicketReceiptIssued_v1{}},
+		{KindEvent, entities.TicketRefunded_v1{}},
+		{KindEvent, entities.TicketCheckedIn_v1{}},
+		{KindEvent, entities.TicketTransferred_v1{}},
+		{KindEvent, entities.RefundPolicyOverridden_v1{}},
+		{KindEvent, entities.ShowCanceled_v1{}},
+		{KindEvent, entities.SeatsHeld_v1{}},
+		{KindEvent, entities.SeatHoldExpired_v1{}},
+		{KindEvent, entities.BookingCanceled_v1{}},
+		{KindEvent, entities.SeatsReleased_v1{}},
+		{KindEvent, entities.WaitlistOfferMade_v1{}},
+		{KindEvent, entities.TicketPriceMismatchDetected_v1{}},
+
+		{KindCommand, entities.RefundTicket{}},
+		{KindCommand, entities.TransferTicket{}},
+	}
+
+	catalog := Catalog{contracts: map[string]Contract{}}
+	for _, m := range messages {
+		name := cqrs.StructName(m.message)
+
+		schema, err := SchemaOf(m.message)
+		if err != nil {
+			return Catalog{}, err
+		}
+
+		topic := "events"
+		if m.kind == KindCommand {
+			topic = "commands." + name
+		}
+
+		catalog.contracts[name] = Contract{
+			Name:   name,
+			Kind:   m.kind,
+			Topic:  topic,
+			File:   name + ".json",
+			Schema: schema,
+		}
+	}
+
+	return catalog, nil
+}
+
+// Contracts returns the contracts sorted by name.
+func (c Catalog) Contracts() []Contract {
+	contracts := make([]Contract, 0, len(c.contracts))
+	for _, name := range slices.Sorted(maps.Keys(c.contracts)) {
+		contracts = append(contracts, c.contracts[name])
+	}
+	return contracts
+}
+
+func (c Catalog) Contract(name string) (Contract, bool) {
+	contract, ok := c.contracts[name]
+	return contract, ok
+}
+
+// Validate checks the payload against the contract of the message name. Messages without a contract,
+// like events published in versions older than the catalog, are not validated.
+func (c Catalog) Validate(name string, payload []byte) error {
+	contract, ok := c.contracts[name]
+	if !ok {
+		return nil
+	}
+
+	if err := contract.Schema.Validate(payload); err != nil {
+		return fmt.Errorf("%s %s: %w", contract.Kind, name, err)
+	}
+
+	return nil
+}
+
+// ValidateMessage validates the payload of a message published by cqrs.EventBus or cqrs.CommandBus.
+func (c Catalog) ValidateMessage(msg *message.Message) error {
+	return c.Validate(cqrs.JSONMarshaler{}.NameFromMessage(msg), msg.Payload)
+}
+
+// Files returns the content of the schema files and the catalog file, by file name.
+func (c Catalog) Files() (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	contracts := c.Contracts()
+	for _, contract := range contracts {
+		content, err := marshalFile(contract.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal schema of %s: %w", contract.Name, err)
+		}
+		files[contract.File] = content
+	}
+
+	content, err := marshalFile(contracts)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal catalog: %w", err)
+	}
+	files[catalogFileName] = content
+
+	return files, nil
+}
+
+func marshalFile(v any) ([]byte, error) {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// WriteDir replaces the schema files in dir with the files of the catalog.
+func (c Catalog) WriteDir(dir string) error {
+	files, err := c.Files()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range existing {
+		if _, ok := files[filepath.Base(path)]; !ok {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OutdatedFiles lists the files in dir which differ from the files of the catalog,
+// or which are missing or left over.
+func (c Catalog) OutdatedFiles(dir string) ([]string, error) {
+	files, err := c.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []string
+	for _, path := range existing {
+		if _, ok := files[filepath.Base(path)]; !ok {
+			outdated = append(outdated, filepath.Base(path))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if !bytes.Equal(content, files[name]) {
+			outdated = append(outdated, name)
+		}
+	}
+
+	slices.Sort(outdated)
+	return outdated, nil
+}
+
+// ReadCatalog reads a catalog written by WriteDir.
+func ReadCatalog(dir string) (Catalog, error) {
+	content, err := os.ReadFile(filepath.Join(dir, catalogFileName))
+	if err != nil {
+		return Catalog{}, fmt.Errorf("could not read catalog: %w", err)
+	}
+
+	var contracts []Contract
+	if err := json.Unmarshal(content, &contracts); err != nil {
+		return Catalog{}, fmt.Errorf("could not unmarshal catalog: %w", err)
+	}
+
+	catalog := Catalog{contracts: map[string]Contract{}}
+	for _, contract := range contracts {
+		content, err := os.ReadFile(filepath.Join(dir, contract.File))
+		if err != nil {
+			return Catalog{}, fmt.Errorf("could not read schema of %s: %w", contract.Name, err)
+		}
+
+		if err := json.Unmarshal(content, &contract.Schema); err != nil {
+			return Catalog{}, fmt.Errorf("could not unmarshal schema of %s: %w", contract.Name, err)
+		}
+
+		catalog.contracts[contract.Name] = contract
+	}
+
+	return catalog, nil
+}
+
+// BreakingChanges lists the changes of the catalog breaking the contracts of the previous catalog.
+// Removing a contract is breaking, the messages may still wait in their topics.
+func (c Catalog) BreakingChanges(previous Catalog) []string {
+	var changes []string
+
+	for _, prev := range previous.Contracts() {
+		contract, ok := c.contracts[prev.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: contract was removed", prev.Name))
+			continue
+		}
+
+		if contract.Kind != prev.Kind || contract.Topic != prev.Topic {
+			changes = append(changes, fmt.Sprintf("%s: moved from %s %s to %s %s", prev.Name, prev.Kind, prev.Topic, contract.Kind, contract.Topic))
+		}
+
+		for _, change := range BreakingChanges(prev.Schema, contract.Schema) {
+			changes = append(changes, prev.Name+": "+strings.TrimPrefix(change, "$."))
+		}
+	}
+
+	return changes
+}
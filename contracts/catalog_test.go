@@ -0,0 +1,70 @@
+package contracts_test
+
+import (
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/contracts"
+	"tickets/entities"
+	"tickets/message/command"
+	"tickets/message/event"
+)
+
+func TestDefaultCatalog_schema_files_are_up_to_date(t *testing.T) {
+	outdated, err := contracts.Default.OutdatedFiles("schemas")
+	require.NoError(t, err)
+	assert.Empty(t, outdated, "run go generate ./contracts")
+
+	committed, err := contracts.ReadCatalog("schemas")
+	require.NoError(t, err)
+	assert.Empty(t, contracts.Default.BreakingChanges(committed))
+	assert.Len(t, committed.Contracts(), len(contracts.Default.Contracts()))
+}
+
+func TestCatalog_BreakingChanges(t *testing.T) {
+	committed, err := contracts.ReadCatalog("schemas")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, contracts.Default.WriteDir(dir))
+
+	previous, err := contracts.ReadCatalog(dir)
+	require.NoError(t, err)
+
+	assert.Empty(t, committed.BreakingChanges(previous))
+	assert.Empty(t, contracts.Catalog{}.BreakingChanges(contracts.Catalog{}))
+	assert.Contains(t, contracts.Catalog{}.BreakingChanges(previous), "TicketPrinted_v1: contract was removed")
+}
+
+func TestValidatingPublisherDecorator(t *testing.T) {
+	pubSub := gochannel.NewGoChannel(gochannel.Config{Persistent: true}, nil)
+
+	eventBus := event.NewEventBus(pubSub)
+	commandBus := command.NewCommandBus(pubSub)
+
+	ctx := t.Context()
+
+	err := eventBus.Publish(ctx, entities.ShowCanceled_v1{Header: entities.NewMessageHeader(), ShowID: uuid.New()})
+	require.NoError(t, err)
+
+	err = commandBus.Send(ctx, entities.TransferTicket{Header: entities.NewMessageHeader(), TicketID: "ticket-1"})
+	require.NoError(t, err)
+
+	msg := message.NewMessage(uuid.NewString(), []byte(`{"header": {}, "ticket_id": 1}`))
+	msg.Metadata.Set("name", "TransferTicket")
+
+	publisher := contracts.ValidatingPublisherDecorator{Publisher: pubSub, Catalog: contracts.Default}
+	err = publisher.Publish("commands.TransferTicket", msg)
+	var validationErr *contracts.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, validationErr.Problems, "$.ticket_id: expected string, got integer")
+
+	legacy := message.NewMessage(uuid.NewString(), []byte(`{"ticket_id": "ticket-1"}`))
+	legacy.Metadata.Set("name", "TicketRefunded_v0")
+	assert.NoError(t, publisher.Publish("events", legacy), "messages without a contract are not validated")
+}
@@ -0,0 +1,22 @@
+package contracts
+
+import (
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// ValidatingPublisherDecorator doesn't publish messages which don't match their contract,
+// a bad payload fails where it's published instead of in every handler.
+type ValidatingPublisherDecorator struct {
+	message.Publisher
+	Catalog Catalog
+}
+
+func (p ValidatingPublisherDecorator) Publish(topic string, messages ...*message.Message) error {
+	for _, msg := range messages {
+		if err := p.Catalog.ValidateMessage(msg); err != nil {
+			return err
+		}
+	}
+
+	return p.Publisher.Publish(topic, messages...)
+}
@@ -0,0 +1,209 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"tickets/entities"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema needed to describe the JSON encoding of events and commands.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Types are the JSON types a value may have, a schema without types accepts any value.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*t = multiple
+	return nil
+}
+
+func (t Types) String() string {
+	return strings.Join(t, "|")
+}
+
+// decimalPattern matches entities.Decimal, which is encoded as a string.
+const decimalPattern = `^-?[0-9]+(\.[0-9]+)?$`
+
+// knownTypes have custom JSON encodings, their schema can't be derived from their fields.
+var knownTypes = map[reflect.Type]Schema{
+	reflect.TypeFor[time.Time]():        {Type: Types{"string"}, Format: "date-time"},
+	reflect.TypeFor[uuid.UUID]():        {Type: Types{"string"}, Format: "uuid"},
+	reflect.TypeFor[entities.Decimal](): {Type: Types{"string"}, Pattern: decimalPattern},
+}
+
+// SchemaOf generates the schema of the JSON encoding of v, following the rules of encoding/json:
+// fields with omitempty are optional, pointers and slices may be null.
+func SchemaOf(v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, fmt.Errorf("cannot generate schema of nil")
+	}
+
+	schema, err := schemaOfType(t)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate schema of %s: %w", t, err)
+	}
+
+	schema.Dialect = schemaDialect
+	schema.Title = t.Name()
+
+	return schema, nil
+}
+
+func schemaOfType(t reflect.Type) (*Schema, error) {
+	if known, ok := knownTypes[t]; ok {
+		known.Type = slices.Clone(known.Type)
+		return &known, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}, nil
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Pointer:
+		return nullable(t.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string
+			return &Schema{Type: Types{"string", "null"}}, nil
+		}
+
+		items, err := schemaOfType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: Types{"array", "null"}, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys of %s are not strings", t)
+		}
+
+		values, err := schemaOfType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return schemaOfStruct(t)
+	default:
+		return nil, fmt.Errorf("%s can't be encoded as JSON", t)
+	}
+}
+
+func nullable(t reflect.Type) (*Schema, error) {
+	schema, err := schemaOfType(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(schema.Type) > 0 && !slices.Contains(schema.Type, "null") {
+		schema.Type = append(schema.Type, "null")
+	}
+
+	return schema, nil
+}
+
+func schemaOfStruct(t reflect.Type) (*Schema, error) {
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[json.Marshaler]()) || t.Implements(reflect.TypeFor[json.Marshaler]()) {
+		return nil, fmt.Errorf("%s has a custom JSON encoding, add it to knownTypes", t)
+	}
+
+	schema := &Schema{
+		Type:       Types{"object"},
+		Properties: map[string]*Schema{},
+	}
+
+	if err := addStructFields(schema, t); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+func addStructFields(schema *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			// fields of embedded structs are encoded as fields of the struct
+			if err := addStructFields(schema, field.Type); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := schemaOfType(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		schema.Properties[name] = property
+		if opts := strings.Split(options, ","); !slices.Contains(opts, "omitempty") && !slices.Contains(opts, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return nil
+}
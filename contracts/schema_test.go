@@ -0,0 +1,127 @@
+package contracts_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/contracts"
+	"tickets/entities"
+)
+
+func TestSchemaOf_matches_JSON_encoding(t *testing.T) {
+	amount := entities.MustNewMoney("10.50", "EUR")
+	holdID := uuid.New()
+
+	events := []any{
+		entities.BookingMade_v1{
+			Header:          entities.NewMessageHeader(),
+			NumberOfTickets: 2,
+			BookingID:       uuid.New(),
+			CustomerEmail:   "customer@example.com",
+			ShowID:          uuid.New(),
+			Seats:           []entities.Seat{{Section: "A", Row: "1", Number: 2}},
+			TicketPrice:     &amount,
+			HoldID:          &holdID,
+		},
+		entities.BookingMade_v1{},
+		entities.TicketRefunded_v1{Header: entities.NewMessageHeader(), TicketID: "ticket-1", Amount: &amount},
+		entities.TicketReceiptIssued_v1{Header: entities.NewMessageHeader(), IssuedAt: time.Now()},
+		entities.RefundTicket{Header: entities.NewMessageHeader(), TicketID: "ticket-1"},
+	}
+
+	for _, event := range events {
+		schema, err := contracts.SchemaOf(event)
+		require.NoError(t, err)
+
+		payload, err := json.Marshal(event)
+		require.NoError(t, err)
+
+		assert.NoError(t, schema.Validate(payload), string(payload))
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := contracts.SchemaOf(entities.BookingMade_v1{})
+	require.NoError(t, err)
+
+	validPayload := `{
+		"header": {"id": "1", "published_at": "2024-06-10T20:00:00.123Z", "idempotency_key": "key"},
+		"number_of_tickets": 2,
+		"booking_id": "` + uuid.NewString() + `",
+		"customer_email": "customer@example.com",
+		"show_id": "` + uuid.NewString() + `"
+	}`
+
+	testCases := []struct {
+		Name             string
+		Payload          string
+		ExpectedProblems []string
+	}{
+		{
+			Name:    "valid",
+			Payload: validPayload,
+		},
+		{
+			Name: "optional_fields",
+			Payload: `{
+				"header": {"id": "1", "published_at": "2024-06-10T20:00:00Z", "idempotency_key": "key"},
+				"number_of_tickets": 2,
+				"booking_id": "` + uuid.NewString() + `",
+				"customer_email": "customer@example.com",
+				"show_id": "` + uuid.NewString() + `",
+				"seats": [{"section": "A", "row": "1", "number": 2}],
+				"ticket_price": {"amount": "10.50", "currency": "EUR"},
+				"hold_id": null,
+				"unknown_field": true
+			}`,
+		},
+		{
+			Name:             "not_json",
+			Payload:          "not json",
+			ExpectedProblems: []string{"invalid JSON: invalid character 'o' in literal null (expecting 'u')"},
+		},
+		{
+			Name:             "not_object",
+			Payload:          `[]`,
+			ExpectedProblems: []string{"$: expected object, got array"},
+		},
+		{
+			Name: "invalid_fields",
+			Payload: `{
+				"header": {"id": "1", "published_at": "yesterday", "idempotency_key": "key"},
+				"number_of_tickets": 2.5,
+				"booking_id": "not-uuid",
+				"customer_email": "customer@example.com",
+				"seats": [{"section": "A", "row": 1, "number": 2}],
+				"ticket_price": {"amount": "ten", "currency": "EUR"}
+			}`,
+			ExpectedProblems: []string{
+				"$.show_id: is required",
+				`$.header.published_at: "yesterday" is not a date-time`,
+				"$.number_of_tickets: expected integer, got number",
+				`$.booking_id: "not-uuid" is not a uuid`,
+				"$.seats[0].row: expected string, got integer",
+				`$.ticket_price.amount: "ten" doesn't match ^-?[0-9]+(\.[0-9]+)?$`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := schema.Validate([]byte(tc.Payload))
+			if len(tc.ExpectedProblems) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr *contracts.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.ElementsMatch(t, tc.ExpectedProblems, validationErr.Problems)
+		})
+	}
+}
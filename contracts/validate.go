@@ -0,0 +1,124 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidationError lists everything in the payload that doesn't match the schema.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "payload doesn't match the contract: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the JSON payload against the schema, it returns *ValidationError when it doesn't match.
+func (s *Schema) Validate(payload []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return &ValidationError{Problems: []string{fmt.Sprintf("invalid JSON: %s", err)}}
+	}
+	if decoder.More() {
+		return &ValidationError{Problems: []string{"invalid JSON: unexpected data after the top-level value"}}
+	}
+
+	var problems []string
+	s.validate("$", value, &problems)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, value any, problems *[]string) {
+	typ := jsonType(value)
+	if len(s.Type) > 0 && !slices.Contains(s.Type, typ) && !(typ == "integer" && slices.Contains(s.Type, "number")) {
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", path, s.Type, typ))
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(path, v, problems)
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s.%s: is required", path, name))
+			}
+		}
+
+		for name, property := range v {
+			if schema, ok := s.Properties[name]; ok {
+				schema.validate(path+"."+name, property, problems)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(path+"."+name, property, problems)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateString(path, value string, problems *[]string) {
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not a date-time", path, value))
+		}
+	case "uuid":
+		if _, err := uuid.Parse(value); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not a uuid", path, value))
+		}
+	}
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: invalid pattern %q in schema", path, s.Pattern))
+		} else if !pattern.MatchString(value) {
+			*problems = append(*problems, fmt.Sprintf("%s: %q doesn't match %s", path, value, s.Pattern))
+		}
+	}
+}
+
+// jsonType returns the JSON type of a value decoded with UseNumber. Numbers are integers when
+// encoding/json can decode them to an int.
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -5,16 +5,37 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"tickets/entities"
 	"tickets/message/event"
 	"tickets/message/outbox"
 )
 
-var ErrNotEnoughSeats = errors.New("not enough seats available")
+var (
+	ErrNotEnoughSeats   = errors.New("not enough seats available")
+	ErrShowCanceled     = errors.New("show is canceled")
+	ErrSeatNotAvailable = errors.New("seat is not available")
+	ErrShowHasNoSeatMap = errors.New("show has no seat map")
+
+	ErrPurchaseLimitExceeded = errors.New("purchase limit exceeded")
+
+	ErrPriceCategoryRequired = errors.New("price category is required")
+	ErrPriceCategoryNotFound = errors.New("price category not found")
+
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different booking")
+
+	ErrBookingNotFound        = errors.New("booking not found")
+	ErrBookingAlreadyCanceled = errors.New("booking is already canceled")
+)
+
+// maxTicketsPerBooking is the limit for all shows, shows can limit tickets per customer further.
+const maxTicketsPerBooking = 20
 
 type BookingRepository struct {
 	db *sqlx.DB
@@ -28,83 +49,649 @@ func NewBookingRepository(db *sqlx.DB) BookingRepository {
 	return BookingRepository{db: db}
 }
 
-func (b BookingRepository) AddBooking(ctx context.Context, booking entities.Booking) error {
+// AddBooking makes the booking. When the booking has an idempotency key which was already used,
+// the booking made with that key is returned instead.
+func (b BookingRepository) AddBooking(ctx context.Context, booking entities.Booking) (entities.Booking, error) {
+	var allocatedSeats []entities.Seat
+	var existingBooking *entities.Booking
+
 	updateFn := func(ctx context.Context, tx *sqlx.Tx) error {
-		availableSeats, err := getAvailableSeats(ctx, tx, booking.ShowID)
-		if err != nil {
+		var err error
+		existingBooking, err = findBookingByIdempotencyKey(ctx, tx, booking)
+		if err != nil || existingBooking != nil {
+			return err
+		}
+
+		if err := reserveSeats(ctx, tx, booking.ShowID, booking.NumberOfTickets, 0); err != nil {
 			return err
 		}
 
-		alreadyBookedSeats, err := getAlreadyBookedSeats(ctx, tx, booking.ShowID)
+		if err := ensurePurchaseLimit(ctx, tx, booking.ShowID, booking.CustomerEmail, booking.NumberOfTickets); err != nil {
+			return err
+		}
+
+		booking.TicketPrice, err = getTicketPrice(ctx, tx, booking.ShowID, booking.PriceCategory)
 		if err != nil {
 			return err
 		}
 
-		if availableSeats-alreadyBookedSeats < booking.NumberOfTickets {
-			return ErrNotEnoughSeats
+		if booking.PromoCode != "" {
+			if err := applyPromoCode(ctx, tx, &booking); err != nil {
+				return err
+			}
 		}
 
 		if err := insertBooking(ctx, tx, booking); err != nil {
 			return err
 		}
 
-		if err := publishBookingMadeEvent(ctx, tx, booking); err != nil {
+		allocatedSeats, err = allocateSeats(ctx, tx, booking.ShowID, booking.Seats, booking.NumberOfTickets, bookingSeats(booking.BookingID))
+		if err != nil {
+			return err
+		}
+
+		madeBooking := booking
+		madeBooking.Seats = allocatedSeats
+
+		if err := publishBookingMadeEvent(ctx, tx, madeBooking, nil); err != nil {
 			return err
 		}
 
 		return nil
 	}
 
-	return updateInTx(ctx, b.db, sql.LevelSerializable, updateFn)
+	if err := updateInTx(ctx, b.db, sql.LevelSerializable, updateFn); err != nil {
+		if booking.IdempotencyKey != "" && isUniqueViolation(err, "bookings_idempotency_key_idx") {
+			// concurrent request with the same key made the booking first
+			return b.AddBooking(ctx, booking)
+		}
+		return entities.Booking{}, err
+	}
+
+	if existingBooking != nil {
+		return *existingBooking, nil
+	}
+
+	booking.Seats = allocatedSeats
+
+	return booking, nil
+}
+
+func findBookingByIdempotencyKey(ctx context.Context, tx *sqlx.Tx, booking entities.Booking) (*entities.Booking, error) {
+	if booking.IdempotencyKey == "" {
+		return nil, nil
+	}
+
+	var bookingID uuid.UUID
+	err := tx.GetContext(ctx, &bookingID, `SELECT booking_id FROM bookings WHERE idempotency_key = $1`, booking.IdempotencyKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not find booking by idempotency key: %w", err)
+	}
+
+	existingBooking, err := findBooking(ctx, tx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingBooking.ShowID != booking.ShowID ||
+		existingBooking.NumberOfTickets != booking.NumberOfTickets ||
+		existingBooking.CustomerEmail != booking.CustomerEmail ||
+		existingBooking.PromoCode != booking.PromoCode ||
+		existingBooking.PriceCategory != booking.PriceCategory ||
+		!sameSeats(booking.Seats, existingBooking.Seats) {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	return &existingBooking, nil
+}
+
+// sameSeats checks that the booked seats are the requested ones. Bookings without requested seats
+// get the best available seats, so any booked seats match them.
+func sameSeats(requested, booked []entities.Seat) bool {
+	if len(requested) == 0 {
+		return true
+	}
+	if len(requested) != len(booked) {
+		return false
+	}
+
+	bookedSeats := map[entities.Seat]bool{}
+	for _, seat := range booked {
+		bookedSeats[seat] = true
+	}
+	for _, seat := range requested {
+		if !bookedSeats[seat] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// AssignSeatToTicket picks one of the booking's seats for the ticket. It's idempotent: the ticket always gets the same seat.
+// It returns nil when the booking has no seats left to assign (for example, the show has no seat map).
+func (b BookingRepository) AssignSeatToTicket(ctx context.Context, bookingID uuid.UUID, ticketID string) (*entities.Seat, error) {
+	var seat *entities.Seat
+
+	err := updateInTx(
+		ctx,
+		b.db,
+		sql.LevelSerializable,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			seat = nil
+
+			var assigned entities.Seat
+			err := tx.GetContext(ctx, &assigned, `
+				SELECT
+					seat_section, seat_row, seat_number
+				FROM
+					show_seats
+				WHERE
+					booking_id = $1 AND ticket_id = $2
+			`, bookingID, ticketID)
+			if err == nil {
+				seat = &assigned
+				return nil
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("could not get seat of ticket %s: %w", ticketID, err)
+			}
+
+			err = tx.GetContext(ctx, &assigned, `
+				UPDATE
+					show_seats
+				SET
+					ticket_id = $2
+				WHERE
+					(show_id, seat_section, seat_row, seat_number) = (
+						SELECT
+							show_id, seat_section, seat_row, seat_number
+						FROM
+							show_seats
+						WHERE
+							booking_id = $1 AND ticket_id IS NULL
+						ORDER BY
+							position
+						LIMIT 1
+					)
+				RETURNING
+					seat_section, seat_row, seat_number
+			`, bookingID, ticketID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("could not assign seat to ticket %s: %w", ticketID, err)
+			}
+
+			seat = &assigned
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return seat, nil
+}
+
+func (b BookingRepository) BookingByID(ctx context.Context, bookingID uuid.UUID) (entities.Booking, error) {
+	var booking entities.Booking
+
+	err := updateInTx(
+		ctx,
+		b.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var err error
+			booking, err = findBooking(ctx, tx, bookingID)
+			return err
+		},
+	)
+	if err != nil {
+		return entities.Booking{}, err
+	}
+
+	return booking, nil
 }
 
-func getAvailableSeats(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID) (int, error) {
-	var availableSeats int
-	err := tx.GetContext(ctx, &availableSeats, `
+// CancelBooking releases all seats of the booking. Tickets of the booking are refunded by BookingCanceled_v1 handlers.
+func (b BookingRepository) CancelBooking(ctx context.Context, bookingID uuid.UUID) error {
+	return updateInTx(
+		ctx,
+		b.db,
+		sql.LevelRepeatableRead,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var booking struct {
+				entities.Booking
+				ReleasedTickets int  `db:"released_tickets"`
+				IsCanceled      bool `db:"is_canceled"`
+			}
+			err := tx.GetContext(ctx, &booking, `
+				SELECT
+					booking_id,
+					show_id,
+					number_of_tickets,
+					customer_email,
+					released_tickets,
+					canceled_at IS NOT NULL AS is_canceled
+				FROM
+					bookings
+				WHERE
+					booking_id = $1
+				FOR UPDATE
+			`, bookingID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrBookingNotFound
+			} else if err != nil {
+				return fmt.Errorf("could not get booking %s: %w", bookingID, err)
+			}
+
+			if booking.IsCanceled {
+				return ErrBookingAlreadyCanceled
+			}
+
+			// seats of already refunded tickets were released before
+			releasedTickets := booking.NumberOfTickets - booking.ReleasedTickets
+
+			if err := updateSeatCounter(ctx, tx, booking.ShowID, -releasedTickets, 0); err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE bookings SET canceled_at = now() WHERE booking_id = $1`, bookingID)
+			if err != nil {
+				return fmt.Errorf("could not cancel booking %s: %w", bookingID, err)
+			}
+
+			var releasedSeats []entities.Seat
+			err = tx.SelectContext(ctx, &releasedSeats, `
+				UPDATE
+					show_seats
+				SET
+					booking_id = NULL, ticket_id = NULL
+				WHERE
+					booking_id = $1
+				RETURNING
+					seat_section, seat_row, seat_number
+			`, bookingID)
+			if err != nil {
+				return fmt.Errorf("could not release seats of booking %s: %w", bookingID, err)
+			}
+
+			publisher, err := outbox.NewPublisherForDB(ctx, tx)
+			if err != nil {
+				return fmt.Errorf("could not create event bus: %w", err)
+			}
+
+			bus := event.NewEventBus(publisher)
+
+			return bus.Publish(ctx, &entities.BookingCanceled_v1{
+				Header:          entities.NewMessageHeader(),
+				BookingID:       booking.BookingID,
+				ShowID:          booking.ShowID,
+				NumberOfTickets: booking.NumberOfTickets,
+				CustomerEmail:   booking.CustomerEmail,
+				ReleasedTickets: releasedTickets,
+				Seats:           releasedSeats,
+			})
+		},
+	)
+}
+
+// ReleaseTicketSeat frees the seat of a refunded ticket, so it can be booked again.
+// Releasing the same ticket twice and releasing tickets booked outside our system is a no-op.
+func (b BookingRepository) ReleaseTicketSeat(ctx context.Context, ticketID string) error {
+	return updateInTx(
+		ctx,
+		b.db,
+		sql.LevelSerializable,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var ticket struct {
+				BookingID  *uuid.UUID `db:"booking_id"`
+				IsRefunded bool       `db:"is_refunded"`
+			}
+			err := tx.GetContext(ctx, &ticket, `
+				SELECT
+					booking_id, refunded_at IS NOT NULL AS is_refunded
+				FROM
+					tickets
+				WHERE
+					ticket_id = $1
+				FOR UPDATE
+			`, ticketID)
+			if errors.Is(err, sql.ErrNoRows) {
+				slog.Warn("ticket to release not found", "ticket_id", ticketID)
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("could not get ticket %s: %w", ticketID, err)
+			}
+
+			if ticket.IsRefunded || ticket.BookingID == nil {
+				return nil
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE tickets SET refunded_at = now() WHERE ticket_id = $1`, ticketID)
+			if err != nil {
+				return fmt.Errorf("could not mark ticket %s as refunded: %w", ticketID, err)
+			}
+
+			var showID uuid.UUID
+			err = tx.GetContext(ctx, &showID, `
+				SELECT
+					show_id
+				FROM
+					bookings
+				WHERE
+					booking_id = $1 AND released_tickets < number_of_tickets AND canceled_at IS NULL
+				FOR UPDATE
+			`, *ticket.BookingID)
+			if errors.Is(err, sql.ErrNoRows) {
+				// seats of canceled bookings are already released
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("could not get booking %s: %w", *ticket.BookingID, err)
+			}
+
+			if err := updateSeatCounter(ctx, tx, showID, -1, 0); err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE bookings SET released_tickets = released_tickets + 1 WHERE booking_id = $1`, *ticket.BookingID)
+			if err != nil {
+				return fmt.Errorf("could not release ticket of booking %s: %w", *ticket.BookingID, err)
+			}
+
+			// the ticket may be refunded before it got its seat, then any not assigned seat of the booking is released
+			var releasedSeats []entities.Seat
+			err = tx.SelectContext(ctx, &releasedSeats, `
+				UPDATE
+					show_seats
+				SET
+					booking_id = NULL, ticket_id = NULL
+				WHERE
+					(show_id, seat_section, seat_row, seat_number) = (
+						SELECT
+							show_id, seat_section, seat_row, seat_number
+						FROM
+							show_seats
+						WHERE
+							booking_id = $1 AND (ticket_id = $2 OR ticket_id IS NULL)
+						ORDER BY
+							ticket_id IS NULL, position DESC
+						LIMIT 1
+					)
+				RETURNING
+					seat_section, seat_row, seat_number
+			`, *ticket.BookingID, ticketID)
+			if err != nil {
+				return fmt.Errorf("could not release seat of ticket %s: %w", ticketID, err)
+			}
+
+			return publishSeatsReleasedEvent(ctx, tx, entities.SeatsReleased_v1{
+				Header:          entities.NewMessageHeader(),
+				ShowID:          showID,
+				BookingID:       *ticket.BookingID,
+				NumberOfTickets: 1,
+				Seats:           releasedSeats,
+			})
+		},
+	)
+}
+
+func (b BookingRepository) FindAllByShowID(ctx context.Context, showID uuid.UUID) ([]entities.Booking, error) {
+	var bookings []entities.Booking
+	err := b.db.SelectContext(ctx, &bookings, `
 		SELECT
-			number_of_tickets AS available_seats
+			booking_id, show_id, number_of_tickets, customer_email
+		FROM
+			bookings
+		WHERE
+			show_id = $1 AND canceled_at IS NULL
+	`, showID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find bookings for show %s: %w", showID, err)
+	}
+
+	return bookings, nil
+}
+
+// ensurePurchaseLimit checks the per-booking limit and the show's limit of tickets per customer.
+// Tickets of active holds and waitlist entries not offered yet count to the customer's limit,
+// as they can be confirmed at any moment.
+func ensurePurchaseLimit(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID, customerEmail string, numberOfTickets int) error {
+	if numberOfTickets > maxTicketsPerBooking {
+		return fmt.Errorf("%w: at most %d tickets per booking", ErrPurchaseLimitExceeded, maxTicketsPerBooking)
+	}
+
+	var maxTicketsPerCustomer *int
+	err := tx.GetContext(ctx, &maxTicketsPerCustomer, `SELECT max_tickets_per_customer FROM shows WHERE show_id = $1`, showID)
+	if err != nil {
+		return fmt.Errorf("could not get purchase limit: %w", err)
+	}
+
+	if maxTicketsPerCustomer == nil {
+		return nil
+	}
+
+	var customerTickets int
+	err = tx.GetContext(ctx, &customerTickets, `
+		SELECT
+			(
+				SELECT
+					COALESCE(SUM(number_of_tickets - released_tickets), 0)
+				FROM
+					bookings
+				WHERE
+					show_id = $1 AND lower(customer_email) = lower($2) AND canceled_at IS NULL
+			) + (
+				SELECT
+					COALESCE(SUM(number_of_tickets), 0)
+				FROM
+					seat_holds
+				WHERE
+					show_id = $1 AND lower(customer_email) = lower($2) AND booking_id IS NULL AND released_at IS NULL
+			) + (
+				SELECT
+					COALESCE(SUM(number_of_tickets), 0)
+				FROM
+					waitlist_entries
+				WHERE
+					show_id = $1 AND lower(customer_email) = lower($2) AND offered_at IS NULL
+			) AS customer_tickets
+	`, showID, customerEmail)
+	if err != nil {
+		return fmt.Errorf("could not get tickets of customer: %w", err)
+	}
+
+	if customerTickets+numberOfTickets > *maxTicketsPerCustomer {
+		return fmt.Errorf("%w: at most %d tickets per customer", ErrPurchaseLimitExceeded, *maxTicketsPerCustomer)
+	}
+
+	return nil
+}
+
+func ensureShowNotCanceled(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID) error {
+	var canceled bool
+	err := tx.GetContext(ctx, &canceled, `
+		SELECT
+			canceled_at IS NOT NULL
 		FROM
 			shows
 		WHERE
 			show_id = $1
 	`, showID)
 	if err != nil {
-		return 0, fmt.Errorf("could not get available seats: %w", err)
+		return fmt.Errorf("could not get show: %w", err)
+	}
+
+	if canceled {
+		return ErrShowCanceled
 	}
-	return availableSeats, nil
+
+	return nil
 }
 
-func getAlreadyBookedSeats(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID) (int, error) {
-	var alreadyBookedSeats int
-	err := tx.GetContext(ctx, &alreadyBookedSeats, `
+// getTicketPrice returns the price of the show's category. Shows without price categories can be booked without a category.
+func getTicketPrice(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID, category string) (*entities.Money, error) {
+	if category == "" {
+		var hasPriceCategories bool
+		err := tx.GetContext(ctx, &hasPriceCategories, `SELECT EXISTS (SELECT 1 FROM show_price_categories WHERE show_id = $1)`, showID)
+		if err != nil {
+			return nil, fmt.Errorf("could not check price categories: %w", err)
+		}
+
+		if hasPriceCategories {
+			return nil, ErrPriceCategoryRequired
+		}
+
+		return nil, nil
+	}
+
+	var price entities.Money
+	err := tx.GetContext(ctx, &price, `
 		SELECT
-			COALESCE(SUM(number_of_tickets), 0) AS already_booked_seats
+			price_amount AS amount, price_currency AS currency
+		FROM
+			show_price_categories
+		WHERE
+			show_id = $1 AND category = $2
+	`, showID, category)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrPriceCategoryNotFound, category)
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get price of category %s: %w", category, err)
+	}
+
+	return &price, nil
+}
+
+func findBooking(ctx context.Context, tx *sqlx.Tx, bookingID uuid.UUID) (entities.Booking, error) {
+	var booking struct {
+		entities.Booking
+		PriceAmount   sql.Null[entities.Decimal] `db:"price_amount"`
+		PriceCurrency sql.NullString             `db:"price_currency"`
+
+		OriginalPriceAmount sql.Null[entities.Decimal] `db:"original_price_amount"`
+	}
+	err := tx.GetContext(ctx, &booking, `
+		SELECT
+			booking_id,
+			show_id,
+			number_of_tickets,
+			customer_email,
+			COALESCE(price_category, '') AS price_category,
+			price_amount,
+			price_currency,
+			COALESCE(promo_code, '') AS promo_code,
+			original_price_amount
 		FROM
 			bookings
 		WHERE
-			show_id = $1
-	`, showID)
+			booking_id = $1
+	`, bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Booking{}, ErrBookingNotFound
+	} else if err != nil {
+		return entities.Booking{}, fmt.Errorf("could not get booking %s: %w", bookingID, err)
+	}
+
+	if booking.PriceAmount.Valid {
+		booking.TicketPrice = &entities.Money{
+			Amount:   booking.PriceAmount.V,
+			Currency: booking.PriceCurrency.String,
+		}
+	}
+
+	if booking.PromoCode != "" && booking.OriginalPriceAmount.Valid && booking.TicketPrice != nil {
+		originalPrice := entities.Money{Amount: booking.OriginalPriceAmount.V, Currency: booking.TicketPrice.Currency}
+		discount, err := originalPrice.Sub(*booking.TicketPrice)
+		if err != nil {
+			return entities.Booking{}, err
+		}
+
+		booking.Discount = &entities.BookingDiscount{
+			PromoCode:           booking.PromoCode,
+			OriginalTicketPrice: originalPrice,
+			TicketDiscount:      discount,
+		}
+	}
+
+	booking.Seats, err = findSeats(ctx, tx, bookingSeats(bookingID))
 	if err != nil {
-		return 0, fmt.Errorf("could not get already booked seats: %w", err)
+		return entities.Booking{}, err
 	}
-	return alreadyBookedSeats, nil
+
+	return booking.Booking, nil
+}
+
+// applyPromoCode redeems the booking's promo code and discounts its ticket price.
+func applyPromoCode(ctx context.Context, tx *sqlx.Tx, booking *entities.Booking) error {
+	if booking.TicketPrice == nil {
+		return fmt.Errorf("%w: show has no ticket prices to discount", entities.ErrPromoCodeNotApplicable)
+	}
+
+	promoCode, err := redeemPromoCode(ctx, tx, booking.PromoCode, booking.ShowID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	discount, err := promoCode.Apply(*booking.TicketPrice)
+	if err != nil {
+		return err
+	}
+
+	ticketPrice := discount.TicketPrice()
+	booking.TicketPrice = &ticketPrice
+	booking.Discount = &discount
+
+	return nil
 }
 
 func insertBooking(ctx context.Context, tx *sqlx.Tx, booking entities.Booking) error {
+	var priceAmount, priceCurrency, originalPriceAmount *string
+	if booking.TicketPrice != nil {
+		amount := booking.TicketPrice.Amount.String()
+		priceAmount = &amount
+		priceCurrency = &booking.TicketPrice.Currency
+	}
+	if booking.Discount != nil {
+		amount := booking.Discount.OriginalTicketPrice.Amount.String()
+		originalPriceAmount = &amount
+	}
+
 	insertSql := `
 		INSERT INTO
-			bookings (booking_id, show_id, number_of_tickets, customer_email)
+			bookings (booking_id, show_id, number_of_tickets, customer_email, price_category, price_amount, price_currency, idempotency_key, promo_code, original_price_amount)
 		VALUES
-			(:booking_id, :show_id, :number_of_tickets, :customer_email)
+			($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($8, ''), NULLIF($9, ''), $10)
 	`
-	_, err := tx.NamedExecContext(ctx, insertSql, booking)
+	_, err := tx.ExecContext(
+		ctx,
+		insertSql,
+		booking.BookingID,
+		booking.ShowID,
+		booking.NumberOfTickets,
+		booking.CustomerEmail,
+		booking.PriceCategory,
+		priceAmount,
+		priceCurrency,
+		booking.IdempotencyKey,
+		booking.PromoCode,
+		originalPriceAmount,
+	)
 	if err != nil {
 		return fmt.Errorf("could not add booking: %w", err)
 	}
 	return nil
 }
 
-func publishBookingMadeEvent(ctx context.Context, tx *sqlx.Tx, booking entities.Booking) error {
+// publishBookingMadeEvent publishes BookingMade_v1, holdID is set for bookings made by confirming a seat hold.
+func publishBookingMadeEvent(ctx context.Context, tx *sqlx.Tx, booking entities.Booking, holdID *uuid.UUID) error {
 	publisher, err := outbox.NewPublisherForDB(ctx, tx)
 	if err != nil {
 		return fmt.Errorf("could not create event bus: %w", err)
@@ -118,7 +705,23 @@ func publishBookingMadeEvent(ctx context.Context, tx *sqlx.Tx, booking entities.
 		BookingID:       booking.BookingID,
 		CustomerEmail:   booking.CustomerEmail,
 		ShowID:          booking.ShowID,
+		Seats:           booking.Seats,
+		PriceCategory:   booking.PriceCategory,
+		TicketPrice:     booking.TicketPrice,
+		Discount:        booking.Discount,
+		HoldID:          holdID,
 	}
 
 	return bus.Publish(ctx, e)
 }
+
+func publishSeatsReleasedEvent(ctx context.Context, tx *sqlx.Tx, e entities.SeatsReleased_v1) error {
+	publisher, err := outbox.NewPublisherForDB(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("could not create event bus: %w", err)
+	}
+
+	bus := event.NewEventBus(publisher)
+
+	return bus.Publish(ctx, &e)
+}
@@ -38,7 +38,7 @@ func TestBookingsRepository_AddBooking_seats_limit(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = bookings.AddBooking(ctx, entities.Booking{
+		_, err = bookings.AddBooking(ctx, entities.Booking{
 			BookingID:       uuid.New(),
 			ShowID:          showID,
 			NumberOfTickets: 2,
@@ -46,7 +46,7 @@ func TestBookingsRepository_AddBooking_seats_limit(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = bookings.AddBooking(ctx, entities.Booking{
+		_, err = bookings.AddBooking(ctx, entities.Booking{
 			BookingID:       uuid.New(),
 			ShowID:          showID,
 			NumberOfTickets: 2,
@@ -82,7 +82,7 @@ func TestBookingsRepository_AddBooking_seats_limit(t *testing.T) {
 
 				// we are synchronizing goroutines to make sure that chance of overbooking is as high as possible
 				<-unlock
-				err = bookings.AddBooking(ctx, entities.Booking{
+				_, err = bookings.AddBooking(ctx, entities.Booking{
 					BookingID:       uuid.New(),
 					ShowID:          showID,
 					NumberOfTickets: 2,
@@ -120,3 +120,347 @@ func TestBookingsRepository_AddBooking_seats_limit(t *testing.T) {
 		}
 	})
 }
+
+func TestBookingsRepository_AddBooking_seats_allocation(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	bookings := db.NewBookingRepository(testDB)
+	shows := db.NewShowRepository(testDB)
+
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:          showID,
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 4,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+		SeatMap: &entities.SeatMap{
+			Sections: []entities.SeatMapSection{
+				{
+					Name: "A",
+					Rows: []entities.SeatMapRow{
+						{Name: "1", Seats: 2},
+						{Name: "2", Seats: 2},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	booking, err := bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+		Seats:           []entities.Seat{{Section: "A", Row: "2", Number: 2}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []entities.Seat{{Section: "A", Row: "2", Number: 2}}, booking.Seats)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+		Seats:           []entities.Seat{{Section: "A", Row: "2", Number: 2}},
+	})
+	require.ErrorIs(t, err, db.ErrSeatNotAvailable)
+
+	booking, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 3,
+		CustomerEmail:   "foo@bar.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []entities.Seat{
+		{Section: "A", Row: "1", Number: 1},
+		{Section: "A", Row: "1", Number: 2},
+		{Section: "A", Row: "2", Number: 1},
+	}, booking.Seats)
+
+	seat, err := bookings.AssignSeatToTicket(ctx, booking.BookingID, uuid.NewString())
+	require.NoError(t, err)
+	require.NotNil(t, seat)
+	assert.Equal(t, entities.Seat{Section: "A", Row: "1", Number: 1}, *seat)
+}
+
+func TestBookingsRepository_AddBooking_price_category(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	bookings := db.NewBookingRepository(testDB)
+	shows := db.NewShowRepository(testDB)
+
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:          showID,
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 10,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+		PriceCategories: []entities.PriceCategory{
+			{Name: "standard", Price: entities.MustNewMoney("50.00", "EUR")},
+			{Name: "vip", Price: entities.MustNewMoney("120.00", "EUR")},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+	})
+	require.ErrorIs(t, err, db.ErrPriceCategoryRequired)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+		PriceCategory:   "student",
+	})
+	require.ErrorIs(t, err, db.ErrPriceCategoryNotFound)
+
+	booking, err := bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   "foo@bar.com",
+		PriceCategory:   "vip",
+	})
+	require.NoError(t, err)
+
+	storedBooking, err := bookings.BookingByID(ctx, booking.BookingID)
+	require.NoError(t, err)
+	assert.Equal(t, "vip", storedBooking.PriceCategory)
+	require.NotNil(t, storedBooking.TicketPrice)
+	assert.Equal(t, entities.MustNewMoney("120.00", "EUR"), *storedBooking.TicketPrice)
+}
+
+func TestBookingsRepository_AddBooking_promo_code(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	bookings := db.NewBookingRepository(testDB)
+	shows := db.NewShowRepository(testDB)
+	promoCodes := db.NewPromoCodeRepository(testDB)
+
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:          showID,
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 10,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+		PriceCategories: []entities.PriceCategory{
+			{Name: "standard", Price: entities.MustNewMoney("50.00", "EUR")},
+		},
+	})
+	require.NoError(t, err)
+
+	maxRedemptions := 1
+	code := "TEST-" + uuid.NewString()[:8]
+	err = promoCodes.AddPromoCode(ctx, entities.PromoCode{
+		Code:           code,
+		DiscountType:   entities.PromoCodePercentage,
+		Percentage:     15,
+		ShowID:         &showID,
+		MaxRedemptions: &maxRedemptions,
+	})
+	require.NoError(t, err)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+		PriceCategory:   "standard",
+		PromoCode:       "UNKNOWN-" + code,
+	})
+	require.ErrorIs(t, err, db.ErrPromoCodeNotFound)
+
+	booking, err := bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   "foo@bar.com",
+		PriceCategory:   "standard",
+		PromoCode:       code,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, booking.TicketPrice)
+	assert.Equal(t, "42.50 EUR", booking.TicketPrice.String())
+
+	storedBooking, err := bookings.BookingByID(ctx, booking.BookingID)
+	require.NoError(t, err)
+	require.NotNil(t, storedBooking.Discount)
+	assert.Equal(t, code, storedBooking.Discount.PromoCode)
+	assert.Equal(t, "50.00 EUR", storedBooking.Discount.OriginalTicketPrice.String())
+	assert.Equal(t, "7.50 EUR", storedBooking.Discount.TicketDiscount.String())
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+		PriceCategory:   "standard",
+		PromoCode:       code,
+	})
+	require.ErrorIs(t, err, db.ErrPromoCodeExhausted)
+}
+
+func TestBookingsRepository_AddBooking_idempotency_key(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	bookings := db.NewBookingRepository(testDB)
+	shows := db.NewShowRepository(testDB)
+
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:          showID,
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 4,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+		SeatMap: &entities.SeatMap{
+			Sections: []entities.SeatMapSection{
+				{Name: "A", Rows: []entities.SeatMapRow{{Name: "1", Seats: 4}}},
+			},
+		},
+		PriceCategories: []entities.PriceCategory{
+			{Name: "standard", Price: entities.MustNewMoney("50.00", "EUR")},
+			{Name: "vip", Price: entities.MustNewMoney("120.00", "EUR")},
+		},
+	})
+	require.NoError(t, err)
+
+	idempotencyKey := uuid.NewString()
+	newBooking := func(priceCategory string, seats ...entities.Seat) entities.Booking {
+		return entities.Booking{
+			BookingID:       uuid.New(),
+			ShowID:          showID,
+			NumberOfTickets: 1,
+			CustomerEmail:   "foo@bar.com",
+			PriceCategory:   priceCategory,
+			Seats:           seats,
+			IdempotencyKey:  idempotencyKey,
+		}
+	}
+
+	booking, err := bookings.AddBooking(ctx, newBooking("standard", entities.Seat{Section: "A", Row: "1", Number: 2}))
+	require.NoError(t, err)
+
+	replayed, err := bookings.AddBooking(ctx, newBooking("standard", entities.Seat{Section: "A", Row: "1", Number: 2}))
+	require.NoError(t, err)
+	assert.Equal(t, booking.BookingID, replayed.BookingID)
+
+	_, err = bookings.AddBooking(ctx, newBooking("vip", entities.Seat{Section: "A", Row: "1", Number: 2}))
+	require.ErrorIs(t, err, db.ErrIdempotencyKeyReused)
+
+	_, err = bookings.AddBooking(ctx, newBooking("standard", entities.Seat{Section: "A", Row: "1", Number: 3}))
+	require.ErrorIs(t, err, db.ErrIdempotencyKeyReused)
+}
+
+func TestBookingsRepository_AddBooking_purchase_limit(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	bookings := db.NewBookingRepository(testDB)
+	shows := db.NewShowRepository(testDB)
+
+	maxTicketsPerCustomer := 3
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:                showID,
+		DeadNationID:          uuid.New(),
+		NumberOfTickets:       100,
+		StartTime:             time.Now().Add(time.Hour),
+		Title:                 "Example title",
+		Venue:                 "Example venue",
+		MaxTicketsPerCustomer: &maxTicketsPerCustomer,
+	})
+	require.NoError(t, err)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   "scalper@bar.com",
+	})
+	require.NoError(t, err)
+
+	// emails are compared case-insensitively
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   "Scalper@bar.com",
+	})
+	require.ErrorIs(t, err, db.ErrPurchaseLimitExceeded)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "scalper@bar.com",
+	})
+	require.NoError(t, err)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 3,
+		CustomerEmail:   "fan@bar.com",
+	})
+	require.NoError(t, err)
+}
+
+func TestBookingsRepository_CancelBooking_releases_seats(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	bookings := db.NewBookingRepository(testDB)
+	shows := db.NewShowRepository(testDB)
+
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:          showID,
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 2,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+	})
+	require.NoError(t, err)
+
+	booking, err := bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   "foo@bar.com",
+	})
+	require.NoError(t, err)
+
+	err = bookings.CancelBooking(ctx, booking.BookingID)
+	require.NoError(t, err)
+
+	err = bookings.CancelBooking(ctx, booking.BookingID)
+	require.ErrorIs(t, err, db.ErrBookingAlreadyCanceled)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 2,
+		CustomerEmail:   "foo@bar.com",
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tickets/entities"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+const deadLetterColumns = `
+	id, message_id, topic, handler, error, correlation_id, payload, metadata, dead_lettered_at
+`
+
+type DeadLetterRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeadLetterRepository(db *sqlx.DB) DeadLetterRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return DeadLetterRepository{db: db}
+}
+
+// AddDeadLetter stores the dead letter, a message which is already stored for the handler is ignored,
+// so redelivered poison messages are stored once.
+func (r DeadLetterRepository) AddDeadLetter(ctx context.Context, deadLetter entities.DeadLetter) error {
+	metadata, err := json.Marshal(deadLetter.Metadata)
+	if err != nil {
+		return fmt.Errorf("could not marshal dead letter metadata: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO
+			dead_letters (id, message_id, topic, handler, error, correlation_id, payload, metadata, dead_lettered_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (message_id, handler) DO NOTHING
+	`,
+		deadLetter.ID,
+		deadLetter.MessageID,
+		deadLetter.Topic,
+		deadLetter.Handler,
+		deadLetter.Error,
+		deadLetter.CorrelationID,
+		deadLetter.Payload,
+		metadata,
+		deadLetter.DeadLetteredAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("could not add dead letter of message %s: %w", deadLetter.MessageID, err)
+	}
+
+	return nil
+}
+
+// FindAll returns dead letters from the oldest, only of the handler if it's not empty.
+func (r DeadLetterRepository) FindAll(ctx context.Context, handler string) ([]entities.DeadLetter, error) {
+	var rows []deadLetterRow
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+deadLetterColumns+`
+		FROM dead_letters
+		WHERE $1 = '' OR handler = $1
+		ORDER BY dead_lettered_at, id
+	`, handler)
+	if err != nil {
+		return nil, fmt.Errorf("could not find dead letters: %w", err)
+	}
+
+	deadLetters := make([]entities.DeadLetter, 0, len(rows))
+	for _, row := range rows {
+		deadLetter, err := row.deadLetter()
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	return deadLetters, nil
+}
+
+func (r DeadLetterRepository) FindByID(ctx context.Context, id uuid.UUID) (entities.DeadLetter, error) {
+	var row deadLetterRow
+	err := r.db.GetContext(ctx, &row, `SELECT `+deadLetterColumns+` FROM dead_letters WHERE id = $1`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.DeadLetter{}, ErrDeadLetterNotFound
+	} else if err != nil {
+		return entities.DeadLetter{}, fmt.Errorf("could not find dead letter %s: %w", id, err)
+	}
+
+	return row.deadLetter()
+}
+
+// ReplayDeadLetter removes the dead letter and calls replay with it. The dead letter is kept when replay fails,
+// so it can be replayed again. Replaying the same dead letter concurrently calls replay only once.
+func (r DeadLetterRepository) ReplayDeadLetter(
+	ctx context.Context,
+	id uuid.UUID,
+	replay func(ctx context.Context, deadLetter entities.DeadLetter) error,
+) error {
+	// runInTx doesn't retry, replay publishes the message, so it must not be called more than once
+	return runInTx(ctx, r.db, sql.LevelReadCommitted, func(ctx context.Context, tx *sqlx.Tx) error {
+		deadLetter, err := deleteDeadLetter(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		return replay(ctx, deadLetter)
+	})
+}
+
+// DiscardDeadLetter removes the dead letter without processing the message.
+func (r DeadLetterRepository) DiscardDeadLetter(ctx context.Context, id uuid.UUID) error {
+	return runInTx(ctx, r.db, sql.LevelReadCommitted, func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := deleteDeadLetter(ctx, tx, id)
+		return err
+	})
+}
+
+func deleteDeadLetter(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (entities.DeadLetter, error) {
+	var row deadLetterRow
+	err := tx.GetContext(ctx, &row, `DELETE FROM dead_letters WHERE id = $1 RETURNING `+deadLetterColumns, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.DeadLetter{}, ErrDeadLetterNotFound
+	} else if err != nil {
+		return entities.DeadLetter{}, fmt.Errorf("could not delete dead letter %s: %w", id, err)
+	}
+
+	return row.deadLetter()
+}
+
+type deadLetterRow struct {
+	entities.DeadLetter
+	MetadataJSON []byte `db:"metadata"`
+}
+
+func (r deadLetterRow) deadLetter() (entities.DeadLetter, error) {
+	deadLetter := r.DeadLetter
+	if err := json.Unmarshal(r.MetadataJSON, &deadLetter.Metadata); err != nil {
+		return entities.DeadLetter{}, fmt.Errorf("could not unmarshal metadata of dead letter %s: %w", deadLetter.ID, err)
+	}
+
+	return deadLetter, nil
+}
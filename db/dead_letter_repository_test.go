@@ -0,0 +1,101 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/db"
+	"tickets/entities"
+)
+
+func TestDeadLetterRepository(t *testing.T) {
+	ctx := context.Background()
+
+	deadLetters := db.NewDeadLetterRepository(getDBTest())
+
+	// unique handler, so the test doesn't see dead letters of other tests
+	handler := "handler-" + uuid.NewString()
+
+	deadLetter := entities.DeadLetter{
+		ID:             uuid.New(),
+		MessageID:      uuid.NewString(),
+		Topic:          "events.TicketBookingConfirmed_v1",
+		Handler:        handler,
+		Error:          "something went wrong",
+		CorrelationID:  "correlation-" + uuid.NewString(),
+		Payload:        `{"ticket_id":"1"}`,
+		Metadata:       map[string]string{"name": "TicketBookingConfirmed_v1"},
+		DeadLetteredAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	require.NoError(t, deadLetters.AddDeadLetter(ctx, deadLetter))
+
+	redelivered := deadLetter
+	redelivered.ID = uuid.New()
+	require.NoError(t, deadLetters.AddDeadLetter(ctx, redelivered), "redelivered dead letter should be ignored")
+
+	found, err := deadLetters.FindAll(ctx, handler)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assertDeadLetterEqual(t, deadLetter, found[0])
+
+	t.Run("failed_replay_keeps_dead_letter", func(t *testing.T) {
+		err := deadLetters.ReplayDeadLetter(ctx, deadLetter.ID, func(ctx context.Context, d entities.DeadLetter) error {
+			assertDeadLetterEqual(t, deadLetter, d)
+			return errors.New("publish failed")
+		})
+		require.Error(t, err)
+
+		_, err = deadLetters.FindByID(ctx, deadLetter.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("replay_removes_dead_letter", func(t *testing.T) {
+		replayed := 0
+		err := deadLetters.ReplayDeadLetter(ctx, deadLetter.ID, func(ctx context.Context, d entities.DeadLetter) error {
+			replayed++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, replayed)
+
+		_, err = deadLetters.FindByID(ctx, deadLetter.ID)
+		assert.ErrorIs(t, err, db.ErrDeadLetterNotFound)
+
+		err = deadLetters.ReplayDeadLetter(ctx, deadLetter.ID, func(ctx context.Context, d entities.DeadLetter) error {
+			replayed++
+			return nil
+		})
+		assert.ErrorIs(t, err, db.ErrDeadLetterNotFound)
+		assert.Equal(t, 1, replayed)
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		discarded := deadLetter
+		discarded.ID = uuid.New()
+		discarded.MessageID = uuid.NewString()
+		require.NoError(t, deadLetters.AddDeadLetter(ctx, discarded))
+
+		require.NoError(t, deadLetters.DiscardDeadLetter(ctx, discarded.ID))
+		assert.ErrorIs(t, deadLetters.DiscardDeadLetter(ctx, discarded.ID), db.ErrDeadLetterNotFound)
+
+		found, err := deadLetters.FindAll(ctx, handler)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func assertDeadLetterEqual(t *testing.T, expected, actual entities.DeadLetter) {
+	t.Helper()
+
+	assert.True(t, expected.DeadLetteredAt.Equal(actual.DeadLetteredAt), "dead lettered at %s", actual.DeadLetteredAt)
+
+	// the location of times read from the database differs
+	actual.DeadLetteredAt = expected.DeadLetteredAt
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"tickets/entities"
+)
+
+type ExchangeRateRepository struct {
+	db *sqlx.DB
+}
+
+func NewExchangeRateRepository(db *sqlx.DB) ExchangeRateRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ExchangeRateRepository{db: db}
+}
+
+// AddRates stores the rates, a rate of the same pair and effective date is replaced.
+func (r ExchangeRateRepository) AddRates(ctx context.Context, rates []entities.ExchangeRate) error {
+	updateFn := func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, rate := range rates {
+			rate.EffectiveFrom = rate.EffectiveFrom.UTC()
+
+			_, err := tx.NamedExecContext(ctx, `
+				INSERT INTO
+					exchange_rates (from_currency, to_currency, effective_from, rate)
+				VALUES
+					(:from_currency, :to_currency, :effective_from, :rate)
+				ON CONFLICT (from_currency, to_currency, effective_from) DO UPDATE SET rate = excluded.rate
+			`, rate)
+			if err != nil {
+				return fmt.Errorf("could not add exchange rate %s to %s: %w", rate.FromCurrency, rate.ToCurrency, err)
+			}
+		}
+
+		return nil
+	}
+
+	return updateInTx(ctx, r.db, sql.LevelReadCommitted, updateFn)
+}
+
+func (r ExchangeRateRepository) FindAll(ctx context.Context) ([]entities.ExchangeRate, error) {
+	var rates []entities.ExchangeRate
+	err := r.db.SelectContext(ctx, &rates, `
+		SELECT
+			from_currency, to_currency, effective_from, rate
+		FROM
+			exchange_rates
+		ORDER BY
+			from_currency, to_currency, effective_from
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("could not find exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
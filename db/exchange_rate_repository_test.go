@@ -0,0 +1,46 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/db"
+	"tickets/entities"
+)
+
+func TestExchangeRateRepository_AddRates_replaces_rate_of_same_effective_date(t *testing.T) {
+	ctx := context.Background()
+
+	exchangeRates := db.NewExchangeRateRepository(getDBTest())
+
+	// unique effective date, so the test doesn't see rates of other tests
+	effectiveFrom := time.Now().UTC().Truncate(time.Microsecond)
+
+	rate := entities.ExchangeRate{
+		FromCurrency:  "USD",
+		ToCurrency:    "EUR",
+		Rate:          entities.MustParseDecimal("0.91"),
+		EffectiveFrom: effectiveFrom,
+	}
+	require.NoError(t, exchangeRates.AddRates(ctx, []entities.ExchangeRate{rate}))
+
+	rate.Rate = entities.MustParseDecimal("0.92")
+	require.NoError(t, exchangeRates.AddRates(ctx, []entities.ExchangeRate{rate}))
+
+	rates, err := exchangeRates.FindAll(ctx)
+	require.NoError(t, err)
+
+	var found []entities.ExchangeRate
+	for _, r := range rates {
+		if r.FromCurrency == "USD" && r.ToCurrency == "EUR" && r.EffectiveFrom.Equal(effectiveFrom) {
+			found = append(found, r)
+		}
+	}
+
+	require.Len(t, found, 1)
+	assert.True(t, found[0].Rate.Equal(entities.MustParseDecimal("0.92")), "rate is %s", found[0].Rate)
+}
@@ -0,0 +1,284 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/outbox"
+)
+
+var (
+	ErrHoldNotFound = errors.New("seat hold not found")
+	ErrHoldExpired  = errors.New("seat hold expired")
+
+	ErrHoldPriceCategoryMismatch = errors.New("seat hold was made for a different price category")
+)
+
+// releaseExpiredHoldsBatchSize limits how many holds are released in one transaction.
+const releaseExpiredHoldsBatchSize = 100
+
+type HoldRepository struct {
+	db *sqlx.DB
+}
+
+func NewHoldRepository(db *sqlx.DB) HoldRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return HoldRepository{db: db}
+}
+
+func (h HoldRepository) AddHold(ctx context.Context, hold entities.SeatHold) (entities.SeatHold, error) {
+	var heldSeats []entities.Seat
+
+	updateFn := func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := reserveSeats(ctx, tx, hold.ShowID, 0, hold.NumberOfTickets); err != nil {
+			return err
+		}
+
+		if err := ensurePurchaseLimit(ctx, tx, hold.ShowID, hold.CustomerEmail, hold.NumberOfTickets); err != nil {
+			return err
+		}
+
+		// the price is taken when the hold is confirmed, the category is only checked here
+		if _, err := getTicketPrice(ctx, tx, hold.ShowID, hold.PriceCategory); err != nil {
+			return err
+		}
+
+		var err error
+		heldSeats, err = insertHold(ctx, tx, hold)
+		return err
+	}
+
+	if err := updateInTx(ctx, h.db, sql.LevelSerializable, updateFn); err != nil {
+		return entities.SeatHold{}, err
+	}
+
+	hold.Seats = heldSeats
+
+	return hold, nil
+}
+
+type seatHoldRow struct {
+	entities.SeatHold
+	BookingID  *uuid.UUID `db:"booking_id"`
+	ReleasedAt *time.Time `db:"released_at"`
+}
+
+// ConfirmHold turns the hold into a booking at the price of the hold's category. priceCategory is required
+// for holds made without a category, like waitlist offers, for shows with price categories.
+// Confirming already confirmed hold returns the same booking.
+func (h HoldRepository) ConfirmHold(ctx context.Context, holdID uuid.UUID, priceCategory string) (entities.Booking, error) {
+	var booking entities.Booking
+
+	updateFn := func(ctx context.Context, tx *sqlx.Tx) error {
+		var hold seatHoldRow
+		err := tx.GetContext(ctx, &hold, `
+			SELECT
+				hold_id,
+				show_id,
+				number_of_tickets,
+				customer_email,
+				expires_at,
+				COALESCE(price_category, '') AS price_category,
+				booking_id,
+				released_at
+			FROM
+				seat_holds
+			WHERE
+				hold_id = $1
+			FOR UPDATE
+		`, holdID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrHoldNotFound
+		} else if err != nil {
+			return fmt.Errorf("could not get seat hold: %w", err)
+		}
+
+		if hold.BookingID != nil {
+			booking, err = findBooking(ctx, tx, *hold.BookingID)
+			return err
+		}
+
+		if hold.ReleasedAt != nil || !time.Now().UTC().Before(hold.ExpiresAt) {
+			return ErrHoldExpired
+		}
+
+		if err := ensureShowNotCanceled(ctx, tx, hold.ShowID); err != nil {
+			return err
+		}
+
+		if hold.PriceCategory == "" {
+			hold.PriceCategory = priceCategory
+		} else if priceCategory != "" && priceCategory != hold.PriceCategory {
+			return fmt.Errorf("%w: %s", ErrHoldPriceCategoryMismatch, hold.PriceCategory)
+		}
+
+		ticketPrice, err := getTicketPrice(ctx, tx, hold.ShowID, hold.PriceCategory)
+		if err != nil {
+			return err
+		}
+
+		if err := updateSeatCounter(ctx, tx, hold.ShowID, hold.NumberOfTickets, -hold.NumberOfTickets); err != nil {
+			return err
+		}
+
+		booking = entities.Booking{
+			BookingID:       uuid.New(),
+			ShowID:          hold.ShowID,
+			NumberOfTickets: hold.NumberOfTickets,
+			CustomerEmail:   hold.CustomerEmail,
+			PriceCategory:   hold.PriceCategory,
+			TicketPrice:     ticketPrice,
+		}
+
+		if err := insertBooking(ctx, tx, booking); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE show_seats SET booking_id = $1, hold_id = NULL WHERE hold_id = $2`, booking.BookingID, holdID)
+		if err != nil {
+			return fmt.Errorf("could not move held seats to booking: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE seat_holds SET booking_id = $1 WHERE hold_id = $2`, booking.BookingID, holdID)
+		if err != nil {
+			return fmt.Errorf("could not confirm seat hold: %w", err)
+		}
+
+		booking.Seats, err = findSeats(ctx, tx, bookingSeats(booking.BookingID))
+		if err != nil {
+			return err
+		}
+
+		return publishBookingMadeEvent(ctx, tx, booking, &holdID)
+	}
+
+	if err := updateInTx(ctx, h.db, sql.LevelSerializable, updateFn); err != nil {
+		return entities.Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// ReleaseExpiredHolds frees seats of holds which expired without being confirmed, and returns how many holds were released.
+func (h HoldRepository) ReleaseExpiredHolds(ctx context.Context) (int, error) {
+	var released int
+
+	updateFn := func(ctx context.Context, tx *sqlx.Tx) error {
+		var holds []entities.SeatHold
+		err := tx.SelectContext(ctx, &holds, `
+			SELECT
+				hold_id, show_id, number_of_tickets, customer_email, expires_at
+			FROM
+				seat_holds
+			WHERE
+				booking_id IS NULL AND released_at IS NULL AND expires_at <= $1
+			ORDER BY
+				expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		`, time.Now().UTC(), releaseExpiredHoldsBatchSize)
+		if err != nil {
+			return fmt.Errorf("could not find expired seat holds: %w", err)
+		}
+
+		for _, hold := range holds {
+			if err := updateSeatCounter(ctx, tx, hold.ShowID, 0, -hold.NumberOfTickets); err != nil {
+				return err
+			}
+
+			hold.Seats, err = findSeats(ctx, tx, holdSeats(hold.HoldID))
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE show_seats SET hold_id = NULL WHERE hold_id = $1`, hold.HoldID)
+			if err != nil {
+				return fmt.Errorf("could not release held seats: %w", err)
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE seat_holds SET released_at = $1 WHERE hold_id = $2`, time.Now().UTC(), hold.HoldID)
+			if err != nil {
+				return fmt.Errorf("could not release seat hold: %w", err)
+			}
+
+			if err := publishSeatHoldExpiredEvent(ctx, tx, hold); err != nil {
+				return err
+			}
+		}
+
+		released = len(holds)
+		return nil
+	}
+
+	if err := updateInTx(ctx, h.db, sql.LevelRepeatableRead, updateFn); err != nil {
+		return 0, err
+	}
+
+	return released, nil
+}
+
+// insertHold adds the hold and allocates its seats. Seats must be reserved by the caller.
+func insertHold(ctx context.Context, tx *sqlx.Tx, hold entities.SeatHold) ([]entities.Seat, error) {
+	_, err := tx.NamedExecContext(ctx, `
+		INSERT INTO
+			seat_holds (hold_id, show_id, number_of_tickets, customer_email, expires_at, price_category)
+		VALUES
+			(:hold_id, :show_id, :number_of_tickets, :customer_email, :expires_at, NULLIF(:price_category, ''))
+	`, hold)
+	if err != nil {
+		return nil, fmt.Errorf("could not add seat hold: %w", err)
+	}
+
+	seats, err := allocateSeats(ctx, tx, hold.ShowID, hold.Seats, hold.NumberOfTickets, holdSeats(hold.HoldID))
+	if err != nil {
+		return nil, err
+	}
+
+	publisher, err := outbox.NewPublisherForDB(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("could not create event bus: %w", err)
+	}
+
+	bus := event.NewEventBus(publisher)
+
+	err = bus.Publish(ctx, &entities.SeatsHeld_v1{
+		Header:          entities.NewMessageHeader(),
+		HoldID:          hold.HoldID,
+		ShowID:          hold.ShowID,
+		NumberOfTickets: hold.NumberOfTickets,
+		ExpiresAt:       hold.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return seats, nil
+}
+
+func publishSeatHoldExpiredEvent(ctx context.Context, tx *sqlx.Tx, hold entities.SeatHold) error {
+	publisher, err := outbox.NewPublisherForDB(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("could not create event bus: %w", err)
+	}
+
+	bus := event.NewEventBus(publisher)
+
+	return bus.Publish(ctx, &entities.SeatHoldExpired_v1{
+		Header:          entities.NewMessageHeader(),
+		HoldID:          hold.HoldID,
+		ShowID:          hold.ShowID,
+		NumberOfTickets: hold.NumberOfTickets,
+		Seats:           hold.Seats,
+	})
+}
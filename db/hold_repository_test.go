@@ -0,0 +1,167 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/db"
+	"tickets/entities"
+)
+
+func TestHoldRepository(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	holds := db.NewHoldRepository(testDB)
+	bookings := db.NewBookingRepository(testDB)
+	shows := db.NewShowRepository(testDB)
+
+	addShow := func(t *testing.T, numberOfTickets int) uuid.UUID {
+		showID := uuid.New()
+		err := shows.AddShow(ctx, entities.Show{
+			ShowID:          showID,
+			DeadNationID:    uuid.New(),
+			NumberOfTickets: numberOfTickets,
+			StartTime:       time.Now().Add(time.Hour),
+			Title:           "Example title",
+			Venue:           "Example venue",
+		})
+		require.NoError(t, err)
+		return showID
+	}
+
+	t.Run("active_hold_takes_seats", func(t *testing.T) {
+		showID := addShow(t, 2)
+
+		_, err := holds.AddHold(ctx, entities.SeatHold{
+			HoldID:          uuid.New(),
+			ShowID:          showID,
+			NumberOfTickets: 2,
+			CustomerEmail:   "foo@bar.com",
+			ExpiresAt:       time.Now().UTC().Add(time.Minute),
+		})
+		require.NoError(t, err)
+
+		_, err = bookings.AddBooking(ctx, entities.Booking{
+			BookingID:       uuid.New(),
+			ShowID:          showID,
+			NumberOfTickets: 1,
+			CustomerEmail:   "foo@bar.com",
+		})
+		require.ErrorIs(t, err, db.ErrNotEnoughSeats)
+	})
+
+	t.Run("confirm_is_idempotent", func(t *testing.T) {
+		showID := addShow(t, 2)
+
+		hold, err := holds.AddHold(ctx, entities.SeatHold{
+			HoldID:          uuid.New(),
+			ShowID:          showID,
+			NumberOfTickets: 2,
+			CustomerEmail:   "foo@bar.com",
+			ExpiresAt:       time.Now().UTC().Add(time.Minute),
+		})
+		require.NoError(t, err)
+
+		booking, err := holds.ConfirmHold(ctx, hold.HoldID, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, booking.NumberOfTickets)
+
+		again, err := holds.ConfirmHold(ctx, hold.HoldID, "")
+		require.NoError(t, err)
+		assert.Equal(t, booking.BookingID, again.BookingID)
+	})
+
+	t.Run("confirm_takes_price_of_category", func(t *testing.T) {
+		showID := uuid.New()
+		err := shows.AddShow(ctx, entities.Show{
+			ShowID:          showID,
+			DeadNationID:    uuid.New(),
+			NumberOfTickets: 10,
+			StartTime:       time.Now().Add(time.Hour),
+			Title:           "Example title",
+			Venue:           "Example venue",
+			PriceCategories: []entities.PriceCategory{
+				{Name: "standard", Price: entities.MustNewMoney("50.00", "EUR")},
+				{Name: "vip", Price: entities.MustNewMoney("120.00", "EUR")},
+			},
+		})
+		require.NoError(t, err)
+
+		newHold := func(priceCategory string) entities.SeatHold {
+			return entities.SeatHold{
+				HoldID:          uuid.New(),
+				ShowID:          showID,
+				NumberOfTickets: 1,
+				CustomerEmail:   "foo@bar.com",
+				ExpiresAt:       time.Now().UTC().Add(time.Minute),
+				PriceCategory:   priceCategory,
+			}
+		}
+
+		_, err = holds.AddHold(ctx, newHold(""))
+		require.ErrorIs(t, err, db.ErrPriceCategoryRequired)
+
+		_, err = holds.AddHold(ctx, newHold("student"))
+		require.ErrorIs(t, err, db.ErrPriceCategoryNotFound)
+
+		hold, err := holds.AddHold(ctx, newHold("vip"))
+		require.NoError(t, err)
+
+		_, err = holds.ConfirmHold(ctx, hold.HoldID, "standard")
+		require.ErrorIs(t, err, db.ErrHoldPriceCategoryMismatch)
+
+		booking, err := holds.ConfirmHold(ctx, hold.HoldID, "")
+		require.NoError(t, err)
+		assert.Equal(t, "vip", booking.PriceCategory)
+		require.NotNil(t, booking.TicketPrice)
+		assert.Equal(t, "120.00 EUR", booking.TicketPrice.String())
+
+		// the confirmed booking is returned again with its price
+		again, err := holds.ConfirmHold(ctx, hold.HoldID, "")
+		require.NoError(t, err)
+		assert.Equal(t, booking.BookingID, again.BookingID)
+		assert.Equal(t, "vip", again.PriceCategory)
+		require.NotNil(t, again.TicketPrice)
+		assert.True(t, booking.TicketPrice.Equal(*again.TicketPrice))
+
+		storedBooking, err := bookings.BookingByID(ctx, booking.BookingID)
+		require.NoError(t, err)
+		assert.Equal(t, "vip", storedBooking.PriceCategory)
+		require.NotNil(t, storedBooking.TicketPrice)
+		assert.Equal(t, entities.MustNewMoney("120.00", "EUR"), *storedBooking.TicketPrice)
+	})
+
+	t.Run("expired_hold_is_released", func(t *testing.T) {
+		showID := addShow(t, 2)
+
+		hold, err := holds.AddHold(ctx, entities.SeatHold{
+			HoldID:          uuid.New(),
+			ShowID:          showID,
+			NumberOfTickets: 2,
+			CustomerEmail:   "foo@bar.com",
+			ExpiresAt:       time.Now().UTC().Add(-time.Second),
+		})
+		require.NoError(t, err)
+
+		_, err = holds.ConfirmHold(ctx, hold.HoldID, "")
+		require.ErrorIs(t, err, db.ErrHoldExpired)
+
+		released, err := holds.ReleaseExpiredHolds(ctx)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, released, 1)
+
+		_, err = bookings.AddBooking(ctx, entities.Booking{
+			BookingID:       uuid.New(),
+			ShowID:          showID,
+			NumberOfTickets: 2,
+			CustomerEmail:   "foo@bar.com",
+		})
+		require.NoError(t, err)
+	})
+}
@@ -7,131 +7,59 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/google/uuid"
-
 	"tickets/entities"
 )
 
-type bookingMade_v0 struct {
-	Header entities.MessageHeader `json:"header"`
-
-	NumberOfTickets int `json:"number_of_tickets"`
-
-	BookingID uuid.UUID `json:"booking_id"`
-
-	CustomerEmail string    `json:"customer_email"`
-	ShowID        uuid.UUID `json:"show_id"`
-}
-
-type ticketBookingConfirmed_v0 struct {
-	Header entities.MessageHeader `json:"header"`
-
-	TicketID      string         `json:"ticket_id"`
-	CustomerEmail string         `json:"customer_email"`
-	Price         entities.Money `json:"price"`
-
-	BookingID string `json:"booking_id"`
-}
-
-type ticketReceiptIssued_v0 struct {
-	Header entities.MessageHeader `json:"header"`
-
-	TicketID      string `json:"ticket_id"`
-	ReceiptNumber string `json:"receipt_number"`
-
-	IssuedAt time.Time `json:"issued_at"`
-}
-
-type ticketPrinted_v0 struct {
-	Header entities.MessageHeader `json:"header"`
+// migrationHandler unmarshals the payload of the latest version of the event for the read model handler.
+func migrationHandler[T any](handle func(context.Context, *T) error) func(context.Context, []byte) error {
+	return func(ctx context.Context, payload []byte) error {
+		event := new(T)
+		if err := json.Unmarshal(payload, event); err != nil {
+			return fmt.Errorf("could not unmarshal event: %w", err)
+		}
 
-	TicketID string `json:"ticket_id"`
-	FileName string `json:"file_name"`
+		return handle(ctx, event)
+	}
 }
 
-type ticketRefunded_v0 struct {
-	Header entities.MessageHeader `json:"header"`
-
-	TicketID string `json:"ticket_id"`
+func opsReadModelMigrationHandlers(rm OpsBookingReadModel) map[string]func(context.Context, []byte) error {
+	return map[string]func(context.Context, []byte) error{
+		"BookingMade_v1":            migrationHandler(rm.OnBookingMade),
+		"BookingCanceled_v1":        migrationHandler(rm.OnBookingCanceled),
+		"TicketBookingConfirmed_v1": migrationHandler(rm.OnTicketBookingConfirmed),
+		"TicketRefunded_v1":         migrationHandler(rm.OnTicketRefunded),
+		"TicketPrinted_v1":          migrationHandler(rm.OnTicketPrinted),
+		"TicketReceiptIssued_v1":    migrationHandler(rm.OnTicketReceiptIssued),
+		"TicketTransferred_v1":      migrationHandler(rm.OnTicketTransferred),
+		"TicketCheckedIn_v1":        migrationHandler(rm.OnTicketCheckedIn),
+	}
 }
 
-func unmarshalDataLakeEvent[T any](event entities.DataLakeEvent) (*T, error) {
-	eventInstance := new(T)
-
-	err := json.Unmarshal(event.EventPayload, &eventInstance)
+func migrateEvent(
+	ctx context.Context,
+	event entities.DataLakeEvent,
+	upcasters entities.EventUpcasters,
+	handlers map[string]func(context.Context, []byte) error,
+) error {
+	eventName, payload, err := upcasters.Upcast(event.EventName, event.EventPayload)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal event %s: %w", event.EventName, err)
+		return err
 	}
 
-	return eventInstance, nil
-}
-
-func migrateEvent(ctx context.Context, event entities.DataLakeEvent, rm OpsBookingReadModel) error {
-	switch event.EventName {
-	case "BookingMade_v0":
-		bookingMade, err := unmarshalDataLakeEvent[bookingMade_v0](event)
-		if err != nil {
-			return err
-		}
-
-		return rm.OnBookingMade(ctx, &entities.BookingMade_v1{
-			Header:          bookingMade.Header,
-			NumberOfTickets: bookingMade.NumberOfTickets,
-			BookingID:       bookingMade.BookingID,
-			CustomerEmail:   bookingMade.CustomerEmail,
-			ShowID:          bookingMade.ShowID,
-		})
-	case "TicketBookingConfirmed_v0":
-		bookingConfirmedEvent, err := unmarshalDataLakeEvent[ticketBookingConfirmed_v0](event)
-		if err != nil {
-			return err
-		}
-
-		return rm.OnTicketBookingConfirmed(ctx, &entities.TicketBookingConfirmed_v1{
-			Header:        bookingConfirmedEvent.Header,
-			TicketID:      bookingConfirmedEvent.TicketID,
-			CustomerEmail: bookingConfirmedEvent.CustomerEmail,
-			Price:         bookingConfirmedEvent.Price,
-			BookingID:     bookingConfirmedEvent.BookingID,
-		})
-	case "TicketReceiptIssued_v0":
-		receiptIssuedEvent, err := unmarshalDataLakeEvent[ticketReceiptIssued_v0](event)
-		if err != nil {
-			return err
-		}
-
-		return rm.OnTicketReceiptIssued(ctx, &entities.TicketReceiptIssued_v1{
-			Header:        receiptIssuedEvent.Header,
-			TicketID:      receiptIssuedEvent.TicketID,
-			ReceiptNumber: receiptIssuedEvent.ReceiptNumber,
-			IssuedAt:      receiptIssuedEvent.IssuedAt,
-		})
-	case "TicketPrinted_v0":
-		ticketPrintedEvent, err := unmarshalDataLakeEvent[ticketPrinted_v0](event)
-		if err != nil {
-			return err
-		}
-
-		return rm.OnTicketPrinted(ctx, &entities.TicketPrinted_v1{
-			Header:   ticketPrintedEvent.Header,
-			TicketID: ticketPrintedEvent.TicketID,
-			FileName: ticketPrintedEvent.FileName,
-		})
-	case "TicketRefunded_v0":
-		ticketRefundedEvent, err := unmarshalDataLakeEvent[ticketRefunded_v0](event)
-		if err != nil {
-			return err
-		}
+	if eventName == event.EventName {
+		// events published in their latest version were already processed by the read model handlers
+		slog.Info("Skipping event in its latest version during migration", "event_name", event.EventName)
+		return nil
+	}
 
-		return rm.OnTicketRefunded(ctx, &entities.TicketRefunded_v1{
-			Header:   ticketRefundedEvent.Header,
-			TicketID: ticketRefundedEvent.TicketID,
-		})
-	default:
+	handle, ok := handlers[eventName]
+	if !ok {
 		// Skip unknown events (they may be events we don't need for this read model)
 		slog.Info("Skipping unknown event during migration", "event_name", event.EventName)
 		return nil
 	}
+
+	return handle(ctx, payload)
 }
 
 func MigrateOpsReadModel(ctx context.Context, dataLake DataLake, rm OpsBookingReadModel) {
@@ -170,8 +98,11 @@ func MigrateOpsReadModel(ctx context.Context, dataLake DataLake, rm OpsBookingRe
 
 	slog.Info("Migrating events", "count", len(events))
 
+	upcasters := entities.DefaultEventUpcasters()
+	handlers := opsReadModelMigrationHandlers(rm)
+
 	for i, event := range events {
-		if err := migrateEvent(ctx, event, rm); err != nil {
+		if err := migrateEvent(ctx, event, upcasters, handlers); err != nil {
 			slog.Error("Failed to migrate event",
 				"event_id", event.EventID,
 				"event_name", event.EventName,
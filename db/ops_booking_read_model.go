@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/ThreeDotsLabs/go-event-driven/v2/common/log"
@@ -35,10 +36,13 @@ func NewOpsBookingReadModel(db *sqlx.DB) OpsBookingReadModel {
 func (r OpsBookingReadModel) OnBookingMade(ctx context.Context, e *entities.BookingMade_v1) error {
 	// this is the first event that should arrive, so we create the read model
 	err := r.createReadModel(ctx, entities.OpsBooking{
-		BookingID:  e.BookingID,
-		Tickets:    map[string]entities.OpsTicket{},
-		LastUpdate: time.Now(),
-		BookedAt:   e.Header.PublishedAt,
+		BookingID:   e.BookingID,
+		Seats:       e.Seats,
+		TicketPrice: e.TicketPrice,
+		Discount:    e.Discount,
+		Tickets:     map[string]entities.OpsTicket{},
+		LastUpdate:  time.Now(),
+		BookedAt:    e.Header.PublishedAt,
 	})
 	if err != nil {
 		return fmt.Errorf("could not create read model: %w", err)
@@ -47,6 +51,18 @@ func (r OpsBookingReadModel) OnBookingMade(ctx context.Context, e *entities.Book
 	return nil
 }
 
+func (r OpsBookingReadModel) OnBookingCanceled(ctx context.Context, e *entities.BookingCanceled_v1) error {
+	return r.updateByBookingID(
+		ctx,
+		e.BookingID.String(),
+		func(rm entities.OpsBooking) (entities.OpsBooking, error) {
+			rm.CanceledAt = e.Header.PublishedAt
+
+			return rm, nil
+		},
+	)
+}
+
 func (r OpsBookingReadModel) OnTicketBookingConfirmed(ctx context.Context, e *entities.TicketBookingConfirmed_v1) error {
 	return r.updateByBookingID(
 		ctx,
@@ -58,9 +74,12 @@ func (r OpsBookingReadModel) OnTicketBookingConfirmed(ctx context.Context, e *en
 				log.FromContext(ctx).With("ticket_id", e.TicketID).Debug("Creating ticket read model for ticket %s")
 			}
 
-			ticket.PriceAmount = e.Price.Amount
+			ticket.PriceAmount = e.Price.Amount.String()
 			ticket.PriceCurrency = e.Price.Currency
-			ticket.CustomerEmail = e.CustomerEmail
+			if ticket.Revision == 0 {
+				// transferred tickets have a new holder
+				ticket.CustomerEmail = e.CustomerEmail
+			}
 			ticket.ConfirmedAt = e.Header.PublishedAt
 
 			rm.Tickets[e.TicketID] = ticket
@@ -77,18 +96,112 @@ func (r OpsBookingReadModel) OnTicketRefunded(ctx context.Context, e *entities.T
 		func(rm entities.OpsTicket) (entities.OpsTicket, error) {
 			rm.RefundedAt = e.Header.PublishedAt
 
-			return rm, nil
+			refund := entities.OpsTicketRefund{
+				IdempotencyKey: e.Header.IdempotencyKey,
+				// full refunds don't carry the amount, it's the ticket price
+				Amount:     rm.PriceAmount,
+				Partial:    e.Amount != nil,
+				Reason:     e.Reason,
+				RefundedAt: e.Header.PublishedAt,
+			}
+			if e.Amount != nil {
+				refund.Amount = e.Amount.Amount.String()
+			}
+
+			return addTicketRefund(rm, refund)
 		},
 	)
 }
 
+// addTicketRefund adds the refund to the ticket, a redelivered refund replaces the one with the same idempotency key.
+func addTicketRefund(ticket entities.OpsTicket, refund entities.OpsTicketRefund) (entities.OpsTicket, error) {
+	refunds := make([]entities.OpsTicketRefund, 0, len(ticket.Refunds)+1)
+	for _, r := range ticket.Refunds {
+		if r.IdempotencyKey != refund.IdempotencyKey {
+			refunds = append(refunds, r)
+		}
+	}
+	ticket.Refunds = append(refunds, refund)
+
+	total := entities.NewDecimalFromInt(0)
+	for _, r := range ticket.Refunds {
+		if r.Amount == "" {
+			// the ticket wasn't confirmed yet, so its price is unknown
+			ticket.RefundedAmount = ""
+			return ticket, nil
+		}
+
+		amount, err := entities.ParseDecimal(r.Amount)
+		if err != nil {
+			return entities.OpsTicket{}, fmt.Errorf("invalid refund amount: %w", err)
+		}
+		if total, err = total.Add(amount); err != nil {
+			return entities.OpsTicket{}, fmt.Errorf("could not sum refunds: %w", err)
+		}
+	}
+	ticket.RefundedAmount = total.String()
+
+	return ticket, nil
+}
+
 func (r OpsBookingReadModel) OnTicketPrinted(ctx context.Context, e *entities.TicketPrinted_v1) error {
 	return r.updateByTicketID(
 		ctx,
 		e.TicketID,
 		func(rm entities.OpsTicket) (entities.OpsTicket, error) {
+			if slices.Contains(rm.InvalidatedFileNames, e.FileName) {
+				// the file of the previous holder was printed after the ticket was transferred
+				return rm, nil
+			}
+
 			rm.PrintedAt = e.Header.PublishedAt
 			rm.PrintedFileName = e.FileName
+			rm.PrintedFileNames = e.FileNames
+			if len(rm.PrintedFileNames) == 0 {
+				// tickets printed before PDF tickets only have the HTML file
+				rm.PrintedFileNames = []string{e.FileName}
+			}
+
+			return rm, nil
+		},
+	)
+}
+
+func (r OpsBookingReadModel) OnTicketTransferred(ctx context.Context, e *entities.TicketTransferred_v1) error {
+	return r.updateByTicketID(
+		ctx,
+		e.TicketID,
+		func(rm entities.OpsTicket) (entities.OpsTicket, error) {
+			if e.Revision <= rm.Revision {
+				// redelivered or older than a transfer that was already applied
+				return rm, nil
+			}
+
+			// transfers can be applied out of order, so files of all skipped revisions are invalidated
+			for revision := rm.Revision; revision < e.Revision; revision++ {
+				rm.InvalidatedFileNames = append(rm.InvalidatedFileNames, entities.TicketFileNames(e.TicketID, revision)...)
+			}
+			if slices.Contains(rm.InvalidatedFileNames, rm.PrintedFileName) {
+				rm.PrintedAt = time.Time{}
+				rm.PrintedFileName = ""
+				rm.PrintedFileNames = nil
+			}
+
+			rm.CustomerEmail = e.CustomerEmail
+			rm.Revision = e.Revision
+			rm.TransferredAt = e.Header.PublishedAt
+
+			return rm, nil
+		},
+	)
+}
+
+func (r OpsBookingReadModel) OnTicketCheckedIn(ctx context.Context, e *entities.TicketCheckedIn_v1) error {
+	return r.updateByTicketID(
+		ctx,
+		e.TicketID,
+		func(rm entities.OpsTicket) (entities.OpsTicket, error) {
+			rm.CheckedInAt = e.CheckedInAt
 
 			return rm, nil
 		},
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tickets/entities"
+)
+
+var (
+	ErrPromoCodeNotFound      = errors.New("promo code not found")
+	ErrPromoCodeAlreadyExists = errors.New("promo code already exists")
+	ErrPromoCodeExhausted     = errors.New("promo code has no redemptions left")
+)
+
+const promoCodeColumns = `
+	code, discount_type, percentage, amount, currency, show_id, valid_from, valid_until, max_redemptions, redemptions
+`
+
+type PromoCodeRepository struct {
+	db *sqlx.DB
+}
+
+func NewPromoCodeRepository(db *sqlx.DB) PromoCodeRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return PromoCodeRepository{db: db}
+}
+
+func (r PromoCodeRepository) AddPromoCode(ctx context.Context, promoCode entities.PromoCode) error {
+	var amount, currency *string
+	if promoCode.Amount != nil {
+		amountString := promoCode.Amount.Amount.String()
+		amount = &amountString
+		currency = &promoCode.Amount.Currency
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO
+			promo_codes (code, discount_type, percentage, amount, currency, show_id, valid_from, valid_until, max_redemptions)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		promoCode.Code,
+		promoCode.DiscountType,
+		promoCode.Percentage,
+		amount,
+		currency,
+		promoCode.ShowID,
+		utcTime(promoCode.ValidFrom),
+		utcTime(promoCode.ValidUntil),
+		promoCode.MaxRedemptions,
+	)
+	if isUniqueViolation(err, "promo_codes_pkey") {
+		return ErrPromoCodeAlreadyExists
+	} else if err != nil {
+		return fmt.Errorf("could not add promo code: %w", err)
+	}
+
+	return nil
+}
+
+func (r PromoCodeRepository) FindAll(ctx context.Context) ([]entities.PromoCode, error) {
+	var rows []promoCodeRow
+	err := r.db.SelectContext(ctx, &rows, `SELECT `+promoCodeColumns+` FROM promo_codes ORDER BY code`)
+	if err != nil {
+		return nil, fmt.Errorf("could not find promo codes: %w", err)
+	}
+
+	promoCodes := make([]entities.PromoCode, 0, len(rows))
+	for _, row := range rows {
+		promoCodes = append(promoCodes, row.promoCode())
+	}
+
+	return promoCodes, nil
+}
+
+// redeemPromoCode counts the redemption of the code for a booking of the show.
+// The promo code row is locked until the booking transaction ends, so concurrent bookings can't exceed max redemptions.
+func redeemPromoCode(ctx context.Context, tx *sqlx.Tx, code string, showID uuid.UUID, at time.Time) (entities.PromoCode, error) {
+	var row promoCodeRow
+	err := tx.GetContext(ctx, &row, `SELECT `+promoCodeColumns+` FROM promo_codes WHERE code = $1 FOR UPDATE`, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.PromoCode{}, fmt.Errorf("%w: %s", ErrPromoCodeNotFound, code)
+	} else if err != nil {
+		return entities.PromoCode{}, fmt.Errorf("could not get promo code: %w", err)
+	}
+
+	promoCode := row.promoCode()
+
+	if err := promoCode.CheckApplicable(showID, at); err != nil {
+		return entities.PromoCode{}, err
+	}
+
+	if promoCode.MaxRedemptions != nil && promoCode.Redemptions >= *promoCode.MaxRedemptions {
+		return entities.PromoCode{}, fmt.Errorf("%w: %s", ErrPromoCodeExhausted, code)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE promo_codes SET redemptions = redemptions + 1 WHERE code = $1`, code)
+	if err != nil {
+		return entities.PromoCode{}, fmt.Errorf("could not redeem promo code: %w", err)
+	}
+	promoCode.Redemptions++
+
+	return promoCode, nil
+}
+
+type promoCodeRow struct {
+	entities.PromoCode
+	Amount   sql.Null[entities.Decimal] `db:"amount"`
+	Currency sql.NullString             `db:"currency"`
+}
+
+func (r promoCodeRow) promoCode() entities.PromoCode {
+	promoCode := r.PromoCode
+	if r.Amount.Valid {
+		promoCode.Amount = &entities.Money{Amount: r.Amount.V, Currency: r.Currency.String}
+	}
+
+	return promoCode
+}
+
+// utcTime converts the time for TIMESTAMP columns, which don't store the time zone.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	utc := t.UTC()
+	return &utc
+}
@@ -41,12 +41,138 @@ func InitializeDatabaseSchema(db *sqlx.DB) error {
 			FOREIGN KEY (show_id) REFERENCES shows(show_id)
 		);
 
+		CREATE TABLE IF NOT EXISTS show_seats (
+			show_id UUID NOT NULL,
+			seat_section VARCHAR(64) NOT NULL,
+			seat_row VARCHAR(64) NOT NULL,
+			seat_number INT NOT NULL,
+			position INT NOT NULL,
+			booking_id UUID NULL,
+			ticket_id UUID NULL,
+			PRIMARY KEY (show_id, seat_section, seat_row, seat_number),
+			FOREIGN KEY (show_id) REFERENCES shows(show_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS seat_holds (
+			hold_id UUID PRIMARY KEY,
+			show_id UUID NOT NULL,
+			number_of_tickets INT NOT NULL,
+			customer_email VARCHAR(255) NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			booking_id UUID NULL,
+			released_at TIMESTAMP NULL,
+			FOREIGN KEY (show_id) REFERENCES shows(show_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS show_price_categories (
+			show_id UUID NOT NULL,
+			category VARCHAR(64) NOT NULL,
+			price_amount NUMERIC(10, 2) NOT NULL,
+			price_currency CHAR(3) NOT NULL,
+			PRIMARY KEY (show_id, category),
+			FOREIGN KEY (show_id) REFERENCES shows(show_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS show_seat_counters (
+			show_id UUID PRIMARY KEY,
+			capacity INT NOT NULL,
+			booked INT NOT NULL,
+			held INT NOT NULL,
+			FOREIGN KEY (show_id) REFERENCES shows(show_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS read_model_show_availability (
+			show_id UUID PRIMARY KEY,
+			capacity INT NOT NULL,
+			booked INT NOT NULL,
+			held INT NOT NULL,
+			refunded INT NOT NULL,
+			last_update TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS read_model_show_availability_processed_events (
+			event_id VARCHAR(255) PRIMARY KEY
+		);
+
+		CREATE TABLE IF NOT EXISTS waitlist_entries (
+			entry_id UUID PRIMARY KEY,
+			show_id UUID NOT NULL,
+			number_of_tickets INT NOT NULL,
+			customer_email VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			hold_id UUID NULL,
+			offered_at TIMESTAMP NULL,
+			FOREIGN KEY (show_id) REFERENCES shows(show_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS exchange_rates (
+			from_currency CHAR(3) NOT NULL,
+			to_currency CHAR(3) NOT NULL,
+			effective_from TIMESTAMP NOT NULL,
+			rate NUMERIC(18, 6) NOT NULL,
+			PRIMARY KEY (from_currency, to_currency, effective_from)
+		);
+
+		CREATE TABLE IF NOT EXISTS promo_codes (
+			code VARCHAR(64) PRIMARY KEY,
+			discount_type VARCHAR(16) NOT NULL,
+			percentage INT NOT NULL DEFAULT 0,
+			amount NUMERIC(10, 2) NULL,
+			currency CHAR(3) NULL,
+			show_id UUID NULL,
+			valid_from TIMESTAMP NULL,
+			valid_until TIMESTAMP NULL,
+			max_redemptions INT NULL,
+			redemptions INT NOT NULL DEFAULT 0
+		);
+
 		CREATE TABLE IF NOT EXISTS events (
 			event_id UUID PRIMARY KEY,
 			published_at TIMESTAMP NOT NULL,
 			event_name VARCHAR(255) NOT NULL,
 			event_payload JSONB NOT NULL
 		);
+
+		ALTER TABLE shows ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP NULL;
+		ALTER TABLE shows ADD COLUMN IF NOT EXISTS max_tickets_per_customer INT NULL;
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS booking_id UUID NULL;
+		ALTER TABLE show_seats ADD COLUMN IF NOT EXISTS hold_id UUID NULL;
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP NULL;
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS released_tickets INT NOT NULL DEFAULT 0;
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP NULL;
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS price_category VARCHAR(64) NULL;
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS price_amount NUMERIC(10, 2) NULL;
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS price_currency CHAR(3) NULL;
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255) NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS bookings_idempotency_key_idx ON bookings (idempotency_key);
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS promo_code VARCHAR(64) NULL;
+		ALTER TABLE bookings ADD COLUMN IF NOT EXISTS original_price_amount NUMERIC(10, 2) NULL;
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS revision INT NOT NULL DEFAULT 0;
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP NULL;
+		ALTER TABLE read_model_show_availability ADD COLUMN IF NOT EXISTS checked_in INT NOT NULL DEFAULT 0;
+		ALTER TABLE seat_holds ADD COLUMN IF NOT EXISTS price_category VARCHAR(64) NULL;
+		ALTER TABLE tickets ADD COLUMN IF NOT EXISTS refunded_amount NUMERIC(10, 2) NOT NULL DEFAULT 0;
+
+		CREATE TABLE IF NOT EXISTS ticket_refunds (
+			idempotency_key VARCHAR(255) PRIMARY KEY,
+			ticket_id UUID NOT NULL,
+			amount NUMERIC(10, 2) NULL,
+			currency CHAR(3) NULL,
+			fully_refunded BOOLEAN NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS dead_letters (
+			id UUID PRIMARY KEY,
+			message_id VARCHAR(255) NOT NULL,
+			topic VARCHAR(255) NOT NULL,
+			handler VARCHAR(255) NOT NULL,
+			error TEXT NOT NULL,
+			correlation_id VARCHAR(255) NOT NULL,
+			payload TEXT NOT NULL,
+			metadata JSONB NOT NULL,
+			dead_lettered_at TIMESTAMP NOT NULL,
+			UNIQUE (message_id, handler)
+		);
 	`
 
 	if _, err := db.Exec(initScript); err != nil {
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// show_seat_counters keeps the number of booked and held seats per show, so booking doesn't need to sum all bookings.
+// It must be updated in the same transaction as bookings and seat_holds.
+
+// reserveSeats takes seats from the show's free seats, it returns ErrNotEnoughSeats when there are not enough of them.
+func reserveSeats(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID, bookedSeats, heldSeats int) error {
+	if err := ensureShowNotCanceled(ctx, tx, showID); err != nil {
+		return err
+	}
+
+	return updateSeatCounter(ctx, tx, showID, bookedSeats, heldSeats)
+}
+
+// updateSeatCounter changes booked and held seats of the show by the deltas.
+// Negative deltas release seats and never fail, positive deltas fail with ErrNotEnoughSeats when the show is full.
+// It must be called before bookings and seat_holds are changed in the transaction,
+// as the counter of shows created before counters existed is initialized from them.
+func updateSeatCounter(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID, bookedDelta, heldDelta int) error {
+	if err := ensureSeatCounter(ctx, tx, showID); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE
+			show_seat_counters
+		SET
+			booked = booked + $2,
+			held = held + $3
+		WHERE
+			show_id = $1 AND ($2 + $3 <= 0 OR capacity - booked - held >= $2 + $3)
+	`, showID, bookedDelta, heldDelta)
+	if err != nil {
+		return fmt.Errorf("could not update seat counter: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotEnoughSeats
+	}
+
+	return nil
+}
+
+func insertSeatCounter(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID, capacity int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO
+			show_seat_counters (show_id, capacity, booked, held)
+		VALUES
+			($1, $2, 0, 0)
+	`, showID, capacity)
+	if err != nil {
+		return fmt.Errorf("could not add seat counter: %w", err)
+	}
+
+	return nil
+}
+
+func ensureSeatCounter(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID) error {
+	var exists bool
+	err := tx.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM show_seat_counters WHERE show_id = $1)`, showID)
+	if err != nil {
+		return fmt.Errorf("could not check seat counter: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	// seats of canceled bookings and refunded tickets are free again, seats of active holds are taken,
+	// even when the hold expired but wasn't released yet
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO
+			show_seat_counters (show_id, capacity, booked, held)
+		SELECT
+			s.show_id,
+			s.number_of_tickets,
+			(
+				SELECT
+					COALESCE(SUM(number_of_tickets - released_tickets), 0)
+				FROM
+					bookings
+				WHERE
+					show_id = s.show_id AND canceled_at IS NULL
+			),
+			(
+				SELECT
+					COALESCE(SUM(number_of_tickets), 0)
+				FROM
+					seat_holds
+				WHERE
+					show_id = s.show_id AND booking_id IS NULL AND released_at IS NULL
+			)
+		FROM
+			shows s
+		WHERE
+			s.show_id = $1
+		ON CONFLICT (show_id) DO NOTHING
+	`, showID)
+	if err != nil {
+		return fmt.Errorf("could not initialize seat counter: %w", err)
+	}
+
+	return nil
+}
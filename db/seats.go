@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tickets/entities"
+)
+
+// seatsOwner points to the show_seats column which references the seat's owner: a booking or a hold.
+type seatsOwner struct {
+	column string
+	id     uuid.UUID
+}
+
+func bookingSeats(bookingID uuid.UUID) seatsOwner {
+	return seatsOwner{column: "booking_id", id: bookingID}
+}
+
+func holdSeats(holdID uuid.UUID) seatsOwner {
+	return seatsOwner{column: "hold_id", id: holdID}
+}
+
+const freeSeatCondition = "booking_id IS NULL AND hold_id IS NULL"
+
+// allocateSeats assigns requested seats to the owner, or the best available seats when no seats are requested.
+// For shows without a seat map it's a no-op.
+func allocateSeats(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	showID uuid.UUID,
+	requestedSeats []entities.Seat,
+	numberOfSeats int,
+	owner seatsOwner,
+) ([]entities.Seat, error) {
+	var hasSeatMap bool
+	err := tx.GetContext(ctx, &hasSeatMap, `SELECT EXISTS (SELECT 1 FROM show_seats WHERE show_id = $1)`, showID)
+	if err != nil {
+		return nil, fmt.Errorf("could not check seat map: %w", err)
+	}
+
+	if !hasSeatMap {
+		if len(requestedSeats) > 0 {
+			return nil, ErrShowHasNoSeatMap
+		}
+		return nil, nil
+	}
+
+	if len(requestedSeats) == 0 {
+		return allocateBestAvailableSeats(ctx, tx, showID, numberOfSeats, owner)
+	}
+
+	for _, seat := range requestedSeats {
+		res, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			UPDATE
+				show_seats
+			SET
+				%s = $1
+			WHERE
+				show_id = $2 AND seat_section = $3 AND seat_row = $4 AND seat_number = $5 AND %s
+		`, owner.column, freeSeatCondition), owner.id, showID, seat.Section, seat.Row, seat.Number)
+		if err != nil {
+			return nil, fmt.Errorf("could not allocate seat: %w", err)
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("could get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrSeatNotAvailable, seat)
+		}
+	}
+
+	return findSeats(ctx, tx, owner)
+}
+
+func allocateBestAvailableSeats(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	showID uuid.UUID,
+	numberOfSeats int,
+	owner seatsOwner,
+) ([]entities.Seat, error) {
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE
+			show_seats
+		SET
+			%s = $1
+		WHERE
+			show_id = $2 AND (seat_section, seat_row, seat_number) IN (
+				SELECT
+					seat_section, seat_row, seat_number
+				FROM
+					show_seats
+				WHERE
+					show_id = $2 AND %s
+				ORDER BY
+					position
+				LIMIT $3
+			)
+	`, owner.column, freeSeatCondition), owner.id, showID, numberOfSeats)
+	if err != nil {
+		return nil, fmt.Errorf("could not allocate best available seats: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("could get rows affected: %w", err)
+	}
+
+	if rowsAffected < int64(numberOfSeats) {
+		return nil, ErrNotEnoughSeats
+	}
+
+	return findSeats(ctx, tx, owner)
+}
+
+func findSeats(ctx context.Context, tx *sqlx.Tx, owner seatsOwner) ([]entities.Seat, error) {
+	var seats []entities.Seat
+	err := tx.SelectContext(ctx, &seats, fmt.Sprintf(`
+		SELECT
+			seat_section, seat_row, seat_number
+		FROM
+			show_seats
+		WHERE
+			%s = $1
+		ORDER BY
+			position
+	`, owner.column), owner.id)
+	if err != nil {
+		return nil, fmt.Errorf("could not find seats by %s %s: %w", owner.column, owner.id, err)
+	}
+
+	return seats, nil
+}
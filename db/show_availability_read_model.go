@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tickets/entities"
+)
+
+// ShowAvailabilityReadModel keeps seats availability of shows for displaying.
+// It's eventually consistent, bookings are checked against show_seat_counters.
+type ShowAvailabilityReadModel struct {
+	db *sqlx.DB
+}
+
+func NewShowAvailabilityReadModel(db *sqlx.DB) ShowAvailabilityReadModel {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return ShowAvailabilityReadModel{db: db}
+}
+
+type availabilityChange struct {
+	booked    int
+	held      int
+	refunded  int
+	checkedIn int
+}
+
+func (r ShowAvailabilityReadModel) OnBookingMade(ctx context.Context, e *entities.BookingMade_v1) error {
+	change := availabilityChange{booked: e.NumberOfTickets}
+	if e.HoldID != nil {
+		// seats of the confirmed hold were already counted as held
+		change.held = -e.NumberOfTickets
+	}
+
+	return r.update(ctx, e.Header.ID, e.ShowID, change)
+}
+
+func (r ShowAvailabilityReadModel) OnSeatsHeld(ctx context.Context, e *entities.SeatsHeld_v1) error {
+	return r.update(ctx, e.Header.ID, e.ShowID, availabilityChange{held: e.NumberOfTickets})
+}
+
+func (r ShowAvailabilityReadModel) OnSeatHoldExpired(ctx context.Context, e *entities.SeatHoldExpired_v1) error {
+	return r.update(ctx, e.Header.ID, e.ShowID, availabilityChange{held: -e.NumberOfTickets})
+}
+
+func (r ShowAvailabilityReadModel) OnSeatsReleased(ctx context.Context, e *entities.SeatsReleased_v1) error {
+	return r.update(ctx, e.Header.ID, e.ShowID, availabilityChange{
+		booked:   -e.NumberOfTickets,
+		refunded: e.NumberOfTickets,
+	})
+}
+
+func (r ShowAvailabilityReadModel) OnBookingCanceled(ctx context.Context, e *entities.BookingCanceled_v1) error {
+	return r.update(ctx, e.Header.ID, e.ShowID, availabilityChange{
+		booked:   -e.ReleasedTickets,
+		refunded: e.ReleasedTickets,
+	})
+}
+
+func (r ShowAvailabilityReadModel) OnTicketCheckedIn(ctx context.Context, e *entities.TicketCheckedIn_v1) error {
+	return r.update(ctx, e.Header.ID, e.ShowID, availabilityChange{checkedIn: 1})
+}
+
+// update applies the change once per event, counters would drift on redelivered events otherwise.
+func (r ShowAvailabilityReadModel) update(ctx context.Context, eventID string, showID uuid.UUID, change availabilityChange) error {
+	return updateInTx(
+		ctx,
+		r.db,
+		sql.LevelRepeatableRead,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO
+					read_model_show_availability_processed_events (event_id)
+				VALUES
+					($1)
+				ON CONFLICT DO NOTHING
+			`, eventID)
+			if err != nil {
+				return fmt.Errorf("could not mark event %s as processed: %w", eventID, err)
+			}
+
+			rowsAffected, err := res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("could get rows affected: %w", err)
+			}
+
+			if rowsAffected == 0 {
+				return nil
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO
+					read_model_show_availability (show_id, capacity, booked, held, refunded, last_update)
+				SELECT
+					show_id, number_of_tickets, 0, 0, 0, now()
+				FROM
+					shows
+				WHERE
+					show_id = $1
+				ON CONFLICT (show_id) DO NOTHING
+			`, showID)
+			if err != nil {
+				return fmt.Errorf("could not create read model: %w", err)
+			}
+
+			res, err = tx.ExecContext(ctx, `
+				UPDATE
+					read_model_show_availability
+				SET
+					booked = booked + $2,
+					held = held + $3,
+					refunded = refunded + $4,
+					checked_in = checked_in + $5,
+					last_update = now()
+				WHERE
+					show_id = $1
+			`, showID, change.booked, change.held, change.refunded, change.checkedIn)
+			if err != nil {
+				return fmt.Errorf("could not update read model: %w", err)
+			}
+
+			rowsAffected, err = res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("could get rows affected: %w", err)
+			}
+
+			if rowsAffected == 0 {
+				return fmt.Errorf("show %s not exist yet", showID)
+			}
+
+			return nil
+		},
+	)
+}
+
+const selectShowAvailability = `
+	SELECT
+		s.show_id,
+		COALESCE(a.capacity, s.number_of_tickets) AS capacity,
+		COALESCE(a.booked, 0) AS booked,
+		COALESCE(a.held, 0) AS held,
+		COALESCE(a.refunded, 0) AS refunded,
+		COALESCE(a.checked_in, 0) AS checked_in,
+		GREATEST(COALESCE(a.capacity, s.number_of_tickets) - COALESCE(a.booked, 0) - COALESCE(a.held, 0), 0) AS available
+	FROM
+		shows s
+	LEFT JOIN
+		read_model_show_availability a ON a.show_id = s.show_id
+`
+
+func (r ShowAvailabilityReadModel) FindAll(ctx context.Context) ([]entities.ShowAvailability, error) {
+	var availability []entities.ShowAvailability
+	err := r.db.SelectContext(ctx, &availability, selectShowAvailability)
+	if err != nil {
+		return nil, fmt.Errorf("could not find shows availability: %w", err)
+	}
+
+	return availability, nil
+}
+
+func (r ShowAvailabilityReadModel) FindByShowID(ctx context.Context, showID uuid.UUID) (entities.ShowAvailability, error) {
+	var availability entities.ShowAvailability
+	err := r.db.GetContext(ctx, &availability, selectShowAvailability+` WHERE s.show_id = $1`, showID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.ShowAvailability{}, ErrShowNotFound
+	} else if err != nil {
+		return entities.ShowAvailability{}, fmt.Errorf("could not find availability of show %s: %w", showID, err)
+	}
+
+	return availability, nil
+}
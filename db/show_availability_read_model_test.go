@@ -0,0 +1,73 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/db"
+	"tickets/entities"
+)
+
+func TestShowAvailabilityReadModel(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	shows := db.NewShowRepository(testDB)
+	availability := db.NewShowAvailabilityReadModel(testDB)
+
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:          showID,
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 10,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+	})
+	require.NoError(t, err)
+
+	bookingMade := &entities.BookingMade_v1{
+		Header:          entities.NewMessageHeader(),
+		NumberOfTickets: 3,
+		BookingID:       uuid.New(),
+		CustomerEmail:   "foo@bar.com",
+		ShowID:          showID,
+	}
+
+	// redelivered events are applied once
+	for i := 0; i < 2; i++ {
+		require.NoError(t, availability.OnBookingMade(ctx, bookingMade))
+	}
+
+	err = availability.OnSeatsHeld(ctx, &entities.SeatsHeld_v1{
+		Header:          entities.NewMessageHeader(),
+		HoldID:          uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 2,
+	})
+	require.NoError(t, err)
+
+	err = availability.OnSeatsReleased(ctx, &entities.SeatsReleased_v1{
+		Header:          entities.NewMessageHeader(),
+		ShowID:          showID,
+		BookingID:       bookingMade.BookingID,
+		NumberOfTickets: 1,
+	})
+	require.NoError(t, err)
+
+	showAvailability, err := availability.FindByShowID(ctx, showID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ShowAvailability{
+		ShowID:    showID,
+		Capacity:  10,
+		Booked:    2,
+		Held:      2,
+		Refunded:  1,
+		Available: 6,
+	}, showAvailability)
+}
@@ -2,12 +2,22 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/outbox"
+)
+
+var (
+	ErrShowNotFound        = errors.New("show not found")
+	ErrShowAlreadyCanceled = errors.New("show is already canceled")
 )
 
 type ShowRepository struct {
@@ -23,13 +33,67 @@ func NewShowRepository(db *sqlx.DB) ShowRepository {
 }
 
 func (s ShowRepository) AddShow(ctx context.Context, show entities.Show) error {
-	_, err := s.db.NamedExecContext(ctx, `
-        INSERT INTO
-            shows (show_id, dead_nation_id, number_of_tickets, start_time, title, venue)
-        VALUES (:show_id, :dead_nation_id, :number_of_tickets, :start_time, :title, :venue)
-        `, show)
+	return updateInTx(
+		ctx,
+		s.db,
+		sql.LevelReadCommitted,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			_, err := tx.NamedExecContext(ctx, `
+				INSERT INTO
+					shows (show_id, dead_nation_id, number_of_tickets, start_time, title, venue, max_tickets_per_customer)
+				VALUES (:show_id, :dead_nation_id, :number_of_tickets, :start_time, :title, :venue, :max_tickets_per_customer)
+				`, show)
+			if err != nil {
+				return fmt.Errorf("could not add show: %w", err)
+			}
+
+			if err := insertSeatCounter(ctx, tx, show.ShowID, show.NumberOfTickets); err != nil {
+				return err
+			}
+
+			for _, category := range show.PriceCategories {
+				_, err := tx.ExecContext(ctx, `
+					INSERT INTO
+						show_price_categories (show_id, category, price_amount, price_currency)
+					VALUES
+						($1, $2, $3, $4)
+				`, show.ShowID, category.Name, category.Price.Amount, category.Price.Currency)
+				if err != nil {
+					return fmt.Errorf("could not add price category %s: %w", category.Name, err)
+				}
+			}
+
+			if show.SeatMap == nil {
+				return nil
+			}
+
+			return insertShowSeats(ctx, tx, show.ShowID, show.SeatMap.Seats())
+		},
+	)
+}
+
+func insertShowSeats(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID, seats []entities.Seat) error {
+	sections := make([]string, 0, len(seats))
+	rows := make([]string, 0, len(seats))
+	numbers := make([]int64, 0, len(seats))
+
+	for _, seat := range seats {
+		sections = append(sections, seat.Section)
+		rows = append(rows, seat.Row)
+		numbers = append(numbers, int64(seat.Number))
+	}
+
+	// position keeps the seat map order, it's used to find the best available seats
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO
+			show_seats (show_id, seat_section, seat_row, seat_number, position)
+		SELECT
+			$1, s.seat_section, s.seat_row, s.seat_number, s.position
+		FROM
+			unnest($2::text[], $3::text[], $4::int[]) WITH ORDINALITY AS s(seat_section, seat_row, seat_number, position)
+	`, showID, pq.Array(sections), pq.Array(rows), pq.Array(numbers))
 	if err != nil {
-		return fmt.Errorf("could not add show: %w", err)
+		return fmt.Errorf("could not add show seats: %w", err)
 	}
 
 	return nil
@@ -42,6 +106,21 @@ func (s ShowRepository) FindAll(ctx context.Context) ([]entities.Show, error) {
 		return nil, fmt.Errorf("could not find shows: %w", err)
 	}
 
+	seatMaps, err := s.findSeatMaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	priceCategories, err := s.findPriceCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range shows {
+		shows[i].SeatMap = seatMaps[shows[i].ShowID]
+		shows[i].PriceCategories = priceCategories[shows[i].ShowID]
+	}
+
 	return shows, nil
 }
 
@@ -52,5 +131,196 @@ func (s ShowRepository) ShowByID(ctx context.Context, showID uuid.UUID) (entitie
 		return entities.Show{}, err
 	}
 
+	seatMaps, err := s.findSeatMaps(ctx, showID)
+	if err != nil {
+		return entities.Show{}, err
+	}
+	show.SeatMap = seatMaps[showID]
+
+	priceCategories, err := s.findPriceCategories(ctx, showID)
+	if err != nil {
+		return entities.Show{}, err
+	}
+	show.PriceCategories = priceCategories[showID]
+
 	return show, nil
 }
+
+// ShowByTicketID returns the show of a ticket booked in our system, ErrShowNotFound for other tickets.
+func (s ShowRepository) ShowByTicketID(ctx context.Context, ticketID string) (entities.Show, error) {
+	var showID uuid.UUID
+	err := s.db.GetContext(ctx, &showID, `
+		SELECT
+			b.show_id
+		FROM
+			tickets t
+		JOIN
+			bookings b ON b.booking_id = t.booking_id
+		WHERE
+			t.ticket_id = $1
+	`, ticketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Show{}, ErrShowNotFound
+	} else if err != nil {
+		return entities.Show{}, fmt.Errorf("could not get show of ticket %s: %w", ticketID, err)
+	}
+
+	return s.ShowByID(ctx, showID)
+}
+
+// findPriceCategories returns price categories by show ID, when no show IDs are passed categories of all shows are returned.
+func (s ShowRepository) findPriceCategories(ctx context.Context, showIDs ...uuid.UUID) (map[uuid.UUID][]entities.PriceCategory, error) {
+	var rows []struct {
+		ShowID uuid.UUID `db:"show_id"`
+		entities.PriceCategory
+	}
+
+	ids := make([]string, 0, len(showIDs))
+	for _, id := range showIDs {
+		ids = append(ids, id.String())
+	}
+
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT
+			show_id,
+			category,
+			price_amount AS "price.amount",
+			price_currency AS "price.currency"
+		FROM
+			show_price_categories
+		WHERE
+			cardinality($1::uuid[]) = 0 OR show_id = ANY($1::uuid[])
+		ORDER BY
+			show_id, price_amount DESC, category
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("could not find price categories: %w", err)
+	}
+
+	priceCategories := map[uuid.UUID][]entities.PriceCategory{}
+	for _, row := range rows {
+		priceCategories[row.ShowID] = append(priceCategories[row.ShowID], row.PriceCategory)
+	}
+
+	return priceCategories, nil
+}
+
+// findSeatMaps rebuilds seat maps from show_seats, when no show IDs are passed seat maps of all shows are returned.
+func (s ShowRepository) findSeatMaps(ctx context.Context, showIDs ...uuid.UUID) (map[uuid.UUID]*entities.SeatMap, error) {
+	var rows []struct {
+		ShowID  uuid.UUID `db:"show_id"`
+		Section string    `db:"seat_section"`
+		Row     string    `db:"seat_row"`
+		Seats   int       `db:"seats"`
+	}
+
+	ids := make([]string, 0, len(showIDs))
+	for _, id := range showIDs {
+		ids = append(ids, id.String())
+	}
+
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT
+			show_id, seat_section, seat_row, COUNT(*) AS seats
+		FROM
+			show_seats
+		WHERE
+			cardinality($1::uuid[]) = 0 OR show_id = ANY($1::uuid[])
+		GROUP BY
+			show_id, seat_section, seat_row
+		ORDER BY
+			show_id, MIN(position)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("could not find seat maps: %w", err)
+	}
+
+	seatMaps := map[uuid.UUID]*entities.SeatMap{}
+	for _, row := range rows {
+		seatMap, ok := seatMaps[row.ShowID]
+		if !ok {
+			seatMap = &entities.SeatMap{}
+			seatMaps[row.ShowID] = seatMap
+		}
+
+		sections := seatMap.Sections
+		if len(sections) == 0 || sections[len(sections)-1].Name != row.Section {
+			sections = append(sections, entities.SeatMapSection{Name: row.Section})
+		}
+
+		last := &sections[len(sections)-1]
+		last.Rows = append(last.Rows, entities.SeatMapRow{Name: row.Row, Seats: row.Seats})
+		seatMap.Sections = sections
+	}
+
+	return seatMaps, nil
+}
+
+func (s ShowRepository) FindSeats(ctx context.Context, showID uuid.UUID) ([]entities.ShowSeat, error) {
+	var seats []entities.ShowSeat
+	err := s.db.SelectContext(ctx, &seats, `
+		SELECT
+			seat_section, seat_row, seat_number, booking_id IS NULL AND hold_id IS NULL AS available
+		FROM
+			show_seats
+		WHERE
+			show_id = $1
+		ORDER BY
+			position
+	`, showID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find seats of show %s: %w", showID, err)
+	}
+
+	return seats, nil
+}
+
+func (s ShowRepository) CancelShow(ctx context.Context, showID uuid.UUID) error {
+	return updateInTx(
+		ctx,
+		s.db,
+		sql.LevelRepeatableRead,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var canceled bool
+			err := tx.GetContext(ctx, &canceled, `
+				SELECT
+					canceled_at IS NOT NULL
+				FROM
+					shows
+				WHERE
+					show_id = $1
+				FOR UPDATE
+			`, showID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrShowNotFound
+			} else if err != nil {
+				return fmt.Errorf("could not get show: %w", err)
+			}
+
+			if canceled {
+				return ErrShowAlreadyCanceled
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE shows SET canceled_at = now() WHERE show_id = $1`, showID)
+			if err != nil {
+				return fmt.Errorf("could not cancel show: %w", err)
+			}
+
+			return publishShowCanceledEvent(ctx, tx, showID)
+		},
+	)
+}
+
+func publishShowCanceledEvent(ctx context.Context, tx *sqlx.Tx, showID uuid.UUID) error {
+	publisher, err := outbox.NewPublisherForDB(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("could not create event bus: %w", err)
+	}
+
+	bus := event.NewEventBus(publisher)
+
+	return bus.Publish(ctx, &entities.ShowCanceled_v1{
+		Header: entities.NewMessageHeader(),
+		ShowID: showID,
+	})
+}
@@ -2,12 +2,21 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/outbox"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+var ErrTicketNotFound = errors.New("ticket not found")
+
 type TicketRepository struct {
 	db *sqlx.DB
 }
@@ -25,9 +34,9 @@ func (t TicketRepository) Add(ctx context.Context, ticket entities.Ticket) error
 		ctx,
 		`
 		INSERT INTO
-    		tickets (ticket_id, price_amount, price_currency, customer_email)
+    		tickets (ticket_id, price_amount, price_currency, customer_email, booking_id)
 		VALUES
-		    (:ticket_id, :price.amount, :price.currency, :customer_email)
+		    (:ticket_id, :price.amount, :price.currency, :customer_email, CAST(NULLIF(:booking_id, '') AS UUID))
 		ON CONFLICT DO NOTHING`,
 		ticket,
 	)
@@ -67,7 +76,9 @@ func (t TicketRepository) FindAll(ctx context.Context) ([]entities.Ticket, error
                 ticket_id,
                 price_amount as "price.amount",
                 price_currency as "price.currency",
-                customer_email
+                customer_email,
+                COALESCE(booking_id::text, '') as booking_id,
+                revision
             FROM
                 tickets
             WHERE
@@ -80,3 +91,348 @@ func (t TicketRepository) FindAll(ctx context.Context) ([]entities.Ticket, error
 
 	return returnTickets, nil
 }
+
+func (t TicketRepository) TicketByID(ctx context.Context, ticketID string) (entities.Ticket, error) {
+	var ticket entities.Ticket
+
+	err := t.db.GetContext(
+		ctx,
+		&ticket, `
+            SELECT
+                ticket_id,
+                price_amount as "price.amount",
+                price_currency as "price.currency",
+                customer_email,
+                COALESCE(booking_id::text, '') as booking_id,
+                revision
+            FROM
+                tickets
+            WHERE
+                ticket_id = $1 AND deleted_at IS NULL
+        `,
+		ticketID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Ticket{}, ErrTicketNotFound
+	} else if err != nil {
+		return entities.Ticket{}, fmt.Errorf("could not get ticket %s: %w", ticketID, err)
+	}
+
+	return ticket, nil
+}
+
+func (t TicketRepository) FindAllByShowID(ctx context.Context, showID uuid.UUID) ([]entities.Ticket, error) {
+	var returnTickets []entities.Ticket
+
+	err := t.db.SelectContext(
+		ctx,
+		&returnTickets, `
+            SELECT
+                t.ticket_id,
+                t.price_amount as "price.amount",
+                t.price_currency as "price.currency",
+                t.customer_email,
+                t.booking_id::text as booking_id,
+                t.revision
+            FROM
+                tickets t
+            JOIN
+                bookings b ON b.booking_id = t.booking_id
+            WHERE
+                b.show_id = $1 AND t.deleted_at IS NULL
+        `,
+		showID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not find tickets for show %s: %w", showID, err)
+	}
+
+	return returnTickets, nil
+}
+
+func (t TicketRepository) FindAllByBookingID(ctx context.Context, bookingID uuid.UUID) ([]entities.Ticket, error) {
+	var returnTickets []entities.Ticket
+
+	err := t.db.SelectContext(
+		ctx,
+		&returnTickets, `
+            SELECT
+                ticket_id,
+                price_amount as "price.amount",
+                price_currency as "price.currency",
+                customer_email,
+                booking_id::text as booking_id,
+                revision
+            FROM
+                tickets
+            WHERE
+                booking_id = $1 AND deleted_at IS NULL
+        `,
+		bookingID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not find tickets for booking %s: %w", bookingID, err)
+	}
+
+	return returnTickets, nil
+}
+
+// TransferTicket changes the holder of the ticket and publishes TicketTransferred_v1 with the new revision of the ticket.
+// Transferring the ticket to its current holder is a no-op, so redelivered commands don't bump the revision,
+// and so is transferring a ticket which was refunded or removed after the transfer was requested.
+func (t TicketRepository) TransferTicket(ctx context.Context, ticketID string, newCustomerEmail string) error {
+	return updateInTx(
+		ctx,
+		t.db,
+		sql.LevelRepeatableRead,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var ticket struct {
+				entities.Ticket
+				IsRefunded bool `db:"is_refunded"`
+			}
+			err := tx.GetContext(ctx, &ticket, `
+				SELECT
+					ticket_id,
+					price_amount as "price.amount",
+					price_currency as "price.currency",
+					customer_email,
+					COALESCE(booking_id::text, '') as booking_id,
+					revision,
+					refunded_at IS NOT NULL AS is_refunded
+				FROM
+					tickets
+				WHERE
+					ticket_id = $1 AND deleted_at IS NULL
+				FOR UPDATE
+			`, ticketID)
+			if errors.Is(err, sql.ErrNoRows) {
+				slog.Warn("ticket to transfer not found", "ticket_id", ticketID)
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("could not get ticket %s: %w", ticketID, err)
+			}
+
+			if ticket.IsRefunded {
+				slog.Warn("refunded ticket can't be transferred", "ticket_id", ticketID)
+				return nil
+			}
+			if ticket.CustomerEmail == newCustomerEmail {
+				return nil
+			}
+
+			revision := ticket.Revision + 1
+
+			_, err = tx.ExecContext(
+				ctx,
+				`UPDATE tickets SET customer_email = $1, revision = $2 WHERE ticket_id = $3`,
+				newCustomerEmail, revision, ticketID,
+			)
+			if err != nil {
+				return fmt.Errorf("could not transfer ticket %s: %w", ticketID, err)
+			}
+
+			publisher, err := outbox.NewPublisherForDB(ctx, tx)
+			if err != nil {
+				return fmt.Errorf("could not create event bus: %w", err)
+			}
+
+			bus := event.NewEventBus(publisher)
+
+			return bus.Publish(ctx, &entities.TicketTransferred_v1{
+				Header:                entities.NewMessageHeaderWithIdempotencyKey(fmt.Sprintf("ticket-transferred-%s-%d", ticketID, revision)),
+				TicketID:              ticketID,
+				BookingID:             ticket.BookingID,
+				PreviousCustomerEmail: ticket.CustomerEmail,
+				CustomerEmail:         newCustomerEmail,
+				Price:                 ticket.Price,
+				Revision:              revision,
+			})
+		},
+	)
+}
+
+// AddRefund adds the refund to the refunded total of the ticket, a nil amount refunds the rest of the price.
+// A non-nil maxRefundedTotal is the part of the price the refund policy allows to refund.
+// The ticket is locked, so concurrent partial refunds can't exceed the price or the policy together.
+// A refund with the same idempotency key is returned as it was added, without adding it again.
+func (t TicketRepository) AddRefund(
+	ctx context.Context,
+	ticketID string,
+	idempotencyKey string,
+	amount *entities.Money,
+	maxRefundedTotal *entities.Money,
+) (entities.TicketRefund, error) {
+	var refund entities.TicketRefund
+
+	err := updateInTx(
+		ctx,
+		t.db,
+		sql.LevelRepeatableRead,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			var added struct {
+				Amount        *entities.Decimal `db:"amount"`
+				Currency      *string           `db:"currency"`
+				FullyRefunded bool              `db:"fully_refunded"`
+			}
+			err := tx.GetContext(ctx, &added, `
+				SELECT
+					amount, currency, fully_refunded
+				FROM
+					ticket_refunds
+				WHERE
+					idempotency_key = $1
+			`, idempotencyKey)
+			if err == nil {
+				refund = entities.TicketRefund{FullyRefunded: added.FullyRefunded}
+				if added.Amount != nil && added.Currency != nil {
+					refund.Amount = &entities.Money{Amount: *added.Amount, Currency: *added.Currency}
+				}
+				return nil
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("could not get refund %s: %w", idempotencyKey, err)
+			}
+
+			var ticket struct {
+				Price          entities.Money   `db:"price"`
+				RefundedAmount entities.Decimal `db:"refunded_amount"`
+			}
+			err = tx.GetContext(ctx, &ticket, `
+				SELECT
+					price_amount as "price.amount",
+					price_currency as "price.currency",
+					refunded_amount
+				FROM
+					tickets
+				WHERE
+					ticket_id = $1
+				FOR UPDATE
+			`, ticketID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTicketNotFound
+			} else if err != nil {
+				return fmt.Errorf("could not get ticket %s: %w", ticketID, err)
+			}
+
+			refunded := entities.Money{Amount: ticket.RefundedAmount, Currency: ticket.Price.Currency}
+			refund, err = entities.NewTicketRefund(ticket.Price, refunded, amount, maxRefundedTotal)
+			if err != nil {
+				return err
+			}
+
+			refundAmount := ticket.Price
+			if refund.Amount != nil {
+				refundAmount = *refund.Amount
+			}
+
+			_, err = tx.ExecContext(
+				ctx,
+				`UPDATE tickets SET refunded_amount = refunded_amount + $1 WHERE ticket_id = $2`,
+				refundAmount.Amount, ticketID,
+			)
+			if err != nil {
+				return fmt.Errorf("could not add refund of ticket %s: %w", ticketID, err)
+			}
+
+			// the amount is NULL when the full price was refunded at once
+			var (
+				partialAmount   *entities.Decimal
+				partialCurrency *string
+			)
+			if refund.Amount != nil {
+				partialAmount, partialCurrency = &refund.Amount.Amount, &refund.Amount.Currency
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO
+					ticket_refunds (idempotency_key, ticket_id, amount, currency, fully_refunded)
+				VALUES
+					($1, $2, $3, $4, $5)
+			`, idempotencyKey, ticketID, partialAmount, partialCurrency, refund.FullyRefunded)
+			if err != nil {
+				return fmt.Errorf("could not save refund %s: %w", idempotencyKey, err)
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		return entities.TicketRefund{}, err
+	}
+
+	return refund, nil
+}
+
+// CheckInTicket marks the ticket of the scanned token as used and publishes TicketCheckedIn_v1.
+// The ticket is locked, so a ticket scanned at two gates at once is checked in only once.
+// Tickets which can't be used are rejected with *entities.CheckInRejection.
+func (t TicketRepository) CheckInTicket(ctx context.Context, token entities.TicketToken, at time.Time) (entities.TicketCheckIn, error) {
+	var checkIn entities.TicketCheckIn
+
+	err := updateInTx(
+		ctx,
+		t.db,
+		sql.LevelRepeatableRead,
+		func(ctx context.Context, tx *sqlx.Tx) error {
+			err := tx.GetContext(ctx, &checkIn, `
+				SELECT
+					t.ticket_id,
+					t.booking_id,
+					b.show_id,
+					t.customer_email,
+					t.revision,
+					s.start_time AS show_start_time,
+					t.checked_in_at,
+					t.deleted_at IS NOT NULL OR b.canceled_at IS NOT NULL AS is_canceled,
+					t.refunded_at IS NOT NULL AS is_refunded,
+					s.canceled_at IS NOT NULL AS is_show_canceled
+				FROM
+					tickets t
+				JOIN
+					bookings b ON b.booking_id = t.booking_id
+				JOIN
+					shows s ON s.show_id = b.show_id
+				WHERE
+					t.ticket_id = $1
+				FOR UPDATE OF t
+			`, token.TicketID)
+			if errors.Is(err, sql.ErrNoRows) {
+				// tickets booked outside our system have no show to check in to
+				return entities.NewCheckInRejection(entities.CheckInRejectionTicketNotFound, "the ticket doesn't exist")
+			} else if err != nil {
+				return fmt.Errorf("could not get ticket %s: %w", token.TicketID, err)
+			}
+
+			if rejection := checkIn.Check(token, at); rejection != nil {
+				return rejection
+			}
+
+			checkedInAt := at.UTC()
+			checkIn.CheckedInAt = &checkedInAt
+
+			_, err = tx.ExecContext(ctx, `UPDATE tickets SET checked_in_at = $1 WHERE ticket_id = $2`, checkedInAt, token.TicketID)
+			if err != nil {
+				return fmt.Errorf("could not check in ticket %s: %w", token.TicketID, err)
+			}
+
+			publisher, err := outbox.NewPublisherForDB(ctx, tx)
+			if err != nil {
+				return fmt.Errorf("could not create event bus: %w", err)
+			}
+
+			bus := event.NewEventBus(publisher)
+
+			return bus.Publish(ctx, &entities.TicketCheckedIn_v1{
+				Header:      entities.NewMessageHeaderWithIdempotencyKey("ticket-checked-in-" + token.TicketID),
+				TicketID:    token.TicketID,
+				BookingID:   checkIn.BookingID,
+				ShowID:      checkIn.ShowID,
+				CheckedInAt: checkedInAt,
+			})
+		},
+	)
+	if err != nil {
+		return entities.TicketCheckIn{}, err
+	}
+
+	return checkIn, nil
+}
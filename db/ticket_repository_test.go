@@ -30,7 +30,7 @@ func TestTicketsRepository_Add_idempotency(t *testing.T) {
 	ticketToAdd := entities.Ticket{
 		TicketID: uuid.NewString(),
 		Price: entities.Money{
-			Amount:   "30.00",
+			Amount:   entities.MustParseDecimal("30.00"),
 			Currency: "EUR",
 		},
 		CustomerEmail: "foo@bar.com",
@@ -51,3 +51,101 @@ func TestTicketsRepository_Add_idempotency(t *testing.T) {
 		require.Len(t, foundTickets, 1)
 	}
 }
+
+func TestTicketsRepository_TransferTicket(t *testing.T) {
+	ctx := context.Background()
+	repo := db.NewTicketRepository(getDBTest())
+
+	ticket := entities.Ticket{
+		TicketID: uuid.NewString(),
+		Price: entities.Money{
+			Amount:   entities.MustParseDecimal("30.00"),
+			Currency: "EUR",
+		},
+		CustomerEmail: "from@example.com",
+	}
+	require.NoError(t, repo.Add(ctx, ticket))
+
+	// transferring to the same customer again doesn't create a new revision
+	for i := 0; i < 2; i++ {
+		require.NoError(t, repo.TransferTicket(ctx, ticket.TicketID, "to@example.com"))
+
+		transferred, err := repo.TicketByID(ctx, ticket.TicketID)
+		require.NoError(t, err)
+		require.Equal(t, "to@example.com", transferred.CustomerEmail)
+
+		var revision int
+		err = getDBTest().Get(&revision, `SELECT revision FROM tickets WHERE ticket_id = $1`, ticket.TicketID)
+		require.NoError(t, err)
+		require.Equal(t, 1, revision)
+	}
+}
+
+func TestTicketsRepository_AddRefund(t *testing.T) {
+	ctx := context.Background()
+	repo := db.NewTicketRepository(getDBTest())
+
+	ticket := entities.Ticket{
+		TicketID:      uuid.NewString(),
+		Price:         entities.MustNewMoney("30.00", "EUR"),
+		CustomerEmail: "foo@bar.com",
+	}
+	require.NoError(t, repo.Add(ctx, ticket))
+
+	partial := entities.MustNewMoney("20.00", "EUR")
+
+	// retries with the same idempotency key are not added again
+	for i := 0; i < 2; i++ {
+		refund, err := repo.AddRefund(ctx, ticket.TicketID, "partial-1-"+ticket.TicketID, &partial, nil)
+		require.NoError(t, err)
+		require.NotNil(t, refund.Amount)
+		require.Equal(t, "20.00 EUR", refund.Amount.String())
+		require.False(t, refund.FullyRefunded)
+	}
+
+	_, err := repo.AddRefund(ctx, ticket.TicketID, "partial-2-"+ticket.TicketID, &partial, nil)
+	require.ErrorIs(t, err, entities.ErrRefundExceedsPrice, "refunds can't exceed the ticket price together")
+
+	// the full refund refunds what's left after the partial refund
+	refund, err := repo.AddRefund(ctx, ticket.TicketID, "refund-"+ticket.TicketID, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, refund.Amount)
+	require.Equal(t, "10.00 EUR", refund.Amount.String())
+	require.True(t, refund.FullyRefunded)
+
+	_, err = repo.AddRefund(ctx, ticket.TicketID, "partial-3-"+ticket.TicketID, &partial, nil)
+	require.ErrorIs(t, err, entities.ErrTicketAlreadyRefunded)
+
+	var refunded string
+	err = getDBTest().Get(&refunded, `SELECT refunded_amount FROM tickets WHERE ticket_id = $1`, ticket.TicketID)
+	require.NoError(t, err)
+	require.Equal(t, "30.00", refunded)
+}
+
+func TestTicketsRepository_AddRefund_max_refunded_total(t *testing.T) {
+	ctx := context.Background()
+	repo := db.NewTicketRepository(getDBTest())
+
+	ticket := entities.Ticket{
+		TicketID:      uuid.NewString(),
+		Price:         entities.MustNewMoney("30.00", "EUR"),
+		CustomerEmail: "foo@bar.com",
+	}
+	require.NoError(t, repo.Add(ctx, ticket))
+
+	partial := entities.MustNewMoney("15.00", "EUR")
+	maxRefundedTotal := entities.MustNewMoney("15.00", "EUR")
+
+	_, err := repo.AddRefund(ctx, ticket.TicketID, "partial-1-"+ticket.TicketID, &partial, &maxRefundedTotal)
+	require.NoError(t, err)
+
+	_, err = repo.AddRefund(ctx, ticket.TicketID, "partial-2-"+ticket.TicketID, &partial, &maxRefundedTotal)
+	require.ErrorIs(t, err, entities.ErrRefundExceedsPolicy, "partial refunds can't exceed the policy together")
+
+	// the show was canceled, the rest of the price is refunded
+	refund, err := repo.AddRefund(ctx, ticket.TicketID, "refund-"+ticket.TicketID, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, refund.Amount)
+	require.Equal(t, "15.00 EUR", refund.Amount.String())
+	require.True(t, refund.FullyRefunded)
+}
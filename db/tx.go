@@ -5,13 +5,97 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	txMaxAttempts       = 5
+	txRetryInitialDelay = 10 * time.Millisecond
+	txRetryMaxDelay     = 250 * time.Millisecond
+)
+
+var (
+	transactionRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "db",
+			Name:      "transaction_retries_total",
+			Help:      "The total number of transactions retried after a serialization failure or deadlock",
+		},
+		[]string{"isolation", "code"},
+	)
+
+	transactionRetriesExhaustedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "db",
+			Name:      "transaction_retries_exhausted_total",
+			Help:      "The total number of transactions which failed after all retries",
+		},
+		[]string{"isolation"},
+	)
 )
 
 type Updater func(ctx context.Context, tx *sqlx.Tx) error
 
-func updateInTx(ctx context.Context, db *sqlx.DB, isolation sql.IsolationLevel, fn Updater) (err error) {
+// updateInTx runs fn in a transaction. When Postgres aborts the transaction because of a conflict
+// with a concurrent transaction, fn is run again in a new transaction, so it must not have side effects outside it.
+func updateInTx(ctx context.Context, db *sqlx.DB, isolation sql.IsolationLevel, fn Updater) error {
+	run := func(ctx context.Context) error {
+		return runInTx(ctx, db, isolation, fn)
+	}
+
+	return retryTx(ctx, isolation, run, sleep)
+}
+
+// retryTx calls run until it succeeds, fails with an error which isn't retryable, or txMaxAttempts are used up.
+func retryTx(
+	ctx context.Context,
+	isolation sql.IsolationLevel,
+	run func(ctx context.Context) error,
+	sleep func(ctx context.Context, d time.Duration) error,
+) error {
+	delay := txRetryInitialDelay
+
+	for attempt := 1; ; attempt++ {
+		err := run(ctx)
+
+		code, retryable := retryableErrorCode(err)
+		if !retryable {
+			return err
+		}
+
+		if attempt == txMaxAttempts {
+			transactionRetriesExhaustedTotal.WithLabelValues(isolation.String()).Inc()
+			return err
+		}
+
+		transactionRetriesTotal.WithLabelValues(isolation.String(), code).Inc()
+
+		// full jitter spreads retries of transactions which conflicted with each other
+		if sleepErr := sleep(ctx, rand.N(delay)); sleepErr != nil {
+			return errors.Join(err, sleepErr)
+		}
+
+		delay = min(delay*2, txRetryMaxDelay)
+	}
+}
+
+// sleep waits for d, it returns the error of the context when it's done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func runInTx(ctx context.Context, db *sqlx.DB, isolation sql.IsolationLevel, fn Updater) (err error) {
 	tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: isolation})
 	if err != nil {
 		return fmt.Errorf("could not begin transaction: %w", err)
@@ -30,3 +114,19 @@ func updateInTx(ctx context.Context, db *sqlx.DB, isolation sql.IsolationLevel,
 
 	return fn(ctx, tx)
 }
+
+// retryableErrorCode returns the Postgres error code when the transaction can succeed if run again.
+func retryableErrorCode(err error) (string, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return "", false
+	}
+
+	switch pqErr.Code {
+	case "40001", // serialization_failure
+		"40P01": // deadlock_detected
+		return string(pqErr.Code), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTx(t *testing.T) {
+	serializationFailure := &pq.Error{Code: "40001"}
+	deadlock := &pq.Error{Code: "40P01"}
+
+	testCases := []struct {
+		Name             string
+		Errors           []error
+		ExpectedAttempts int
+		ExpectedErr      error
+	}{
+		{
+			Name:             "success",
+			Errors:           []error{nil},
+			ExpectedAttempts: 1,
+		},
+		{
+			Name:             "serialization_failure_is_retried",
+			Errors:           []error{serializationFailure, serializationFailure, nil},
+			ExpectedAttempts: 3,
+		},
+		{
+			Name:             "deadlock_is_retried",
+			Errors:           []error{deadlock, nil},
+			ExpectedAttempts: 2,
+		},
+		{
+			Name:             "wrapped_error_is_retried",
+			Errors:           []error{errors.Join(errors.New("could not commit"), serializationFailure), nil},
+			ExpectedAttempts: 2,
+		},
+		{
+			Name:             "unique_violation_is_not_retried",
+			Errors:           []error{&pq.Error{Code: "23505"}},
+			ExpectedAttempts: 1,
+			ExpectedErr:      &pq.Error{Code: "23505"},
+		},
+		{
+			Name:             "other_postgres_error_is_not_retried",
+			Errors:           []error{&pq.Error{Code: "42P01"}},
+			ExpectedAttempts: 1,
+			ExpectedErr:      &pq.Error{Code: "42P01"},
+		},
+		{
+			Name:             "other_error_is_not_retried",
+			Errors:           []error{sql.ErrConnDone},
+			ExpectedAttempts: 1,
+			ExpectedErr:      sql.ErrConnDone,
+		},
+		{
+			Name:             "attempts_are_limited",
+			Errors:           []error{deadlock, deadlock, deadlock, deadlock, deadlock, nil},
+			ExpectedAttempts: txMaxAttempts,
+			ExpectedErr:      deadlock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			attempts := 0
+			run := func(ctx context.Context) error {
+				err := tc.Errors[attempts]
+				attempts++
+				return err
+			}
+
+			var delays []time.Duration
+			sleep := func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			err := retryTx(context.Background(), sql.LevelSerializable, run, sleep)
+			if tc.ExpectedErr != nil {
+				assert.Equal(t, tc.ExpectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.ExpectedAttempts, attempts)
+			assert.Len(t, delays, max(attempts-1, 0), "there is a delay between attempts only")
+		})
+	}
+}
+
+func TestRetryTx_backoff_bounds(t *testing.T) {
+	for range 100 {
+		var delays []time.Duration
+		sleep := func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		}
+
+		run := func(ctx context.Context) error {
+			return &pq.Error{Code: "40001"}
+		}
+
+		err := retryTx(context.Background(), sql.LevelSerializable, run, sleep)
+		require.Error(t, err)
+		require.Len(t, delays, txMaxAttempts-1)
+
+		bound := txRetryInitialDelay
+		for i, d := range delays {
+			assert.GreaterOrEqual(t, d, time.Duration(0), "delay %d", i)
+			assert.Less(t, d, bound, "delay %d", i)
+			assert.LessOrEqual(t, d, txRetryMaxDelay, "delay %d", i)
+
+			bound = min(bound*2, txRetryMaxDelay)
+		}
+	}
+}
+
+func TestRetryTx_context_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	run := func(ctx context.Context) error {
+		attempts++
+		cancel()
+		return &pq.Error{Code: "40001"}
+	}
+
+	err := retryTx(ctx, sql.LevelSerializable, run, sleep)
+	require.ErrorIs(t, err, context.Canceled)
+
+	var pqErr *pq.Error
+	require.ErrorAs(t, err, &pqErr, "the error of the transaction is kept")
+	assert.Equal(t, 1, attempts, "canceled context stops retries")
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"tickets/entities"
+	"tickets/message/event"
+	"tickets/message/outbox"
+)
+
+type WaitlistRepository struct {
+	db *sqlx.DB
+}
+
+func NewWaitlistRepository(db *sqlx.DB) WaitlistRepository {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	return WaitlistRepository{db: db}
+}
+
+func (w WaitlistRepository) AddEntry(ctx context.Context, entry entities.WaitlistEntry) error {
+	updateFn := func(ctx context.Context, tx *sqlx.Tx) error {
+		var canceled bool
+		err := tx.GetContext(ctx, &canceled, `SELECT canceled_at IS NOT NULL FROM shows WHERE show_id = $1`, entry.ShowID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrShowNotFound
+		} else if err != nil {
+			return fmt.Errorf("could not get show: %w", err)
+		}
+
+		if canceled {
+			return ErrShowCanceled
+		}
+
+		if err := ensurePurchaseLimit(ctx, tx, entry.ShowID, entry.CustomerEmail, entry.NumberOfTickets); err != nil {
+			return err
+		}
+
+		_, err = tx.NamedExecContext(ctx, `
+			INSERT INTO
+				waitlist_entries (entry_id, show_id, number_of_tickets, customer_email, created_at)
+			VALUES
+				(:entry_id, :show_id, :number_of_tickets, :customer_email, :created_at)
+		`, entry)
+		if err != nil {
+			return fmt.Errorf("could not add waitlist entry: %w", err)
+		}
+
+		return nil
+	}
+
+	return updateInTx(ctx, w.db, sql.LevelRepeatableRead, updateFn)
+}
+
+// OfferFreeSeats holds free seats of the show for the oldest waitlist entries, in order.
+// It stops at the first entry which doesn't fit into the free seats, so later entries can't jump the queue.
+// Entries of customers who would exceed the show's limit of tickets per customer are skipped.
+func (w WaitlistRepository) OfferFreeSeats(ctx context.Context, showID uuid.UUID, offerExpiresAt time.Time) error {
+	updateFn := func(ctx context.Context, tx *sqlx.Tx) error {
+		var entries []entities.WaitlistEntry
+		err := tx.SelectContext(ctx, &entries, `
+			SELECT
+				entry_id, show_id, number_of_tickets, customer_email, created_at
+			FROM
+				waitlist_entries
+			WHERE
+				show_id = $1 AND offered_at IS NULL
+			ORDER BY
+				created_at, entry_id
+			FOR UPDATE
+		`, showID)
+		if err != nil {
+			return fmt.Errorf("could not find waitlist entries: %w", err)
+		}
+
+		for _, entry := range entries {
+			err := offerSeats(ctx, tx, entry, offerExpiresAt)
+			if errors.Is(err, ErrPurchaseLimitExceeded) {
+				slog.Info("skipping waitlist entry over the purchase limit", "entry_id", entry.EntryID, "error", err)
+				continue
+			} else if errors.Is(err, ErrNotEnoughSeats) || errors.Is(err, ErrShowCanceled) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return updateInTx(ctx, w.db, sql.LevelSerializable, updateFn)
+}
+
+func offerSeats(ctx context.Context, tx *sqlx.Tx, entry entities.WaitlistEntry, expiresAt time.Time) error {
+	// the entry was checked when it was added, but the show's limit can be lowered since then,
+	// the entry is still pending, so its own tickets are already counted
+	if err := ensurePurchaseLimit(ctx, tx, entry.ShowID, entry.CustomerEmail, 0); err != nil {
+		return err
+	}
+
+	if err := reserveSeats(ctx, tx, entry.ShowID, 0, entry.NumberOfTickets); err != nil {
+		return err
+	}
+
+	hold := entities.SeatHold{
+		HoldID:          uuid.New(),
+		ShowID:          entry.ShowID,
+		NumberOfTickets: entry.NumberOfTickets,
+		CustomerEmail:   entry.CustomerEmail,
+		ExpiresAt:       expiresAt,
+	}
+
+	seats, err := insertHold(ctx, tx, hold)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE
+			waitlist_entries
+		SET
+			hold_id = $1, offered_at = $2
+		WHERE
+			entry_id = $3
+	`, hold.HoldID, time.Now().UTC(), entry.EntryID)
+	if err != nil {
+		return fmt.Errorf("could not mark waitlist entry %s as offered: %w", entry.EntryID, err)
+	}
+
+	publisher, err := outbox.NewPublisherForDB(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("could not create event bus: %w", err)
+	}
+
+	bus := event.NewEventBus(publisher)
+
+	return bus.Publish(ctx, &entities.WaitlistOfferMade_v1{
+		Header:          entities.NewMessageHeader(),
+		EntryID:         entry.EntryID,
+		HoldID:          hold.HoldID,
+		ShowID:          entry.ShowID,
+		NumberOfTickets: entry.NumberOfTickets,
+		CustomerEmail:   entry.CustomerEmail,
+		ExpiresAt:       hold.ExpiresAt,
+		Seats:           seats,
+	})
+}
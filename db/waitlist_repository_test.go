@@ -0,0 +1,176 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"tickets/db"
+	"tickets/entities"
+)
+
+func TestWaitlistRepository_refunded_seat_is_offered_to_waitlist(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	shows := db.NewShowRepository(testDB)
+	bookings := db.NewBookingRepository(testDB)
+	tickets := db.NewTicketRepository(testDB)
+	waitlist := db.NewWaitlistRepository(testDB)
+
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:          showID,
+		DeadNationID:    uuid.New(),
+		NumberOfTickets: 1,
+		StartTime:       time.Now().Add(time.Hour),
+		Title:           "Example title",
+		Venue:           "Example venue",
+	})
+	require.NoError(t, err)
+
+	booking, err := bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+	})
+	require.NoError(t, err)
+
+	ticketID := uuid.NewString()
+	err = tickets.Add(ctx, entities.Ticket{
+		TicketID:      ticketID,
+		Price:         entities.MustNewMoney("30.00", "EUR"),
+		CustomerEmail: "foo@bar.com",
+		BookingID:     booking.BookingID.String(),
+	})
+	require.NoError(t, err)
+
+	err = waitlist.AddEntry(ctx, entities.WaitlistEntry{
+		EntryID:         uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "waiting@bar.com",
+		CreatedAt:       time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	// releasing twice should free only one seat
+	for i := 0; i < 2; i++ {
+		err = bookings.ReleaseTicketSeat(ctx, ticketID)
+		require.NoError(t, err)
+	}
+
+	err = waitlist.OfferFreeSeats(ctx, showID, time.Now().UTC().Add(time.Minute))
+	require.NoError(t, err)
+
+	// the released seat is held for the waitlist entry
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+	})
+	require.ErrorIs(t, err, db.ErrNotEnoughSeats)
+}
+
+func TestWaitlistRepository_purchase_limit(t *testing.T) {
+	ctx := context.Background()
+
+	testDB := getDBTest()
+	shows := db.NewShowRepository(testDB)
+	bookings := db.NewBookingRepository(testDB)
+	tickets := db.NewTicketRepository(testDB)
+	waitlist := db.NewWaitlistRepository(testDB)
+
+	maxTicketsPerCustomer := 2
+	showID := uuid.New()
+	err := shows.AddShow(ctx, entities.Show{
+		ShowID:                showID,
+		DeadNationID:          uuid.New(),
+		NumberOfTickets:       2,
+		StartTime:             time.Now().Add(time.Hour),
+		Title:                 "Example title",
+		Venue:                 "Example venue",
+		MaxTicketsPerCustomer: &maxTicketsPerCustomer,
+	})
+	require.NoError(t, err)
+
+	_, err = bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "scalper@bar.com",
+	})
+	require.NoError(t, err)
+
+	booking, err := bookings.AddBooking(ctx, entities.Booking{
+		BookingID:       uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "foo@bar.com",
+	})
+	require.NoError(t, err)
+
+	ticketID := uuid.NewString()
+	err = tickets.Add(ctx, entities.Ticket{
+		TicketID:      ticketID,
+		Price:         entities.MustNewMoney("30.00", "EUR"),
+		CustomerEmail: "foo@bar.com",
+		BookingID:     booking.BookingID.String(),
+	})
+	require.NoError(t, err)
+
+	scalperEntryID := uuid.New()
+	err = waitlist.AddEntry(ctx, entities.WaitlistEntry{
+		EntryID:         scalperEntryID,
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "scalper@bar.com",
+		CreatedAt:       time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	// waitlist entries count to the limit like bookings
+	err = waitlist.AddEntry(ctx, entities.WaitlistEntry{
+		EntryID:         uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "Scalper@bar.com",
+		CreatedAt:       time.Now().UTC(),
+	})
+	require.ErrorIs(t, err, db.ErrPurchaseLimitExceeded)
+
+	fanEntryID := uuid.New()
+	err = waitlist.AddEntry(ctx, entities.WaitlistEntry{
+		EntryID:         fanEntryID,
+		ShowID:          showID,
+		NumberOfTickets: 1,
+		CustomerEmail:   "fan@bar.com",
+		CreatedAt:       time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	// the limit is lowered after the scalper joined the waitlist
+	_, err = testDB.ExecContext(ctx, `UPDATE shows SET max_tickets_per_customer = 1 WHERE show_id = $1`, showID)
+	require.NoError(t, err)
+
+	err = bookings.ReleaseTicketSeat(ctx, ticketID)
+	require.NoError(t, err)
+
+	err = waitlist.OfferFreeSeats(ctx, showID, time.Now().UTC().Add(time.Minute))
+	require.NoError(t, err)
+
+	offered := func(entryID uuid.UUID) bool {
+		var offered bool
+		err := testDB.GetContext(ctx, &offered, `SELECT offered_at IS NOT NULL FROM waitlist_entries WHERE entry_id = $1`, entryID)
+		require.NoError(t, err)
+		return offered
+	}
+
+	require.False(t, offered(scalperEntryID), "entry over the purchase limit should be skipped")
+	require.True(t, offered(fanEntryID), "the next entry should get the seat")
+}
@@ -7,6 +7,20 @@ type Booking struct {
 	ShowID          uuid.UUID `json:"show_id" db:"show_id"`
 	NumberOfTickets int       `json:"number_of_tickets" db:"number_of_tickets"`
 	CustomerEmail   string    `json:"customer_email" db:"customer_email"`
+
+	// IdempotencyKey is optional, booking with the same key returns the already made booking.
+	IdempotencyKey string `json:"-" db:"idempotency_key"`
+
+	// PriceCategory is required for shows with price categories, TicketPrice is the category's price at the booking time.
+	PriceCategory string `json:"price_category,omitempty" db:"price_category"`
+	TicketPrice   *Money `json:"ticket_price,omitempty" db:"-"`
+
+	// PromoCode is optional, Discount is set when it was applied and TicketPrice is then the discounted price.
+	PromoCode string           `json:"promo_code,omitempty" db:"promo_code"`
+	Discount  *BookingDiscount `json:"discount,omitempty" db:"-"`
+
+	// Seats requested by the customer. When empty, the best available seats are allocated for shows with a seat map.
+	Seats []Seat `json:"seats,omitempty" db:"-"`
 }
 type DeadNationBooking struct {
 	BookingID         uuid.UUID
@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// checkInWindow is how long before and after the start of the show its tickets can be checked in.
+// It's a window around the start time instead of a calendar day, the venue's time zone isn't known.
+const checkInWindow = 12 * time.Hour
+
+type CheckInRejectionCode string
+
+const (
+	CheckInRejectionInvalidToken      CheckInRejectionCode = "invalid_token"
+	CheckInRejectionTicketNotFound    CheckInRejectionCode = "ticket_not_found"
+	CheckInRejectionTicketTransferred CheckInRejectionCode = "ticket_transferred"
+	CheckInRejectionTicketCanceled    CheckInRejectionCode = "ticket_canceled"
+	CheckInRejectionTicketRefunded    CheckInRejectionCode = "ticket_refunded"
+	CheckInRejectionShowCanceled      CheckInRejectionCode = "show_canceled"
+	CheckInRejectionShowNotToday      CheckInRejectionCode = "show_not_today"
+	CheckInRejectionAlreadyCheckedIn  CheckInRejectionCode = "already_checked_in"
+)
+
+// CheckInRejection tells door staff why the ticket can't be used, it's returned to the scanner as is.
+type CheckInRejection struct {
+	Code    CheckInRejectionCode `json:"code"`
+	Message string               `json:"message"`
+
+	// CheckedInAt is set for tickets which were already scanned.
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+}
+
+func NewCheckInRejection(code CheckInRejectionCode, message string) *CheckInRejection {
+	return &CheckInRejection{Code: code, Message: message}
+}
+
+func (r *CheckInRejection) Error() string {
+	return "check-in rejected: " + r.Message
+}
+
+// TicketCheckIn is the state of a ticket that decides whether it can be used at the venue.
+type TicketCheckIn struct {
+	TicketID      string     `json:"ticket_id" db:"ticket_id"`
+	BookingID     uuid.UUID  `json:"booking_id" db:"booking_id"`
+	ShowID        uuid.UUID  `json:"show_id" db:"show_id"`
+	CustomerEmail string     `json:"customer_email" db:"customer_email"`
+	Revision      int        `json:"revision" db:"revision"`
+	ShowStartTime time.Time  `json:"show_start_time" db:"show_start_time"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty" db:"checked_in_at"`
+
+	Canceled     bool `json:"-" db:"is_canceled"`
+	Refunded     bool `json:"-" db:"is_refunded"`
+	ShowCanceled bool `json:"-" db:"is_show_canceled"`
+}
+
+// Check returns the reason why the ticket of the scanned token can't be checked in at the given time, or nil.
+// Shows are happening today when they start at most checkInWindow before or after the given time.
+func (c TicketCheckIn) Check(token TicketToken, at time.Time) *CheckInRejection {
+	if token.ShowID != c.ShowID.String() {
+		return NewCheckInRejection(CheckInRejectionInvalidToken, "the ticket is for another show")
+	}
+	if token.Revision != c.Revision {
+		return NewCheckInRejection(CheckInRejectionTicketTransferred, "the ticket was transferred to another customer")
+	}
+	if c.ShowCanceled {
+		return NewCheckInRejection(CheckInRejectionShowCanceled, "the show is canceled")
+	}
+	if c.Canceled {
+		return NewCheckInRejection(CheckInRejectionTicketCanceled, "the ticket is canceled")
+	}
+	if c.Refunded {
+		return NewCheckInRejection(CheckInRejectionTicketRefunded, "the ticket is refunded")
+	}
+
+	if at.Before(c.ShowStartTime.Add(-checkInWindow)) || at.After(c.ShowStartTime.Add(checkInWindow)) {
+		return NewCheckInRejection(CheckInRejectionShowNotToday, "the show isn't happening today")
+	}
+
+	if c.CheckedInAt != nil {
+		rejection := NewCheckInRejection(CheckInRejectionAlreadyCheckedIn, "the ticket was already scanned")
+		rejection.CheckedInAt = c.CheckedInAt
+		return rejection
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package entities_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"tickets/entities"
+)
+
+func TestTicketCheckIn_Check(t *testing.T) {
+	showID := uuid.New()
+	showStartTime := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+	checkedInAt := time.Date(2024, 6, 10, 19, 0, 0, 0, time.UTC)
+
+	token := entities.TicketToken{
+		TicketID: uuid.NewString(),
+		ShowID:   showID.String(),
+		Revision: 1,
+	}
+
+	ticket := entities.TicketCheckIn{
+		TicketID:      token.TicketID,
+		ShowID:        showID,
+		Revision:      1,
+		ShowStartTime: showStartTime,
+	}
+
+	testCases := []struct {
+		Name              string
+		Token             entities.TicketToken
+		Modify            func(c *entities.TicketCheckIn)
+		At                time.Time
+		ExpectedRejection entities.CheckInRejectionCode
+	}{
+		{
+			Name:  "before_show",
+			Token: token,
+			At:    time.Date(2024, 6, 10, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			Name:  "after_show_started",
+			Token: token,
+			At:    time.Date(2024, 6, 10, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:              "day_before",
+			Token:             token,
+			At:                time.Date(2024, 6, 9, 20, 0, 0, 0, time.UTC),
+			ExpectedRejection: entities.CheckInRejectionShowNotToday,
+		},
+		{
+			Name:              "day_after",
+			Token:             token,
+			At:                time.Date(2024, 6, 11, 9, 0, 0, 0, time.UTC),
+			ExpectedRejection: entities.CheckInRejectionShowNotToday,
+		},
+		{
+			// the show starts after midnight UTC, e.g. in the evening in America
+			Name:   "show_starts_on_next_utc_day",
+			Token:  token,
+			Modify: func(c *entities.TicketCheckIn) { c.ShowStartTime = time.Date(2024, 6, 11, 1, 0, 0, 0, time.UTC) },
+			At:     time.Date(2024, 6, 10, 23, 30, 0, 0, time.UTC),
+		},
+		{
+			Name: "other_show",
+			Token: entities.TicketToken{
+				TicketID: token.TicketID,
+				ShowID:   uuid.NewString(),
+				Revision: 1,
+			},
+			At:                checkedInAt,
+			ExpectedRejection: entities.CheckInRejectionInvalidToken,
+		},
+		{
+			Name: "printed_before_transfer",
+			Token: entities.TicketToken{
+				TicketID: token.TicketID,
+				ShowID:   token.ShowID,
+				Revision: 0,
+			},
+			At:                checkedInAt,
+			ExpectedRejection: entities.CheckInRejectionTicketTransferred,
+		},
+		{
+			Name:              "refunded",
+			Token:             token,
+			Modify:            func(c *entities.TicketCheckIn) { c.Refunded = true },
+			At:                checkedInAt,
+			ExpectedRejection: entities.CheckInRejectionTicketRefunded,
+		},
+		{
+			Name:              "canceled",
+			Token:             token,
+			Modify:            func(c *entities.TicketCheckIn) { c.Canceled = true },
+			At:                checkedInAt,
+			ExpectedRejection: entities.CheckInRejectionTicketCanceled,
+		},
+		{
+			Name:              "show_canceled",
+			Token:             token,
+			Modify:            func(c *entities.TicketCheckIn) { c.ShowCanceled = true },
+			At:                checkedInAt,
+			ExpectedRejection: entities.CheckInRejectionShowCanceled,
+		},
+		{
+			Name:              "already_checked_in",
+			Token:             token,
+			Modify:            func(c *entities.TicketCheckIn) { c.CheckedInAt = &checkedInAt },
+			At:                checkedInAt.Add(time.Minute),
+			ExpectedRejection: entities.CheckInRejectionAlreadyCheckedIn,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			checkIn := ticket
+			if tc.Modify != nil {
+				tc.Modify(&checkIn)
+			}
+
+			rejection := checkIn.Check(tc.Token, tc.At)
+
+			if tc.ExpectedRejection == "" {
+				assert.Nil(t, rejection)
+				return
+			}
+			if assert.NotNil(t, rejection) {
+				assert.Equal(t, tc.ExpectedRejection, rejection.Code)
+			}
+		})
+	}
+}
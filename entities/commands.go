@@ -3,4 +3,18 @@ package entities
 type RefundTicket struct {
 	Header   MessageHeader `json:"header"`
 	TicketID string        `json:"ticket_id"`
+
+	// Amount is set for partial refunds, nil refunds the full ticket price.
+	Amount *Money `json:"amount,omitempty"`
+	// FullyRefunded is set when nothing of the price is left after the refund,
+	// with Amount set it's what was left after earlier partial refunds.
+	FullyRefunded bool `json:"fully_refunded,omitempty"`
+	// Reason is empty in commands sent before reasons were added, they are customer requested refunds.
+	Reason RefundReason `json:"reason,omitempty"`
+}
+
+type TransferTicket struct {
+	Header           MessageHeader `json:"header"`
+	TicketID         string        `json:"ticket_id"`
+	NewCustomerEmail string        `json:"new_customer_email"`
 }
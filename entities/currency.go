@@ -0,0 +1,43 @@
+package entities
+
+import "strings"
+
+// iso4217Currencies are active ISO 4217 currency codes.
+const iso4217Currencies = `
+AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN BWP BYN BZD
+CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL
+GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD
+KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO
+NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD
+SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV
+WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG
+`
+
+// currencyMinorUnitsExceptions lists currencies which don't have 2 digits after the decimal point.
+var currencyMinorUnitsExceptions = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+var currencies = func() map[string]struct{} {
+	codes := map[string]struct{}{}
+	for _, code := range strings.Fields(iso4217Currencies) {
+		codes[code] = struct{}{}
+	}
+	return codes
+}()
+
+func IsValidCurrency(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+// CurrencyMinorUnits returns the number of digits after the decimal point used by the currency.
+func CurrencyMinorUnits(code string) int {
+	if units, ok := currencyMinorUnitsExceptions[code]; ok {
+		return units
+	}
+	return 2
+}
@@ -0,0 +1,24 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter is a message its handler failed to process even after retries. It's kept until ops replay or discard it.
+type DeadLetter struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	MessageID string    `json:"message_id" db:"message_id"`
+
+	// Topic is the topic the message was consumed from, it's published there again on replay.
+	Topic   string `json:"topic" db:"topic"`
+	Handler string `json:"handler" db:"handler"`
+	Error   string `json:"error" db:"error"`
+
+	CorrelationID string            `json:"correlation_id" db:"correlation_id"`
+	Payload       string            `json:"payload" db:"payload"`
+	Metadata      map[string]string `json:"metadata" db:"-"`
+
+	DeadLetteredAt time.Time `json:"dead_lettered_at" db:"dead_lettered_at"`
+}
@@ -0,0 +1,296 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalScale is more than any currency needs, it leaves room for exchange rates.
+const maxDecimalScale = 9
+
+var (
+	ErrInvalidDecimal  = errors.New("invalid decimal")
+	ErrDecimalOverflow = errors.New("decimal overflow")
+)
+
+// Decimal is an exact decimal number: units * 10^-scale. It keeps the scale it was created with,
+// so "30.00" stays "30.00" after a round trip. The zero value is 0.
+type Decimal struct {
+	units int64
+	scale uint8
+}
+
+func ParseDecimal(s string) (Decimal, error) {
+	value := s
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(value, ".")
+	if integer == "" || (hasFraction && fraction == "") || len(fraction) > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	var units int64
+	for _, digit := range integer + fraction {
+		if digit < '0' || digit > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+		if units > (math.MaxInt64-int64(digit-'0'))/10 {
+			return Decimal{}, fmt.Errorf("%w: %q is out of range", ErrInvalidDecimal, s)
+		}
+		units = units*10 + int64(digit-'0')
+	}
+
+	if negative {
+		units = -units
+	}
+
+	return Decimal{units: units, scale: uint8(len(fraction))}, nil
+}
+
+// MustParseDecimal is ParseDecimal for constants, it panics on invalid input.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal{units: i}
+}
+
+func (d Decimal) String() string {
+	units := d.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(absUnits(units), 10)
+	if d.scale == 0 {
+		return sign + digits
+	}
+
+	scale := int(d.scale)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	return int(d.scale)
+}
+
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units, scale: d.scale}
+}
+
+// Add returns ErrDecimalOverflow when the result doesn't fit, like the other arithmetic methods.
+func (d Decimal) Add(other Decimal) (Decimal, error) {
+	scale := max(d.scale, other.scale)
+	sum := new(big.Int).Add(d.bigUnits(scale), other.bigUnits(scale))
+	return decimalFromBig(sum, scale)
+}
+
+func (d Decimal) Sub(other Decimal) (Decimal, error) {
+	scale := max(d.scale, other.scale)
+	difference := new(big.Int).Sub(d.bigUnits(scale), other.bigUnits(scale))
+	return decimalFromBig(difference, scale)
+}
+
+// Mul rounds the result to maxDecimalScale places, so multiplying by an exchange rate
+// doesn't overflow just because of the digits after the decimal point.
+func (d Decimal) Mul(other Decimal) (Decimal, error) {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(other.units))
+	return decimalFromBig(product, d.scale+other.scale)
+}
+
+// MulRound multiplies and rounds half away from zero to the given number of decimal places,
+// the product only has to fit once it's rounded.
+func (d Decimal) MulRound(other Decimal, places int) (Decimal, error) {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(other.units))
+	return decimalFromBig(roundBig(product, d.scale+other.scale, uint8(places)), uint8(places))
+}
+
+func (d Decimal) MulInt(i int64) (Decimal, error) {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(i))
+	return decimalFromBig(product, d.scale)
+}
+
+// Round rounds half away from zero to the given number of decimal places.
+// The result has exactly that many places, so it can be used for padding as well,
+// unless the padded amount doesn't fit, then it keeps its places.
+func (d Decimal) Round(places int) Decimal {
+	scale := uint8(places)
+	if scale >= d.scale {
+		padded, err := decimalFromBig(roundBig(big.NewInt(d.units), d.scale, scale), scale)
+		if err != nil {
+			return d
+		}
+		return padded
+	}
+
+	divisor := pow10(d.scale - scale)
+	quotient, remainder := d.units/divisor, d.units%divisor
+
+	if absUnits(remainder)*2 >= uint64(divisor) {
+		if d.units < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+
+	return Decimal{units: quotient, scale: scale}
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	scale := max(d.scale, other.scale)
+	return d.bigUnits(scale).Cmp(other.bigUnits(scale))
+}
+
+// Equal compares numerically, so 30 equals 30.00.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts both strings and numbers, amounts were always sent as strings.
+// Legacy payloads have null or "" amounts, null leaves the decimal as it is and "" is 0.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+
+	if s == "" {
+		*d = Decimal{}
+		return nil
+	}
+
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d *Decimal) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		*d = NewDecimalFromInt(v)
+		return nil
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", src)
+	}
+
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// bigUnits returns the units at a scale not lower than the decimal's, the result can't overflow.
+func (d Decimal) bigUnits(scale uint8) *big.Int {
+	return roundBig(big.NewInt(d.units), d.scale, scale)
+}
+
+// roundBig rounds units at scale half away from zero to the given places, or pads them.
+func roundBig(units *big.Int, scale, places uint8) *big.Int {
+	if places >= scale {
+		return new(big.Int).Mul(units, bigPow10(places-scale))
+	}
+
+	divisor := bigPow10(scale - places)
+	quotient, remainder := new(big.Int).QuoRem(units, divisor, new(big.Int))
+
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(units.Sign())))
+	}
+
+	return quotient
+}
+
+// decimalFromBig rounds units to maxDecimalScale places and checks that they fit.
+func decimalFromBig(units *big.Int, scale uint8) (Decimal, error) {
+	if scale > maxDecimalScale {
+		units, scale = roundBig(units, scale, maxDecimalScale), maxDecimalScale
+	}
+
+	if !units.IsInt64() {
+		return Decimal{}, fmt.Errorf("%w: %s * 10^-%d", ErrDecimalOverflow, units, scale)
+	}
+
+	return Decimal{units: units.Int64(), scale: scale}, nil
+}
+
+func bigPow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func pow10(n uint8) int64 {
+	result := int64(1)
+	for range n {
+		result *= 10
+	}
+	return result
+}
+
+func absUnits(units int64) uint64 {
+	if units < 0 {
+		return uint64(-units)
+	}
+	return uint64(units)
+}
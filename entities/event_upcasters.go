@@ -0,0 +1,201 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventUpcaster converts the JSON payload of an event to the next version of the event.
+type EventUpcaster func(payload []byte) ([]byte, error)
+
+type eventVersion struct {
+	event   string
+	version int
+}
+
+// EventUpcasters upgrades events published in older versions to their latest version, so handlers
+// only know the latest version. Event names end with their version, for example "BookingMade_v1".
+type EventUpcasters struct {
+	upcasters map[eventVersion]EventUpcaster
+}
+
+func NewEventUpcasters() EventUpcasters {
+	return EventUpcasters{upcasters: map[eventVersion]EventUpcaster{}}
+}
+
+// Register adds the upcaster from fromVersion of the event to the version after it.
+// It panics when the version already has an upcaster, upcasters are registered on startup.
+func (u EventUpcasters) Register(event string, fromVersion int, upcaster EventUpcaster) {
+	key := eventVersion{event: event, version: fromVersion}
+	if _, ok := u.upcasters[key]; ok {
+		panic(fmt.Sprintf("upcaster of %s is already registered", EventName(event, fromVersion)))
+	}
+
+	u.upcasters[key] = upcaster
+}
+
+// LatestName returns the name of the latest version the event can be upcasted to,
+// names of events without upcasters are returned as they are.
+func (u EventUpcasters) LatestName(eventName string) string {
+	event, version, ok := ParseEventName(eventName)
+	if !ok {
+		return eventName
+	}
+
+	for {
+		if _, ok := u.upcasters[eventVersion{event: event, version: version}]; !ok {
+			return EventName(event, version)
+		}
+		version++
+	}
+}
+
+// Upcast upgrades the payload to the latest version of the event and returns the name of that version.
+func (u EventUpcasters) Upcast(eventName string, payload []byte) (string, []byte, error) {
+	event, version, ok := ParseEventName(eventName)
+	if !ok {
+		return eventName, payload, nil
+	}
+
+	for {
+		upcaster, ok := u.upcasters[eventVersion{event: event, version: version}]
+		if !ok {
+			return EventName(event, version), payload, nil
+		}
+
+		var err error
+		payload, err = upcaster(payload)
+		if err != nil {
+			return "", nil, fmt.Errorf("could not upcast %s: %w", EventName(event, version), err)
+		}
+		version++
+	}
+}
+
+// EventName returns the name of the version of the event, like "BookingMade_v1".
+func EventName(event string, version int) string {
+	return event + "_v" + strconv.Itoa(version)
+}
+
+// ParseEventName splits "BookingMade_v1" into the event and its version.
+func ParseEventName(name string) (event string, version int, ok bool) {
+	i := strings.LastIndex(name, "_v")
+	if i <= 0 {
+		return "", 0, false
+	}
+
+	version, err := strconv.Atoi(name[i+2:])
+	if err != nil || version < 0 {
+		return "", 0, false
+	}
+
+	return name[:i], version, true
+}
+
+// UpcastJSON returns an upcaster converting the payload with convert.
+func UpcastJSON[From, To any](convert func(From) To) EventUpcaster {
+	return func(payload []byte) ([]byte, error) {
+		var from From
+		if err := json.Unmarshal(payload, &from); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(convert(from))
+	}
+}
+
+// DefaultEventUpcasters upgrades events published by the previous version of the system. They are in the data lake
+// and may still be waiting in Redis.
+func DefaultEventUpcasters() EventUpcasters {
+	upcasters := NewEventUpcasters()
+
+	upcasters.Register("BookingMade", 0, UpcastJSON(func(e bookingMade_v0) BookingMade_v1 {
+		return BookingMade_v1{
+			Header:          e.Header,
+			NumberOfTickets: e.NumberOfTickets,
+			BookingID:       e.BookingID,
+			CustomerEmail:   e.CustomerEmail,
+			ShowID:          e.ShowID,
+		}
+	}))
+	upcasters.Register("TicketBookingConfirmed", 0, UpcastJSON(func(e ticketBookingConfirmed_v0) TicketBookingConfirmed_v1 {
+		return TicketBookingConfirmed_v1{
+			Header:        e.Header,
+			TicketID:      e.TicketID,
+			CustomerEmail: e.CustomerEmail,
+			Price:         e.Price,
+			BookingID:     e.BookingID,
+		}
+	}))
+	upcasters.Register("TicketReceiptIssued", 0, UpcastJSON(func(e ticketReceiptIssued_v0) TicketReceiptIssued_v1 {
+		return TicketReceiptIssued_v1{
+			Header:        e.Header,
+			TicketID:      e.TicketID,
+			ReceiptNumber: e.ReceiptNumber,
+			IssuedAt:      e.IssuedAt,
+		}
+	}))
+	upcasters.Register("TicketPrinted", 0, UpcastJSON(func(e ticketPrinted_v0) TicketPrinted_v1 {
+		return TicketPrinted_v1{
+			Header:   e.Header,
+			TicketID: e.TicketID,
+			FileName: e.FileName,
+		}
+	}))
+	upcasters.Register("TicketRefunded", 0, UpcastJSON(func(e ticketRefunded_v0) TicketRefunded_v1 {
+		return TicketRefunded_v1{
+			Header:   e.Header,
+			TicketID: e.TicketID,
+		}
+	}))
+
+	return upcasters
+}
+
+type bookingMade_v0 struct {
+	Header MessageHeader `json:"header"`
+
+	NumberOfTickets int `json:"number_of_tickets"`
+
+	BookingID uuid.UUID `json:"booking_id"`
+
+	CustomerEmail string    `json:"customer_email"`
+	ShowID        uuid.UUID `json:"show_id"`
+}
+
+type ticketBookingConfirmed_v0 struct {
+	Header MessageHeader `json:"header"`
+
+	TicketID      string `json:"ticket_id"`
+	CustomerEmail string `json:"customer_email"`
+	Price         Money  `json:"price"`
+
+	BookingID string `json:"booking_id"`
+}
+
+type ticketReceiptIssued_v0 struct {
+	Header MessageHeader `json:"header"`
+
+	TicketID      string `json:"ticket_id"`
+	ReceiptNumber string `json:"receipt_number"`
+
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+type ticketPrinted_v0 struct {
+	Header MessageHeader `json:"header"`
+
+	TicketID string `json:"ticket_id"`
+	FileName string `json:"file_name"`
+}
+
+type ticketRefunded_v0 struct {
+	Header MessageHeader `json:"header"`
+
+	TicketID string `json:"ticket_id"`
+}
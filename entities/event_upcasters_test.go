@@ -0,0 +1,128 @@
+package entities_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+func TestDefaultEventUpcasters_BookingMade_v0(t *testing.T) {
+	upcasters := entities.DefaultEventUpcasters()
+
+	bookingID := uuid.New()
+	showID := uuid.New()
+	payload := []byte(`{
+		"header": {"id": "1", "published_at": "2024-06-10T20:00:00Z"},
+		"number_of_tickets": 2,
+		"booking_id": "` + bookingID.String() + `",
+		"customer_email": "customer@example.com",
+		"show_id": "` + showID.String() + `"
+	}`)
+
+	name, upcasted, err := upcasters.Upcast("BookingMade_v0", payload)
+	require.NoError(t, err)
+	assert.Equal(t, "BookingMade_v1", name)
+
+	var event entities.BookingMade_v1
+	require.NoError(t, json.Unmarshal(upcasted, &event))
+	assert.Equal(t, "1", event.Header.ID)
+	assert.Equal(t, 2, event.NumberOfTickets)
+	assert.Equal(t, bookingID, event.BookingID)
+	assert.Equal(t, "customer@example.com", event.CustomerEmail)
+	assert.Equal(t, showID, event.ShowID)
+}
+
+func TestEventUpcasters_Upcast(t *testing.T) {
+	type event_v0 struct {
+		Name string `json:"name"`
+	}
+	type event_v1 struct {
+		FullName string `json:"full_name"`
+	}
+	type event_v2 struct {
+		FirstName string `json:"first_name"`
+	}
+
+	upcasters := entities.NewEventUpcasters()
+	upcasters.Register("Event", 0, entities.UpcastJSON(func(e event_v0) event_v1 {
+		return event_v1{FullName: e.Name}
+	}))
+	upcasters.Register("Event", 1, entities.UpcastJSON(func(e event_v1) event_v2 {
+		return event_v2{FirstName: e.FullName}
+	}))
+
+	testCases := []struct {
+		Name            string
+		EventName       string
+		Payload         string
+		ExpectedName    string
+		ExpectedPayload string
+	}{
+		{
+			Name:            "chain_of_upcasters",
+			EventName:       "Event_v0",
+			Payload:         `{"name":"John"}`,
+			ExpectedName:    "Event_v2",
+			ExpectedPayload: `{"first_name":"John"}`,
+		},
+		{
+			Name:            "intermediate_version",
+			EventName:       "Event_v1",
+			Payload:         `{"full_name":"John"}`,
+			ExpectedName:    "Event_v2",
+			ExpectedPayload: `{"first_name":"John"}`,
+		},
+		{
+			Name:            "latest_version",
+			EventName:       "Event_v2",
+			Payload:         `{"first_name":"John"}`,
+			ExpectedName:    "Event_v2",
+			ExpectedPayload: `{"first_name":"John"}`,
+		},
+		{
+			Name:            "unversioned_name",
+			EventName:       "Event",
+			Payload:         `{"name":"John"}`,
+			ExpectedName:    "Event",
+			ExpectedPayload: `{"name":"John"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			name, payload, err := upcasters.Upcast(tc.EventName, []byte(tc.Payload))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedName, name)
+			assert.JSONEq(t, tc.ExpectedPayload, string(payload))
+			assert.Equal(t, tc.ExpectedName, upcasters.LatestName(tc.EventName))
+		})
+	}
+
+	t.Run("invalid_payload", func(t *testing.T) {
+		_, _, err := upcasters.Upcast("Event_v0", []byte("not json"))
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate_upcaster", func(t *testing.T) {
+		assert.Panics(t, func() {
+			upcasters.Register("Event", 1, entities.UpcastJSON(func(e event_v1) event_v2 { return event_v2{} }))
+		})
+	})
+}
+
+func TestParseEventName(t *testing.T) {
+	event, version, ok := entities.ParseEventName("TicketBookingConfirmed_v12")
+	require.True(t, ok)
+	assert.Equal(t, "TicketBookingConfirmed", event)
+	assert.Equal(t, 12, version)
+
+	for _, name := range []string{"TicketBookingConfirmed", "_v1", "Event_vX", "Event_v-1"} {
+		_, _, ok := entities.ParseEventName(name)
+		assert.False(t, ok, name)
+	}
+}
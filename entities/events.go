@@ -48,6 +48,9 @@ type TicketPrinted_v1 struct {
 	Header   MessageHeader `json:"header"`
 	TicketID string        `json:"ticket_id"`
 	FileName string        `json:"file_name"`
+
+	// FileNames lists every rendered file, FileName is the HTML file among them.
+	FileNames []string `json:"file_names,omitempty"`
 }
 
 type BookingMade_v1 struct {
@@ -56,6 +59,15 @@ type BookingMade_v1 struct {
 	BookingID       uuid.UUID     `json:"booking_id"`
 	CustomerEmail   string        `json:"customer_email"`
 	ShowID          uuid.UUID     `json:"show_id"`
+	Seats           []Seat        `json:"seats,omitempty"`
+	PriceCategory   string        `json:"price_category,omitempty"`
+	TicketPrice     *Money        `json:"ticket_price,omitempty"`
+
+	// Discount is set when a promo code was applied, TicketPrice is then the discounted price.
+	Discount *BookingDiscount `json:"discount,omitempty"`
+
+	// HoldID is set when the booking was made by confirming a seat hold.
+	HoldID *uuid.UUID `json:"hold_id,omitempty"`
 }
 
 type TicketReceiptIssued_v1 struct {
@@ -68,6 +80,106 @@ type TicketReceiptIssued_v1 struct {
 type TicketRefunded_v1 struct {
 	Header   MessageHeader `json:"header"`
 	TicketID string        `json:"ticket_id"`
+
+	// Amount is set for partial refunds, nil means the full ticket price was refunded.
+	Amount *Money       `json:"amount,omitempty"`
+	Reason RefundReason `json:"reason,omitempty"`
+
+	// FullyRefunded is set when nothing of the price was left after the refund,
+	// with Amount set it's what was left after earlier partial refunds.
+	FullyRefunded bool `json:"fully_refunded,omitempty"`
+}
+
+// TicketCheckedIn_v1 is published when the ticket was scanned at the venue.
+type TicketCheckedIn_v1 struct {
+	Header      MessageHeader `json:"header"`
+	TicketID    string        `json:"ticket_id"`
+	BookingID   uuid.UUID     `json:"booking_id"`
+	ShowID      uuid.UUID     `json:"show_id"`
+	CheckedInAt time.Time     `json:"checked_in_at"`
+}
+
+// TicketTransferred_v1 is published when the ticket got a new holder, Revision is the number of transfers of the ticket.
+type TicketTransferred_v1 struct {
+	Header                MessageHeader `json:"header"`
+	TicketID              string        `json:"ticket_id"`
+	BookingID             string        `json:"booking_id"`
+	PreviousCustomerEmail string        `json:"previous_customer_email"`
+	CustomerEmail         string        `json:"customer_email"`
+	Price                 Money         `json:"price"`
+	Revision              int           `json:"revision"`
+}
+
+// RefundPolicyOverridden_v1 audits refunds which ops made against the refund policy.
+type RefundPolicyOverridden_v1 struct {
+	Header       MessageHeader   `json:"header"`
+	TicketID     string          `json:"ticket_id"`
+	Amount       *Money          `json:"amount,omitempty"`
+	Rejection    RefundRejection `json:"rejection"`
+	OverriddenBy string          `json:"overridden_by"`
+	Reason       string          `json:"reason"`
+}
+
+type ShowCanceled_v1 struct {
+	Header MessageHeader `json:"header"`
+	ShowID uuid.UUID     `json:"show_id"`
+}
+
+type SeatsHeld_v1 struct {
+	Header          MessageHeader `json:"header"`
+	HoldID          uuid.UUID     `json:"hold_id"`
+	ShowID          uuid.UUID     `json:"show_id"`
+	NumberOfTickets int           `json:"number_of_tickets"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+}
+
+type SeatHoldExpired_v1 struct {
+	Header          MessageHeader `json:"header"`
+	HoldID          uuid.UUID     `json:"hold_id"`
+	ShowID          uuid.UUID     `json:"show_id"`
+	NumberOfTickets int           `json:"number_of_tickets"`
+	Seats           []Seat        `json:"seats,omitempty"`
+}
+
+type BookingCanceled_v1 struct {
+	Header          MessageHeader `json:"header"`
+	BookingID       uuid.UUID     `json:"booking_id"`
+	ShowID          uuid.UUID     `json:"show_id"`
+	NumberOfTickets int           `json:"number_of_tickets"`
+	CustomerEmail   string        `json:"customer_email"`
+	Seats           []Seat        `json:"seats,omitempty"`
+
+	// ReleasedTickets is lower than NumberOfTickets when some tickets of the booking were refunded before.
+	ReleasedTickets int `json:"released_tickets"`
+}
+
+type SeatsReleased_v1 struct {
+	Header          MessageHeader `json:"header"`
+	ShowID          uuid.UUID     `json:"show_id"`
+	BookingID       uuid.UUID     `json:"booking_id"`
+	NumberOfTickets int           `json:"number_of_tickets"`
+	Seats           []Seat        `json:"seats,omitempty"`
+}
+
+type WaitlistOfferMade_v1 struct {
+	Header          MessageHeader `json:"header"`
+	EntryID         uuid.UUID     `json:"entry_id"`
+	HoldID          uuid.UUID     `json:"hold_id"`
+	ShowID          uuid.UUID     `json:"show_id"`
+	NumberOfTickets int           `json:"number_of_tickets"`
+	CustomerEmail   string        `json:"customer_email"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+	Seats           []Seat        `json:"seats,omitempty"`
+}
+
+// TicketPriceMismatchDetected_v1 is an alert for finance, the confirmed ticket price doesn't match the show's price catalog.
+type TicketPriceMismatchDetected_v1 struct {
+	Header         MessageHeader `json:"header"`
+	TicketID       string        `json:"ticket_id"`
+	BookingID      uuid.UUID     `json:"booking_id"`
+	PriceCategory  string        `json:"price_category"`
+	ExpectedPrice  Money         `json:"expected_price"`
+	ConfirmedPrice Money         `json:"confirmed_price"`
 }
 
 type DataLakeEvent struct {
@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidExchangeRate  = errors.New("invalid exchange rate")
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+)
+
+// maxExchangeRateScale keeps converted amounts within Decimal's scale before they are rounded.
+const maxExchangeRateScale = 6
+
+// ExchangeRate says that one unit of FromCurrency is worth Rate units of ToCurrency,
+// starting at EffectiveFrom until the next rate of the same pair becomes effective.
+type ExchangeRate struct {
+	FromCurrency  string    `json:"from_currency" db:"from_currency"`
+	ToCurrency    string    `json:"to_currency" db:"to_currency"`
+	Rate          Decimal   `json:"rate" db:"rate"`
+	EffectiveFrom time.Time `json:"effective_from" db:"effective_from"`
+}
+
+func (r ExchangeRate) Validate() error {
+	if !IsValidCurrency(r.FromCurrency) {
+		return fmt.Errorf("%w: unknown currency %q", ErrInvalidExchangeRate, r.FromCurrency)
+	}
+	if !IsValidCurrency(r.ToCurrency) {
+		return fmt.Errorf("%w: unknown currency %q", ErrInvalidExchangeRate, r.ToCurrency)
+	}
+	if r.FromCurrency == r.ToCurrency {
+		return fmt.Errorf("%w: %s to itself", ErrInvalidExchangeRate, r.FromCurrency)
+	}
+	if r.Rate.Sign() <= 0 {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidExchangeRate)
+	}
+	if r.Rate.Scale() > maxExchangeRateScale {
+		return fmt.Errorf("%w: rate has more than %d decimal places", ErrInvalidExchangeRate, maxExchangeRateScale)
+	}
+	if r.EffectiveFrom.IsZero() {
+		return fmt.Errorf("%w: missing effective_from", ErrInvalidExchangeRate)
+	}
+
+	return nil
+}
+
+// ExchangeRates looks up the rate that applied at a given time.
+type ExchangeRates struct {
+	// rates of each currency pair, sorted by EffectiveFrom
+	byPair map[[2]string][]ExchangeRate
+}
+
+func NewExchangeRates(rates []ExchangeRate) ExchangeRates {
+	byPair := make(map[[2]string][]ExchangeRate)
+	for _, rate := range rates {
+		pair := [2]string{rate.FromCurrency, rate.ToCurrency}
+		byPair[pair] = append(byPair[pair], rate)
+	}
+
+	for _, pairRates := range byPair {
+		sort.Slice(pairRates, func(i, j int) bool {
+			return pairRates[i].EffectiveFrom.Before(pairRates[j].EffectiveFrom)
+		})
+	}
+
+	return ExchangeRates{byPair: byPair}
+}
+
+// RateAt returns the latest rate of the pair that was effective at the given time.
+func (r ExchangeRates) RateAt(fromCurrency, toCurrency string, at time.Time) (ExchangeRate, error) {
+	pairRates := r.byPair[[2]string{fromCurrency, toCurrency}]
+
+	// index of the first rate that became effective after at
+	i := sort.Search(len(pairRates), func(i int) bool {
+		return pairRates[i].EffectiveFrom.After(at)
+	})
+	if i == 0 {
+		return ExchangeRate{}, fmt.Errorf(
+			"%w: %s to %s at %s",
+			ErrExchangeRateNotFound, fromCurrency, toCurrency, at.UTC().Format(time.RFC3339),
+		)
+	}
+
+	return pairRates[i-1], nil
+}
+
+// Convert converts money to the currency at the rate effective at the given time,
+// rounded to the currency's minor units.
+func (r ExchangeRates) Convert(m Money, toCurrency string, at time.Time) (Money, error) {
+	if m.Currency == toCurrency {
+		return m, nil
+	}
+
+	rate, err := r.RateAt(m.Currency, toCurrency, at)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount, err := m.Amount.MulRound(rate.Rate, CurrencyMinorUnits(toCurrency))
+	if err != nil {
+		return Money{}, fmt.Errorf("could not convert %s to %s: %w", m, toCurrency, err)
+	}
+
+	return Money{Amount: amount, Currency: toCurrency}, nil
+}
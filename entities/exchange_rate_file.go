@@ -0,0 +1,93 @@
+package entities
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var exchangeRatesCSVHeader = []string{"from_currency", "to_currency", "rate", "effective_from"}
+
+// ParseExchangeRatesJSON parses a JSON array of exchange rates and validates them.
+func ParseExchangeRatesJSON(r io.Reader) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+	if err := json.NewDecoder(r).Decode(&rates); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExchangeRate, err)
+	}
+
+	return validateExchangeRates(rates)
+}
+
+// ParseExchangeRatesCSV parses CSV with the from_currency,to_currency,rate,effective_from header
+// and validates the rates. effective_from is an RFC 3339 time or a date, which means midnight UTC.
+func ParseExchangeRatesCSV(r io.Reader) ([]ExchangeRate, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExchangeRate, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	if strings.Join(header, ",") != strings.Join(exchangeRatesCSVHeader, ",") {
+		return nil, fmt.Errorf(
+			"%w: expected CSV header %q",
+			ErrInvalidExchangeRate, strings.Join(exchangeRatesCSVHeader, ","),
+		)
+	}
+
+	rates := make([]ExchangeRate, 0, len(records)-1)
+	for i, record := range records[1:] {
+		line := i + 2
+
+		rate, err := ParseDecimal(strings.TrimSpace(record[2]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidExchangeRate, line, err)
+		}
+
+		effectiveFrom, err := parseEffectiveFrom(strings.TrimSpace(record[3]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidExchangeRate, line, err)
+		}
+
+		rates = append(rates, ExchangeRate{
+			FromCurrency:  strings.TrimSpace(record[0]),
+			ToCurrency:    strings.TrimSpace(record[1]),
+			Rate:          rate,
+			EffectiveFrom: effectiveFrom,
+		})
+	}
+
+	return validateExchangeRates(rates)
+}
+
+func parseEffectiveFrom(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, errors.New("effective_from must be an RFC 3339 time or a YYYY-MM-DD date")
+	}
+
+	return t, nil
+}
+
+func validateExchangeRates(rates []ExchangeRate) ([]ExchangeRate, error) {
+	for i, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,138 @@
+package entities_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+func TestExchangeRates_Convert(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	rates := entities.NewExchangeRates([]entities.ExchangeRate{
+		{FromCurrency: "USD", ToCurrency: "EUR", Rate: entities.MustParseDecimal("0.8"), EffectiveFrom: feb},
+		{FromCurrency: "USD", ToCurrency: "EUR", Rate: entities.MustParseDecimal("0.9"), EffectiveFrom: jan},
+	})
+
+	testCases := []struct {
+		Name     string
+		Money    entities.Money
+		At       time.Time
+		Expected string
+	}{
+		{Name: "first_rate", Money: entities.MustNewMoney("10.00", "USD"), At: jan.Add(time.Hour), Expected: "9.00 EUR"},
+		{Name: "effective_from_is_inclusive", Money: entities.MustNewMoney("10.00", "USD"), At: feb, Expected: "8.00 EUR"},
+		{Name: "rounds_to_minor_units", Money: entities.MustNewMoney("0.05", "USD"), At: jan, Expected: "0.05 EUR"},
+		{Name: "same_currency", Money: entities.MustNewMoney("10.00", "EUR"), At: time.Time{}, Expected: "10.00 EUR"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			converted, err := rates.Convert(tc.Money, "EUR", tc.At)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, converted.String())
+		})
+	}
+
+	_, err := rates.Convert(entities.MustNewMoney("10.00", "USD"), "EUR", jan.Add(-time.Second))
+	assert.ErrorIs(t, err, entities.ErrExchangeRateNotFound)
+
+	_, err = rates.Convert(entities.MustNewMoney("10.00", "GBP"), "EUR", feb)
+	assert.ErrorIs(t, err, entities.ErrExchangeRateNotFound)
+}
+
+func TestParseExchangeRatesCSV(t *testing.T) {
+	rates, err := entities.ParseExchangeRatesCSV(strings.NewReader(
+		"from_currency,to_currency,rate,effective_from\n" +
+			"USD,EUR,0.92,2024-01-01\n" +
+			"GBP,EUR,1.17,2024-01-01T12:00:00+01:00\n",
+	))
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "0.92", rates[0].Rate.String())
+	assert.True(t, rates[0].EffectiveFrom.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, rates[1].EffectiveFrom.Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)))
+
+	for _, invalid := range []string{
+		"from,to,rate,date\nUSD,EUR,0.92,2024-01-01\n",
+		"from_currency,to_currency,rate,effective_from\nUSD,EUR,-1,2024-01-01\n",
+		"from_currency,to_currency,rate,effective_from\nUSD,USD,1,2024-01-01\n",
+		"from_currency,to_currency,rate,effective_from\nUSD,EUR,0.92,yesterday\n",
+	} {
+		_, err := entities.ParseExchangeRatesCSV(strings.NewReader(invalid))
+		assert.ErrorIs(t, err, entities.ErrInvalidExchangeRate, invalid)
+	}
+}
+
+func TestNewRevenueReport(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	rates := entities.NewExchangeRates([]entities.ExchangeRate{
+		{FromCurrency: "USD", ToCurrency: "EUR", Rate: entities.MustParseDecimal("0.9"), EffectiveFrom: jan},
+		{FromCurrency: "USD", ToCurrency: "EUR", Rate: entities.MustParseDecimal("0.8"), EffectiveFrom: feb},
+	})
+
+	bookings := []entities.OpsBooking{
+		{
+			Tickets: map[string]entities.OpsTicket{
+				"eur-jan": {PriceAmount: "30.00", PriceCurrency: "EUR", ConfirmedAt: jan.Add(time.Hour)},
+				"usd-jan": {PriceAmount: "10.00", PriceCurrency: "USD", ConfirmedAt: jan.Add(time.Hour)},
+				// refunded in February at the January rate it was sold at
+				"usd-jan-refunded": {PriceAmount: "10.00", PriceCurrency: "USD", ConfirmedAt: jan.Add(time.Hour), RefundedAt: feb.Add(time.Hour)},
+			},
+		},
+		{
+			Tickets: map[string]entities.OpsTicket{
+				"usd-feb":                    {PriceAmount: "10.00", PriceCurrency: "USD", ConfirmedAt: feb.Add(time.Hour)},
+				"eur-feb-partially-refunded": {PriceAmount: "20.00", PriceCurrency: "EUR", ConfirmedAt: feb.Add(time.Hour), RefundedAt: feb.Add(2 * time.Hour), RefundedAmount: "5.00"},
+				"usd-mar":                    {PriceAmount: "10.00", PriceCurrency: "USD", ConfirmedAt: mar.Add(time.Hour)},
+			},
+		},
+	}
+
+	report, err := entities.NewRevenueReport(bookings, rates, "EUR", feb, mar)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.TicketsSold)
+	assert.Equal(t, 2, report.TicketsRefunded)
+	assert.Equal(t, "28.00 EUR", report.Gross.String())
+	assert.Equal(t, "14.00 EUR", report.Refunded.String())
+	assert.Equal(t, "14.00 EUR", report.Net.String())
+
+	report, err = entities.NewRevenueReport(bookings, rates, "EUR", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.TicketsSold)
+	assert.Equal(t, "50.00 EUR", report.ByCurrency[0].Gross.String())
+	assert.Equal(t, "5.00 EUR", report.ByCurrency[0].Refunded.String())
+	assert.Equal(t, "40.00 USD", report.ByCurrency[1].Gross.String())
+	assert.Equal(t, "34.00 EUR", report.ByCurrency[1].GrossConverted.String())
+	assert.Equal(t, "84.00 EUR", report.Gross.String())
+
+	_, err = entities.NewRevenueReport(bookings, rates, "GBP", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, entities.ErrExchangeRateNotFound)
+}
+
+func TestNewRevenueReport_overflow(t *testing.T) {
+	confirmedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	bookings := []entities.OpsBooking{
+		{
+			Tickets: map[string]entities.OpsTicket{
+				"first":  {PriceAmount: "90000000000000000", PriceCurrency: "EUR", ConfirmedAt: confirmedAt},
+				"second": {PriceAmount: "90000000000000000", PriceCurrency: "EUR", ConfirmedAt: confirmedAt},
+			},
+		},
+	}
+
+	_, err := entities.NewRevenueReport(bookings, entities.NewExchangeRates(nil), "EUR", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, entities.ErrDecimalOverflow)
+}
@@ -1,6 +1,109 @@
 package entities
 
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidMoney     = errors.New("invalid money")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
 type Money struct {
-	Amount   string `json:"amount"`
-	Currency string `json:"currency"`
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+func NewMoney(amount, currency string) (Money, error) {
+	decimalAmount, err := ParseDecimal(amount)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %w", ErrInvalidMoney, err)
+	}
+
+	m := Money{Amount: decimalAmount, Currency: currency}
+	if err := m.Validate(); err != nil {
+		return Money{}, err
+	}
+
+	return m, nil
+}
+
+// MustNewMoney is NewMoney for constants, it panics on invalid input.
+func MustNewMoney(amount, currency string) Money {
+	m, err := NewMoney(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Validate checks that the currency is an ISO 4217 code and the amount is not negative
+// and has no more decimal places than the currency uses.
+func (m Money) Validate() error {
+	if !IsValidCurrency(m.Currency) {
+		return fmt.Errorf("%w: unknown currency %q", ErrInvalidMoney, m.Currency)
+	}
+	if m.Amount.Sign() < 0 {
+		return fmt.Errorf("%w: negative amount %s", ErrInvalidMoney, m.Amount)
+	}
+	if minorUnits := CurrencyMinorUnits(m.Currency); m.Amount.Scale() > minorUnits {
+		return fmt.Errorf("%w: %s has at most %d decimal places", ErrInvalidMoney, m.Currency, minorUnits)
+	}
+
+	return nil
+}
+
+// Round rounds the amount to the currency's minor units, for example to cents for EUR.
+func (m Money) Round() Money {
+	return Money{Amount: m.Amount.Round(CurrencyMinorUnits(m.Currency)), Currency: m.Currency}
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	amount, err := m.Amount.Add(other.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(Money{Amount: other.Amount.Neg(), Currency: other.Currency})
+}
+
+func (m Money) MulInt(i int64) (Money, error) {
+	amount, err := m.Amount.MulInt(i)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: amount, Currency: m.Currency}, nil
+}
+
+// Percent returns the percentage (0-100) of the amount, rounded to the currency's minor units.
+func (m Money) Percent(percentage int) Money {
+	p := Decimal{units: int64(percentage), scale: 2}
+
+	amount, err := m.Amount.MulRound(p, CurrencyMinorUnits(m.Currency))
+	if err != nil {
+		// the amount has fewer places than the currency and doesn't fit when padded,
+		// at its own places the result isn't more than the amount
+		amount, _ = m.Amount.MulRound(p, m.Amount.Scale())
+	}
+
+	return Money{Amount: amount, Currency: m.Currency}
+}
+
+// Equal compares amounts numerically, so "30" equals "30.00".
+func (m Money) Equal(other Money) bool {
+	return m.Currency == other.Currency && m.Amount.Equal(other.Amount)
+}
+
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
 }
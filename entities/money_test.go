@@ -0,0 +1,139 @@
+package entities_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+func TestDecimal(t *testing.T) {
+	must := func(d entities.Decimal, err error) entities.Decimal {
+		require.NoError(t, err)
+		return d
+	}
+
+	testCases := []struct {
+		Name     string
+		Result   entities.Decimal
+		Expected string
+	}{
+		{Name: "keeps_scale", Result: entities.MustParseDecimal("30.00"), Expected: "30.00"},
+		{Name: "small_fraction", Result: entities.MustParseDecimal("0.05"), Expected: "0.05"},
+		{Name: "negative", Result: entities.MustParseDecimal("-0.5"), Expected: "-0.5"},
+		{Name: "add_aligns_scales", Result: must(entities.MustParseDecimal("0.1").Add(entities.MustParseDecimal("0.25"))), Expected: "0.35"},
+		{Name: "sub", Result: must(entities.MustParseDecimal("10").Sub(entities.MustParseDecimal("0.01"))), Expected: "9.99"},
+		{Name: "mul", Result: must(entities.MustParseDecimal("19.99").Mul(entities.MustParseDecimal("1.5"))), Expected: "29.985"},
+		{Name: "round_half_up", Result: entities.MustParseDecimal("29.985").Round(2), Expected: "29.99"},
+		{Name: "round_down", Result: entities.MustParseDecimal("29.984").Round(2), Expected: "29.98"},
+		{Name: "round_negative", Result: entities.MustParseDecimal("-0.125").Round(2), Expected: "-0.13"},
+		{Name: "round_pads", Result: entities.MustParseDecimal("5").Round(2), Expected: "5.00"},
+		{Name: "mul_rounds_to_max_scale", Result: must(entities.MustParseDecimal("0.00001").Mul(entities.MustParseDecimal("0.000015"))), Expected: "0.000000000"},
+		{Name: "mul_round", Result: must(entities.MustParseDecimal("19.99").MulRound(entities.MustParseDecimal("1.5"), 2)), Expected: "29.99"},
+		{Name: "mul_round_fits_once_rounded", Result: must(entities.MustParseDecimal("9000000000").MulRound(entities.MustParseDecimal("1.123456789"), 2)), Expected: "10111111101.00"},
+		{Name: "round_keeps_scale_when_padding_overflows", Result: entities.MustParseDecimal("9000000000000000000").Round(2), Expected: "9000000000000000000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, tc.Result.String())
+		})
+	}
+}
+
+func TestDecimal_overflow(t *testing.T) {
+	large := entities.MustParseDecimal("9000000000000000000")
+
+	_, err := large.Add(large)
+	assert.ErrorIs(t, err, entities.ErrDecimalOverflow)
+
+	_, err = large.Neg().Sub(large)
+	assert.ErrorIs(t, err, entities.ErrDecimalOverflow)
+
+	_, err = large.Mul(entities.MustParseDecimal("2"))
+	assert.ErrorIs(t, err, entities.ErrDecimalOverflow)
+
+	_, err = large.MulInt(2)
+	assert.ErrorIs(t, err, entities.ErrDecimalOverflow)
+
+	_, err = entities.MustNewMoney("9000000000000000000", "JPY").Add(entities.MustNewMoney("9000000000000000000", "JPY"))
+	assert.ErrorIs(t, err, entities.ErrDecimalOverflow)
+
+	assert.Equal(t, 1, large.Cmp(entities.MustParseDecimal("0.01")), "comparing doesn't overflow")
+}
+
+func TestParseDecimal_invalid(t *testing.T) {
+	for _, s := range []string{"", "abc", "1.", ".5", "1,5", "1e3", "1.2.3", "99999999999999999999"} {
+		_, err := entities.ParseDecimal(s)
+		assert.ErrorIs(t, err, entities.ErrInvalidDecimal, s)
+	}
+}
+
+func TestMoney_Validate(t *testing.T) {
+	assert.NoError(t, entities.Money{Amount: entities.MustParseDecimal("30.00"), Currency: "EUR"}.Validate())
+	assert.NoError(t, entities.Money{Amount: entities.MustParseDecimal("1500"), Currency: "JPY"}.Validate())
+	assert.NoError(t, entities.Money{Amount: entities.MustParseDecimal("1.125"), Currency: "KWD"}.Validate())
+
+	assert.ErrorIs(t, entities.Money{Amount: entities.MustParseDecimal("30.00"), Currency: "EURO"}.Validate(), entities.ErrInvalidMoney)
+	assert.ErrorIs(t, entities.Money{Amount: entities.MustParseDecimal("30.00"), Currency: "eur"}.Validate(), entities.ErrInvalidMoney)
+	assert.ErrorIs(t, entities.Money{Amount: entities.MustParseDecimal("30.001"), Currency: "EUR"}.Validate(), entities.ErrInvalidMoney)
+	assert.ErrorIs(t, entities.Money{Amount: entities.MustParseDecimal("1500.5"), Currency: "JPY"}.Validate(), entities.ErrInvalidMoney)
+	assert.ErrorIs(t, entities.Money{Amount: entities.MustParseDecimal("-1.00"), Currency: "EUR"}.Validate(), entities.ErrInvalidMoney)
+}
+
+func TestMoney_arithmetic(t *testing.T) {
+	price := entities.MustNewMoney("19.99", "EUR")
+
+	total, err := price.MulInt(3)
+	require.NoError(t, err)
+	total, err = total.Add(entities.MustNewMoney("0.03", "EUR"))
+	require.NoError(t, err)
+	assert.True(t, total.Equal(entities.MustNewMoney("60", "EUR")))
+
+	_, err = price.Add(entities.MustNewMoney("19.99", "USD"))
+	assert.ErrorIs(t, err, entities.ErrCurrencyMismatch)
+
+	assert.Equal(t, "1500", entities.Money{Amount: entities.MustParseDecimal("1499.5"), Currency: "JPY"}.Round().Amount.String())
+
+	assert.Equal(t, "10.00", price.Percent(50).Amount.String())
+	assert.Equal(t, "4500000000000000000", entities.MustNewMoney("9000000000000000000", "EUR").Percent(50).Amount.String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var m entities.Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"50.30","currency":"EUR"}`), &m))
+	assert.Equal(t, "50.30", m.Amount.String())
+
+	payload, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"50.30","currency":"EUR"}`, string(payload))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":12.5,"currency":"EUR"}`), &m))
+	assert.Equal(t, "12.5", m.Amount.String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"12,50","currency":"EUR"}`), &m))
+}
+
+func TestMoney_JSON_legacy_empty_amount(t *testing.T) {
+	payload := []byte(`{
+		"header": {"id": "1", "published_at": "2024-06-10T20:00:00Z"},
+		"ticket_id": "ticket-1",
+		"customer_email": "customer@example.com",
+		"price": {"amount": "", "currency": "EUR"}
+	}`)
+
+	var event entities.TicketBookingConfirmed_v1
+	require.NoError(t, json.Unmarshal(payload, &event))
+	assert.True(t, event.Price.Amount.IsZero())
+	assert.Equal(t, "EUR", event.Price.Currency)
+	assert.NoError(t, event.Price.Validate())
+
+	var m entities.Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":null,"currency":"EUR"}`), &m))
+	assert.True(t, m.Amount.IsZero())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":" ","currency":"EUR"}`), &m))
+}
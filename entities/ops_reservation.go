@@ -9,8 +9,14 @@ import (
 type OpsBooking struct {
 	BookingID  uuid.UUID            `json:"booking_id"`
 	BookedAt   time.Time            `json:"booked_at"`
+	Seats      []Seat               `json:"seats,omitempty"`
+	CanceledAt time.Time            `json:"canceled_at"`
 	Tickets    map[string]OpsTicket `json:"tickets"`
 	LastUpdate time.Time            `json:"last_update"`
+
+	// TicketPrice is the price from the price catalog, discounted when Discount is set.
+	TicketPrice *Money           `json:"ticket_price,omitempty"`
+	Discount    *BookingDiscount `json:"discount,omitempty"`
 }
 
 type OpsTicket struct {
@@ -21,18 +27,41 @@ type OpsTicket struct {
 	ConfirmedAt time.Time `json:"confirmed_at"`
 	RefundedAt  time.Time `json:"refunded_at"`
 
-	PrintedAt       time.Time `json:"printed_at"`
-	PrintedFileName string    `json:"printed_file_name"`
+	// RefundedAmount is the sum of Refunds, in the ticket price currency.
+	RefundedAmount string            `json:"refunded_amount,omitempty"`
+	Refunds        []OpsTicketRefund `json:"refunds,omitempty"`
+
+	PrintedAt        time.Time `json:"printed_at"`
+	PrintedFileName  string    `json:"printed_file_name"`
+	PrintedFileNames []string  `json:"printed_file_names,omitempty"`
 
 	ReceiptIssuedAt time.Time `json:"receipt_issued_at"`
 	ReceiptNumber   string    `json:"receipt_number"`
+
+	CheckedInAt time.Time `json:"checked_in_at"`
+
+	// Revision is the number of transfers, files printed for previous holders are in InvalidatedFileNames.
+	Revision             int       `json:"revision,omitempty"`
+	TransferredAt        time.Time `json:"transferred_at,omitempty"`
+	InvalidatedFileNames []string  `json:"invalidated_file_names,omitempty"`
+}
+
+type OpsTicketRefund struct {
+	IdempotencyKey string       `json:"idempotency_key"`
+	Amount         string       `json:"amount"`
+	Partial        bool         `json:"partial"`
+	Reason         RefundReason `json:"reason,omitempty"`
+	RefundedAt     time.Time    `json:"refunded_at"`
 }
 
 func NewOpsBooking(bookingMade BookingMade_v1) OpsBooking {
 	return OpsBooking{
-		BookingID:  bookingMade.BookingID,
-		BookedAt:   bookingMade.Header.PublishedAt,
-		Tickets:    make(map[string]OpsTicket),
-		LastUpdate: time.Now().UTC(),
+		BookingID:   bookingMade.BookingID,
+		BookedAt:    bookingMade.Header.PublishedAt,
+		Seats:       bookingMade.Seats,
+		TicketPrice: bookingMade.TicketPrice,
+		Discount:    bookingMade.Discount,
+		Tickets:     make(map[string]OpsTicket),
+		LastUpdate:  time.Now().UTC(),
 	}
 }
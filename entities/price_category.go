@@ -0,0 +1,7 @@
+package entities
+
+// PriceCategory is a price of the show's tickets, for example standard, VIP or student.
+type PriceCategory struct {
+	Name  string `json:"name" db:"category"`
+	Price Money  `json:"price" db:"price"`
+}
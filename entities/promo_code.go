@@ -0,0 +1,144 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPromoCode       = errors.New("invalid promo code")
+	ErrPromoCodeNotApplicable = errors.New("promo code is not applicable")
+)
+
+const maxPromoCodeLength = 64
+
+type PromoCodeDiscountType string
+
+const (
+	PromoCodePercentage  PromoCodeDiscountType = "percentage"
+	PromoCodeFixedAmount PromoCodeDiscountType = "fixed_amount"
+)
+
+// PromoCode discounts the ticket price of bookings, either by Percentage or by a fixed Amount per ticket.
+type PromoCode struct {
+	Code         string                `json:"code" db:"code"`
+	DiscountType PromoCodeDiscountType `json:"discount_type" db:"discount_type"`
+	Percentage   int                   `json:"percentage,omitempty" db:"percentage"`
+	Amount       *Money                `json:"amount,omitempty" db:"-"`
+
+	// ShowID limits the code to one show, ValidFrom and ValidUntil to a time window.
+	ShowID     *uuid.UUID `json:"show_id,omitempty" db:"show_id"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty" db:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until,omitempty" db:"valid_until"`
+
+	// MaxRedemptions limits the number of bookings made with the code, nil means unlimited.
+	MaxRedemptions *int `json:"max_redemptions,omitempty" db:"max_redemptions"`
+	Redemptions    int  `json:"redemptions" db:"redemptions"`
+}
+
+// NormalizePromoCode makes codes case-insensitive.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p PromoCode) Validate() error {
+	if p.Code == "" || len(p.Code) > maxPromoCodeLength {
+		return fmt.Errorf("%w: code must have 1 to %d characters", ErrInvalidPromoCode, maxPromoCodeLength)
+	}
+
+	switch p.DiscountType {
+	case PromoCodePercentage:
+		if p.Percentage < 1 || p.Percentage > 100 {
+			return fmt.Errorf("%w: percentage must be between 1 and 100", ErrInvalidPromoCode)
+		}
+		if p.Amount != nil {
+			return fmt.Errorf("%w: percentage discount can't have an amount", ErrInvalidPromoCode)
+		}
+	case PromoCodeFixedAmount:
+		if p.Amount == nil {
+			return fmt.Errorf("%w: fixed amount discount requires an amount", ErrInvalidPromoCode)
+		}
+		if err := p.Amount.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPromoCode, err)
+		}
+		if p.Amount.Amount.Sign() == 0 {
+			return fmt.Errorf("%w: amount must be greater than 0", ErrInvalidPromoCode)
+		}
+		if p.Percentage != 0 {
+			return fmt.Errorf("%w: fixed amount discount can't have a percentage", ErrInvalidPromoCode)
+		}
+	default:
+		return fmt.Errorf("%w: discount type must be %s or %s", ErrInvalidPromoCode, PromoCodePercentage, PromoCodeFixedAmount)
+	}
+
+	if p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidUntil.After(*p.ValidFrom) {
+		return fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidPromoCode)
+	}
+	if p.MaxRedemptions != nil && *p.MaxRedemptions < 1 {
+		return fmt.Errorf("%w: max redemptions must be greater than 0", ErrInvalidPromoCode)
+	}
+
+	return nil
+}
+
+// CheckApplicable checks the show and validity window limits, redemptions are counted by the repository.
+func (p PromoCode) CheckApplicable(showID uuid.UUID, at time.Time) error {
+	if p.ShowID != nil && *p.ShowID != showID {
+		return fmt.Errorf("%w: %s is for another show", ErrPromoCodeNotApplicable, p.Code)
+	}
+	if p.ValidFrom != nil && at.Before(*p.ValidFrom) {
+		return fmt.Errorf("%w: %s is not valid yet", ErrPromoCodeNotApplicable, p.Code)
+	}
+	if p.ValidUntil != nil && !at.Before(*p.ValidUntil) {
+		return fmt.Errorf("%w: %s has expired", ErrPromoCodeNotApplicable, p.Code)
+	}
+
+	return nil
+}
+
+// Apply returns the discount of the ticket price, the discounted price is never negative.
+func (p PromoCode) Apply(ticketPrice Money) (BookingDiscount, error) {
+	var discount Money
+
+	switch p.DiscountType {
+	case PromoCodePercentage:
+		discount = ticketPrice.Percent(p.Percentage)
+	case PromoCodeFixedAmount:
+		if p.Amount == nil || p.Amount.Currency != ticketPrice.Currency {
+			return BookingDiscount{}, fmt.Errorf("%w: %s is for prices in another currency", ErrPromoCodeNotApplicable, p.Code)
+		}
+		discount = *p.Amount
+		if discount.Amount.Cmp(ticketPrice.Amount) > 0 {
+			discount = ticketPrice
+		}
+	default:
+		return BookingDiscount{}, fmt.Errorf("%w: unknown discount type %q", ErrInvalidPromoCode, p.DiscountType)
+	}
+
+	return BookingDiscount{
+		PromoCode:           p.Code,
+		OriginalTicketPrice: ticketPrice,
+		TicketDiscount:      discount,
+	}, nil
+}
+
+// BookingDiscount is the promo code discount of each ticket of a booking.
+type BookingDiscount struct {
+	PromoCode           string `json:"promo_code"`
+	OriginalTicketPrice Money  `json:"original_ticket_price"`
+	TicketDiscount      Money  `json:"ticket_discount"`
+}
+
+// TicketPrice is the discounted price of a ticket.
+func (d BookingDiscount) TicketPrice() Money {
+	price, err := d.OriginalTicketPrice.Sub(d.TicketDiscount)
+	if err != nil {
+		// the discount is in the currency of the price by construction
+		panic(err)
+	}
+	return price
+}
@@ -0,0 +1,98 @@
+package entities_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+func TestPromoCode_Apply(t *testing.T) {
+	fiveEUR := entities.MustNewMoney("5.00", "EUR")
+
+	testCases := []struct {
+		Name          string
+		PromoCode     entities.PromoCode
+		TicketPrice   entities.Money
+		ExpectedPrice string
+	}{
+		{
+			Name:          "percentage",
+			PromoCode:     entities.PromoCode{Code: "P15", DiscountType: entities.PromoCodePercentage, Percentage: 15},
+			TicketPrice:   entities.MustNewMoney("50.00", "EUR"),
+			ExpectedPrice: "42.50 EUR",
+		},
+		{
+			Name:          "percentage_rounds_discount_to_minor_units",
+			PromoCode:     entities.PromoCode{Code: "P15", DiscountType: entities.PromoCodePercentage, Percentage: 15},
+			TicketPrice:   entities.MustNewMoney("19.99", "EUR"),
+			ExpectedPrice: "16.99 EUR",
+		},
+		{
+			Name:          "fixed_amount",
+			PromoCode:     entities.PromoCode{Code: "F5", DiscountType: entities.PromoCodeFixedAmount, Amount: &fiveEUR},
+			TicketPrice:   entities.MustNewMoney("50.00", "EUR"),
+			ExpectedPrice: "45.00 EUR",
+		},
+		{
+			Name:          "fixed_amount_above_price",
+			PromoCode:     entities.PromoCode{Code: "F5", DiscountType: entities.PromoCodeFixedAmount, Amount: &fiveEUR},
+			TicketPrice:   entities.MustNewMoney("3.00", "EUR"),
+			ExpectedPrice: "0.00 EUR",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			discount, err := tc.PromoCode.Apply(tc.TicketPrice)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedPrice, discount.TicketPrice().String())
+			assert.Equal(t, tc.TicketPrice, discount.OriginalTicketPrice)
+		})
+	}
+
+	fixed := entities.PromoCode{Code: "F5", DiscountType: entities.PromoCodeFixedAmount, Amount: &fiveEUR}
+	_, err := fixed.Apply(entities.MustNewMoney("50.00", "USD"))
+	assert.ErrorIs(t, err, entities.ErrPromoCodeNotApplicable)
+}
+
+func TestPromoCode_CheckApplicable(t *testing.T) {
+	showID := uuid.New()
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	validUntil := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	promoCode := entities.PromoCode{
+		Code:         "WINTER",
+		DiscountType: entities.PromoCodePercentage,
+		Percentage:   10,
+		ShowID:       &showID,
+		ValidFrom:    &validFrom,
+		ValidUntil:   &validUntil,
+	}
+	require.NoError(t, promoCode.Validate())
+
+	assert.NoError(t, promoCode.CheckApplicable(showID, validFrom))
+	assert.ErrorIs(t, promoCode.CheckApplicable(uuid.New(), validFrom), entities.ErrPromoCodeNotApplicable)
+	assert.ErrorIs(t, promoCode.CheckApplicable(showID, validFrom.Add(-time.Second)), entities.ErrPromoCodeNotApplicable)
+	assert.ErrorIs(t, promoCode.CheckApplicable(showID, validUntil), entities.ErrPromoCodeNotApplicable)
+}
+
+func TestPromoCode_Validate(t *testing.T) {
+	fiveEUR := entities.MustNewMoney("5.00", "EUR")
+	zero := 0
+
+	for _, invalid := range []entities.PromoCode{
+		{Code: "", DiscountType: entities.PromoCodePercentage, Percentage: 10},
+		{Code: "X", DiscountType: "free", Percentage: 10},
+		{Code: "X", DiscountType: entities.PromoCodePercentage, Percentage: 101},
+		{Code: "X", DiscountType: entities.PromoCodePercentage, Percentage: 10, Amount: &fiveEUR},
+		{Code: "X", DiscountType: entities.PromoCodeFixedAmount},
+		{Code: "X", DiscountType: entities.PromoCodeFixedAmount, Amount: &fiveEUR, MaxRedemptions: &zero},
+	} {
+		assert.ErrorIs(t, invalid.Validate(), entities.ErrInvalidPromoCode, "%+v", invalid)
+	}
+}
@@ -13,8 +13,10 @@ type IssueReceiptResponse struct {
 	IssuedAt      time.Time
 }
 
+// VoidReceipt voids the ticket's receipt because of a refund of Amount, nil Amount is a full refund.
 type VoidReceipt struct {
 	TicketID       string
-	Reason         string
+	Amount         *Money
+	Reason         RefundReason
 	IdempotencyKey string
 }
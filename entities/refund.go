@@ -0,0 +1,53 @@
+package entities
+
+import "fmt"
+
+type RefundReason string
+
+const (
+	RefundReasonCustomerRequested RefundReason = "customer_requested"
+	RefundReasonShowCanceled      RefundReason = "show_canceled"
+	RefundReasonBookingCanceled   RefundReason = "booking_canceled"
+	RefundReasonDuplicatePayment  RefundReason = "duplicate_payment"
+	RefundReasonPriceAdjustment   RefundReason = "price_adjustment"
+	RefundReasonGoodwill          RefundReason = "goodwill"
+)
+
+var refundReasonDescriptions = map[RefundReason]string{
+	RefundReasonCustomerRequested: "customer requested refund",
+	RefundReasonShowCanceled:      "show canceled",
+	RefundReasonBookingCanceled:   "booking canceled",
+	RefundReasonDuplicatePayment:  "duplicate payment",
+	RefundReasonPriceAdjustment:   "price adjustment",
+	RefundReasonGoodwill:          "goodwill refund",
+}
+
+func (r RefundReason) IsValid() bool {
+	_, ok := refundReasonDescriptions[r]
+	return ok
+}
+
+// Description is the reason for people, it's sent to the payments and receipts APIs.
+func (r RefundReason) Description() string {
+	if description, ok := refundReasonDescriptions[r]; ok {
+		return description
+	}
+	return string(r)
+}
+
+// RefundPaymentRequest refunds Amount of the ticket's payment, nil Amount refunds the full price.
+type RefundPaymentRequest struct {
+	TicketID       string
+	Amount         *Money
+	Reason         RefundReason
+	IdempotencyKey string
+}
+
+// RefundDescription describes the refund for the external APIs, which don't have fields for the amount and reason code.
+func RefundDescription(reason RefundReason, amount *Money) string {
+	if amount == nil {
+		return reason.Description()
+	}
+
+	return fmt.Sprintf("%s (partial refund of %s)", reason.Description(), amount)
+}
@@ -0,0 +1,127 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidRefundPolicy = errors.New("invalid refund policy")
+
+type RefundRejectionCode string
+
+const (
+	RefundRejectionShowStarted         RefundRejectionCode = "show_started"
+	RefundRejectionRefundWindowClosed  RefundRejectionCode = "refund_window_closed"
+	RefundRejectionAmountExceedsPolicy RefundRejectionCode = "amount_exceeds_policy"
+)
+
+// RefundPolicy allows a full refund until FullRefundPeriod before the show starts,
+// a PartialRefundPercentage of the price after that, and no refund once the show started.
+type RefundPolicy struct {
+	FullRefundPeriod        time.Duration
+	PartialRefundPercentage int
+}
+
+func DefaultRefundPolicy() RefundPolicy {
+	return RefundPolicy{
+		FullRefundPeriod:        7 * 24 * time.Hour,
+		PartialRefundPercentage: 50,
+	}
+}
+
+func (p RefundPolicy) Validate() error {
+	if p.FullRefundPeriod < 0 {
+		return fmt.Errorf("%w: full refund period can't be negative", ErrInvalidRefundPolicy)
+	}
+	if p.PartialRefundPercentage < 0 || p.PartialRefundPercentage > 100 {
+		return fmt.Errorf("%w: partial refund percentage must be between 0 and 100", ErrInvalidRefundPolicy)
+	}
+
+	return nil
+}
+
+// RefundRejection explains why the policy doesn't allow the refund, it's returned to the API client as is.
+type RefundRejection struct {
+	Code          RefundRejectionCode `json:"code"`
+	Message       string              `json:"message"`
+	ShowStartTime time.Time           `json:"show_start_time"`
+
+	// MaxRefundAmount is set when a smaller refund is allowed.
+	MaxRefundAmount *Money `json:"max_refund_amount,omitempty"`
+}
+
+// RefundDecision is the result of evaluating the policy.
+// Exactly one of Rejection and Allowed is set, an Allowed refund has Amount set for partial refunds.
+type RefundDecision struct {
+	Allowed   bool
+	Amount    *Money
+	Rejection *RefundRejection
+
+	// MaxRefundedTotal is set in the partial refund window, the ticket's refunds together can't exceed it.
+	// The policy doesn't know what was refunded before, so it's checked when the refund is added.
+	MaxRefundedTotal *Money
+}
+
+// Evaluate decides whether the requested amount of the ticket price can be refunded at the given time.
+// A nil requested amount means the full price, in the partial refund window it's lowered to what's left
+// of MaxRefundedTotal.
+func (p RefundPolicy) Evaluate(ticketPrice Money, requested *Money, showStartTime, at time.Time) RefundDecision {
+	reject := func(code RefundRejectionCode, message string, maxRefundAmount *Money) RefundDecision {
+		return RefundDecision{
+			Rejection: &RefundRejection{
+				Code:            code,
+				Message:         message,
+				ShowStartTime:   showStartTime,
+				MaxRefundAmount: maxRefundAmount,
+			},
+		}
+	}
+
+	if !at.Before(showStartTime) {
+		return reject(RefundRejectionShowStarted, "tickets can't be refunded after the show started", nil)
+	}
+
+	if at.Before(showStartTime.Add(-p.FullRefundPeriod)) {
+		return RefundDecision{Allowed: true, Amount: requested}
+	}
+
+	if p.PartialRefundPercentage == 0 {
+		return reject(
+			RefundRejectionRefundWindowClosed,
+			fmt.Sprintf("tickets can be refunded until %s before the show", formatRefundPeriod(p.FullRefundPeriod)),
+			nil,
+		)
+	}
+
+	if p.PartialRefundPercentage == 100 {
+		return RefundDecision{Allowed: true, Amount: requested}
+	}
+
+	maxRefundAmount := ticketPrice.Percent(p.PartialRefundPercentage)
+
+	if requested == nil {
+		return RefundDecision{Allowed: true, MaxRefundedTotal: &maxRefundAmount}
+	}
+
+	if requested.Currency != maxRefundAmount.Currency || requested.Amount.Cmp(maxRefundAmount.Amount) > 0 {
+		return reject(
+			RefundRejectionAmountExceedsPolicy,
+			fmt.Sprintf(
+				"only %d%% of the ticket price can be refunded within %s before the show",
+				p.PartialRefundPercentage, formatRefundPeriod(p.FullRefundPeriod),
+			),
+			&maxRefundAmount,
+		)
+	}
+
+	return RefundDecision{Allowed: true, Amount: requested, MaxRefundedTotal: &maxRefundAmount}
+}
+
+func formatRefundPeriod(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return fmt.Sprintf("%d days", d/day)
+	}
+	return d.String()
+}
@@ -0,0 +1,121 @@
+package entities_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+func TestRefundPolicy_Evaluate(t *testing.T) {
+	policy := entities.RefundPolicy{
+		FullRefundPeriod:        7 * 24 * time.Hour,
+		PartialRefundPercentage: 50,
+	}
+
+	showStartTime := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+	price := entities.MustNewMoney("30.00", "EUR")
+	ten := entities.MustNewMoney("10.00", "EUR")
+	twenty := entities.MustNewMoney("20.00", "EUR")
+
+	testCases := []struct {
+		Name              string
+		Requested         *entities.Money
+		At                time.Time
+		ExpectedAmount    string
+		ExpectedRejection entities.RefundRejectionCode
+
+		// ExpectedMaxRefundedTotal is empty when the refunds aren't limited
+		ExpectedMaxRefundedTotal string
+	}{
+		{
+			Name:           "full_refund_before_period",
+			At:             showStartTime.Add(-8 * 24 * time.Hour),
+			ExpectedAmount: "full",
+		},
+		{
+			Name:           "requested_amount_before_period",
+			Requested:      &twenty,
+			At:             showStartTime.Add(-8 * 24 * time.Hour),
+			ExpectedAmount: "20.00 EUR",
+		},
+		{
+			Name:                     "partial_refund_within_period",
+			At:                       showStartTime.Add(-7 * 24 * time.Hour),
+			ExpectedAmount:           "full",
+			ExpectedMaxRefundedTotal: "15.00 EUR",
+		},
+		{
+			Name:                     "requested_amount_within_partial_refund",
+			Requested:                &ten,
+			At:                       showStartTime.Add(-time.Hour),
+			ExpectedAmount:           "10.00 EUR",
+			ExpectedMaxRefundedTotal: "15.00 EUR",
+		},
+		{
+			Name:              "requested_amount_above_partial_refund",
+			Requested:         &twenty,
+			At:                showStartTime.Add(-time.Hour),
+			ExpectedRejection: entities.RefundRejectionAmountExceedsPolicy,
+		},
+		{
+			Name:              "show_started",
+			At:                showStartTime,
+			ExpectedRejection: entities.RefundRejectionShowStarted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			decision := policy.Evaluate(price, tc.Requested, showStartTime, tc.At)
+
+			if tc.ExpectedRejection != "" {
+				assert.False(t, decision.Allowed)
+				require.NotNil(t, decision.Rejection)
+				assert.Equal(t, tc.ExpectedRejection, decision.Rejection.Code)
+				return
+			}
+
+			require.True(t, decision.Allowed)
+			assert.Nil(t, decision.Rejection)
+			if tc.ExpectedAmount == "full" {
+				assert.Nil(t, decision.Amount)
+			} else {
+				require.NotNil(t, decision.Amount)
+				assert.Equal(t, tc.ExpectedAmount, decision.Amount.String())
+			}
+			if tc.ExpectedMaxRefundedTotal == "" {
+				assert.Nil(t, decision.MaxRefundedTotal)
+			} else {
+				require.NotNil(t, decision.MaxRefundedTotal)
+				assert.Equal(t, tc.ExpectedMaxRefundedTotal, decision.MaxRefundedTotal.String())
+			}
+		})
+	}
+}
+
+func TestRefundPolicy_Evaluate_rejection_has_max_refund_amount(t *testing.T) {
+	policy := entities.RefundPolicy{FullRefundPeriod: 48 * time.Hour, PartialRefundPercentage: 25}
+	showStartTime := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+	requested := entities.MustNewMoney("10.00", "EUR")
+
+	decision := policy.Evaluate(entities.MustNewMoney("19.99", "EUR"), &requested, showStartTime, showStartTime.Add(-time.Hour))
+
+	require.NotNil(t, decision.Rejection)
+	require.NotNil(t, decision.Rejection.MaxRefundAmount)
+	assert.Equal(t, "5.00 EUR", decision.Rejection.MaxRefundAmount.String())
+	assert.Contains(t, decision.Rejection.Message, "2 days")
+}
+
+func TestRefundPolicy_Evaluate_no_partial_refunds(t *testing.T) {
+	policy := entities.RefundPolicy{FullRefundPeriod: 24 * time.Hour}
+	showStartTime := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+
+	decision := policy.Evaluate(entities.MustNewMoney("30.00", "EUR"), nil, showStartTime, showStartTime.Add(-time.Hour))
+
+	require.NotNil(t, decision.Rejection)
+	assert.Equal(t, entities.RefundRejectionRefundWindowClosed, decision.Rejection.Code)
+}
@@ -0,0 +1,168 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type RevenueReport struct {
+	Currency string     `json:"currency"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+
+	TicketsSold     int   `json:"tickets_sold"`
+	TicketsRefunded int   `json:"tickets_refunded"`
+	Gross           Money `json:"gross"`
+	Refunded        Money `json:"refunded"`
+	Net             Money `json:"net"`
+
+	ByCurrency []CurrencyRevenue `json:"by_currency"`
+}
+
+// CurrencyRevenue is the part of the report sold in one currency, in that currency.
+type CurrencyRevenue struct {
+	Currency        string `json:"currency"`
+	TicketsSold     int    `json:"tickets_sold"`
+	TicketsRefunded int    `json:"tickets_refunded"`
+	Gross           Money  `json:"gross"`
+	Refunded        Money  `json:"refunded"`
+
+	// GrossConverted and RefundedConverted are in the reporting currency.
+	GrossConverted    Money `json:"gross_converted"`
+	RefundedConverted Money `json:"refunded_converted"`
+}
+
+// NewRevenueReport sums ticket prices confirmed and amounts refunded within [from, to) in the reporting currency.
+// Each price is converted at the rate effective when the ticket was confirmed, refunds included,
+// so a refund always reverses the amount that was counted for the sale.
+// Zero from or to leaves that side of the period open.
+func NewRevenueReport(
+	bookings []OpsBooking,
+	rates ExchangeRates,
+	currency string,
+	from, to time.Time,
+) (RevenueReport, error) {
+	zero := Money{Amount: NewDecimalFromInt(0), Currency: currency}.Round()
+
+	report := RevenueReport{
+		Currency: currency,
+		Gross:    zero,
+		Refunded: zero,
+	}
+	if !from.IsZero() {
+		report.From = &from
+	}
+	if !to.IsZero() {
+		report.To = &to
+	}
+
+	inPeriod := func(t time.Time) bool {
+		if t.IsZero() {
+			return false
+		}
+		return !t.Before(from) && (to.IsZero() || t.Before(to))
+	}
+
+	byCurrency := map[string]*CurrencyRevenue{}
+
+	for _, booking := range bookings {
+		for ticketID, ticket := range booking.Tickets {
+			sold := inPeriod(ticket.ConfirmedAt)
+			refunded := inPeriod(ticket.RefundedAt)
+			if !sold && !refunded {
+				continue
+			}
+
+			amount, err := ParseDecimal(ticket.PriceAmount)
+			if err != nil {
+				return RevenueReport{}, fmt.Errorf("invalid price of ticket %s: %w", ticketID, err)
+			}
+			price := Money{Amount: amount, Currency: ticket.PriceCurrency}
+
+			converted, err := rates.Convert(price, currency, ticket.ConfirmedAt)
+			if err != nil {
+				return RevenueReport{}, fmt.Errorf("could not convert price of ticket %s: %w", ticketID, err)
+			}
+
+			currencyRevenue, ok := byCurrency[price.Currency]
+			if !ok {
+				currencyZero := Money{Amount: NewDecimalFromInt(0), Currency: price.Currency}.Round()
+				currencyRevenue = &CurrencyRevenue{
+					Currency:          price.Currency,
+					Gross:             currencyZero,
+					Refunded:          currencyZero,
+					GrossConverted:    zero,
+					RefundedConverted: zero,
+				}
+				byCurrency[price.Currency] = currencyRevenue
+			}
+
+			if sold {
+				report.TicketsSold++
+				currencyRevenue.TicketsSold++
+				if err := errors.Join(
+					addTo(&report.Gross, converted),
+					addTo(&currencyRevenue.Gross, price),
+					addTo(&currencyRevenue.GrossConverted, converted),
+				); err != nil {
+					return RevenueReport{}, fmt.Errorf("could not add price of ticket %s: %w", ticketID, err)
+				}
+			}
+			if refunded {
+				refundedPrice, convertedRefund := price, converted
+				if ticket.RefundedAmount != "" {
+					// less than the price after partial refunds
+					refundedAmount, err := ParseDecimal(ticket.RefundedAmount)
+					if err != nil {
+						return RevenueReport{}, fmt.Errorf("invalid refunded amount of ticket %s: %w", ticketID, err)
+					}
+					refundedPrice = Money{Amount: refundedAmount, Currency: price.Currency}
+
+					convertedRefund, err = rates.Convert(refundedPrice, currency, ticket.ConfirmedAt)
+					if err != nil {
+						return RevenueReport{}, fmt.Errorf("could not convert refund of ticket %s: %w", ticketID, err)
+					}
+				}
+
+				report.TicketsRefunded++
+				currencyRevenue.TicketsRefunded++
+				if err := errors.Join(
+					addTo(&report.Refunded, convertedRefund),
+					addTo(&currencyRevenue.Refunded, refundedPrice),
+					addTo(&currencyRevenue.RefundedConverted, convertedRefund),
+				); err != nil {
+					return RevenueReport{}, fmt.Errorf("could not add refund of ticket %s: %w", ticketID, err)
+				}
+			}
+		}
+	}
+
+	net, err := report.Gross.Sub(report.Refunded)
+	if err != nil {
+		return RevenueReport{}, err
+	}
+	report.Net = net
+
+	report.ByCurrency = make([]CurrencyRevenue, 0, len(byCurrency))
+	for _, currencyRevenue := range byCurrency {
+		report.ByCurrency = append(report.ByCurrency, *currencyRevenue)
+	}
+	sort.Slice(report.ByCurrency, func(i, j int) bool {
+		return report.ByCurrency[i].Currency < report.ByCurrency[j].Currency
+	})
+
+	return report, nil
+}
+
+// addTo adds the amount to the sum, it fails when the sum overflows or a ticket has an unexpected currency.
+func addTo(sum *Money, amount Money) error {
+	result, err := sum.Add(amount)
+	if err != nil {
+		return err
+	}
+
+	*sum = result
+	return nil
+}
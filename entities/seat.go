@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidSeatMap = errors.New("invalid seat map")
+
+type Seat struct {
+	Section string `json:"section" db:"seat_section"`
+	Row     string `json:"row" db:"seat_row"`
+	Number  int    `json:"number" db:"seat_number"`
+}
+
+func (s Seat) String() string {
+	return fmt.Sprintf("Section %s, Row %s, Seat %d", s.Section, s.Row, s.Number)
+}
+
+type ShowSeat struct {
+	Seat
+	Available bool `json:"available" db:"available"`
+}
+
+// SeatMap describes reserved seating of the show. Sections and rows are listed from the best to the worst,
+// which is the order used when seats are allocated as "best available".
+type SeatMap struct {
+	Sections []SeatMapSection `json:"sections"`
+}
+
+type SeatMapSection struct {
+	Name string       `json:"name"`
+	Rows []SeatMapRow `json:"rows"`
+}
+
+type SeatMapRow struct {
+	Name  string `json:"name"`
+	Seats int    `json:"seats"`
+}
+
+// Validate checks that the map has seats and that a seat is identified by its section, row and number,
+// so section names are unique in the map and row names in the section.
+func (m SeatMap) Validate() error {
+	if len(m.Sections) == 0 {
+		return fmt.Errorf("%w: no sections", ErrInvalidSeatMap)
+	}
+
+	sections := map[string]struct{}{}
+	for _, section := range m.Sections {
+		if strings.TrimSpace(section.Name) == "" {
+			return fmt.Errorf("%w: section name is required", ErrInvalidSeatMap)
+		}
+		if _, ok := sections[section.Name]; ok {
+			return fmt.Errorf("%w: duplicated section %s", ErrInvalidSeatMap, section.Name)
+		}
+		sections[section.Name] = struct{}{}
+
+		if len(section.Rows) == 0 {
+			return fmt.Errorf("%w: section %s has no rows", ErrInvalidSeatMap, section.Name)
+		}
+
+		rows := map[string]struct{}{}
+		for _, row := range section.Rows {
+			if strings.TrimSpace(row.Name) == "" {
+				return fmt.Errorf("%w: row name in section %s is required", ErrInvalidSeatMap, section.Name)
+			}
+			if _, ok := rows[row.Name]; ok {
+				return fmt.Errorf("%w: duplicated row %s in section %s", ErrInvalidSeatMap, row.Name, section.Name)
+			}
+			rows[row.Name] = struct{}{}
+
+			if row.Seats <= 0 {
+				return fmt.Errorf("%w: row %s in section %s must have seats", ErrInvalidSeatMap, row.Name, section.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m SeatMap) Seats() []Seat {
+	var seats []Seat
+	for _, section := range m.Sections {
+		for _, row := range section.Rows {
+			for number := 1; number <= row.Seats; number++ {
+				seats = append(seats, Seat{
+					Section: section.Name,
+					Row:     row.Name,
+					Number:  number,
+				})
+			}
+		}
+	}
+
+	return seats
+}
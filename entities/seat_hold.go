@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SeatHold struct {
+	HoldID          uuid.UUID `json:"hold_id" db:"hold_id"`
+	ShowID          uuid.UUID `json:"show_id" db:"show_id"`
+	NumberOfTickets int       `json:"number_of_tickets" db:"number_of_tickets"`
+	CustomerEmail   string    `json:"customer_email" db:"customer_email"`
+	ExpiresAt       time.Time `json:"expires_at" db:"expires_at"`
+
+	// PriceCategory is required for shows with price categories. Holds offered from the waitlist
+	// get the category when they are confirmed.
+	PriceCategory string `json:"price_category,omitempty" db:"price_category"`
+
+	// Seats requested by the customer. When empty, the best available seats are held for shows with a seat map.
+	Seats []Seat `json:"seats,omitempty" db:"-"`
+}
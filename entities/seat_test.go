@@ -0,0 +1,81 @@
+package entities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickets/entities"
+)
+
+func TestSeatMap_Validate(t *testing.T) {
+	row := func(name string, seats int) entities.SeatMapRow {
+		return entities.SeatMapRow{Name: name, Seats: seats}
+	}
+
+	testCases := []struct {
+		Name          string
+		Sections      []entities.SeatMapSection
+		ExpectedValid bool
+	}{
+		{
+			Name: "valid",
+			Sections: []entities.SeatMapSection{
+				{Name: "Floor", Rows: []entities.SeatMapRow{row("A", 10), row("B", 12)}},
+				{Name: "Balcony", Rows: []entities.SeatMapRow{row("A", 8)}},
+			},
+			ExpectedValid: true,
+		},
+		{
+			Name: "no_sections",
+		},
+		{
+			Name: "duplicated_section",
+			Sections: []entities.SeatMapSection{
+				{Name: "Floor", Rows: []entities.SeatMapRow{row("A", 10)}},
+				{Name: "Floor", Rows: []entities.SeatMapRow{row("B", 10)}},
+			},
+		},
+		{
+			Name: "empty_section_name",
+			Sections: []entities.SeatMapSection{
+				{Name: " ", Rows: []entities.SeatMapRow{row("A", 10)}},
+			},
+		},
+		{
+			Name: "section_without_rows",
+			Sections: []entities.SeatMapSection{
+				{Name: "Floor"},
+			},
+		},
+		{
+			Name: "duplicated_row",
+			Sections: []entities.SeatMapSection{
+				{Name: "Floor", Rows: []entities.SeatMapRow{row("A", 10), row("A", 10)}},
+			},
+		},
+		{
+			Name: "empty_row_name",
+			Sections: []entities.SeatMapSection{
+				{Name: "Floor", Rows: []entities.SeatMapRow{row("", 10)}},
+			},
+		},
+		{
+			Name: "row_without_seats",
+			Sections: []entities.SeatMapSection{
+				{Name: "Floor", Rows: []entities.SeatMapRow{row("A", 0)}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := entities.SeatMap{Sections: tc.Sections}.Validate()
+			if tc.ExpectedValid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, entities.ErrInvalidSeatMap)
+			}
+		})
+	}
+}
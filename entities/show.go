@@ -13,4 +13,15 @@ type Show struct {
 	StartTime       time.Time `json:"start_time" db:"start_time"`
 	Title           string    `json:"title" db:"title"`
 	Venue           string    `json:"venue" db:"venue"`
+
+	// MaxTicketsPerCustomer is optional, it limits how many tickets one customer email can buy.
+	MaxTicketsPerCustomer *int `json:"max_tickets_per_customer,omitempty" db:"max_tickets_per_customer"`
+
+	CanceledAt *time.Time `json:"canceled_at,omitempty" db:"canceled_at"`
+
+	SeatMap *SeatMap `json:"seat_map,omitempty" db:"-"`
+
+	PriceCategories []PriceCategory `json:"price_categories,omitempty" db:"-"`
+
+	Availability *ShowAvailability `json:"availability,omitempty" db:"-"`
 }
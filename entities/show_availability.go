@@ -0,0 +1,32 @@
+package entities
+
+import "github.com/google/uuid"
+
+type ShowAvailability struct {
+	ShowID    uuid.UUID `json:"show_id" db:"show_id"`
+	Capacity  int       `json:"capacity" db:"capacity"`
+	Booked    int       `json:"booked" db:"booked"`
+	Held      int       `json:"held" db:"held"`
+	Refunded  int       `json:"refunded" db:"refunded"`
+	Available int       `json:"available" db:"available"`
+
+	// CheckedIn is the number of tickets scanned at the venue.
+	CheckedIn int `json:"checked_in" db:"checked_in"`
+}
+
+// ShowAttendance is the live attendance of a show for ops, tickets are checked in at the venue.
+type ShowAttendance struct {
+	ShowID       uuid.UUID `json:"show_id"`
+	Booked       int       `json:"booked"`
+	CheckedIn    int       `json:"checked_in"`
+	NotCheckedIn int       `json:"not_checked_in"`
+}
+
+func NewShowAttendance(availability ShowAvailability) ShowAttendance {
+	return ShowAttendance{
+		ShowID:       availability.ShowID,
+		Booked:       availability.Booked,
+		CheckedIn:    availability.CheckedIn,
+		NotCheckedIn: max(availability.Booked-availability.CheckedIn, 0),
+	}
+}
@@ -1,7 +1,97 @@
 package entities
 
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTicketAlreadyRefunded = errors.New("ticket is already refunded")
+	ErrRefundExceedsPrice    = errors.New("refund exceeds the rest of the ticket price")
+	ErrRefundExceedsPolicy   = errors.New("refund exceeds the part of the ticket price the refund policy allows")
+)
+
 type Ticket struct {
 	TicketID      string `json:"ticket_id" db:"ticket_id"`
 	Price         Money  `json:"price" db:"price"`
 	CustomerEmail string `json:"customer_email" db:"customer_email"`
+	BookingID     string `json:"booking_id" db:"booking_id"`
+	// Revision is bumped on every transfer of the ticket.
+	Revision int `json:"revision" db:"revision"`
+}
+
+// TicketRefund is the part of the ticket price to refund, the refunded total of a ticket never exceeds its price.
+type TicketRefund struct {
+	// Amount is nil when the full price is refunded at once.
+	Amount *Money
+	// FullyRefunded is set when nothing of the price is left after the refund, so the ticket is no longer valid.
+	FullyRefunded bool
+}
+
+// NewTicketRefund refunds the amount from what's left of the price after the refunded total,
+// a nil amount refunds all that's left. A non-nil limit caps the refunded total including this refund,
+// a nil amount is then lowered to what's left below the limit.
+func NewTicketRefund(price, refunded Money, amount, limit *Money) (TicketRefund, error) {
+	left, err := price.Sub(refunded)
+	if err != nil {
+		return TicketRefund{}, err
+	}
+	if left.Amount.Sign() <= 0 {
+		return TicketRefund{}, ErrTicketAlreadyRefunded
+	}
+
+	allowed := left
+	if limit != nil {
+		leftOfLimit, err := limit.Sub(refunded)
+		if err != nil {
+			return TicketRefund{}, err
+		}
+		if leftOfLimit.Amount.Sign() <= 0 {
+			return TicketRefund{}, fmt.Errorf("%w: %s of %s is already refunded", ErrRefundExceedsPolicy, refunded, *limit)
+		}
+		if leftOfLimit.Amount.Cmp(allowed.Amount) < 0 {
+			allowed = leftOfLimit
+		}
+	}
+
+	refund := allowed
+	if amount != nil {
+		if amount.Currency != price.Currency || amount.Amount.Cmp(left.Amount) > 0 {
+			return TicketRefund{}, fmt.Errorf("%w: %s is left of %s", ErrRefundExceedsPrice, left, price)
+		}
+		if amount.Amount.Cmp(allowed.Amount) > 0 {
+			return TicketRefund{}, fmt.Errorf("%w: %s more can be refunded", ErrRefundExceedsPolicy, allowed)
+		}
+		refund = *amount
+	}
+
+	fullyRefunded := refund.Amount.Cmp(left.Amount) == 0
+	if fullyRefunded && refunded.Amount.IsZero() {
+		return TicketRefund{FullyRefunded: true}, nil
+	}
+
+	return TicketRefund{Amount: &refund, FullyRefunded: fullyRefunded}, nil
+}
+
+// TicketFileName is the name of the printed HTML ticket file. Every transfer prints a new revision of the file,
+// so the file of the previous holder is never overwritten.
+func TicketFileName(ticketID string, revision int) string {
+	return ticketFileBaseName(ticketID, revision) + ".html"
+}
+
+// TicketPDFFileName is the name of the PDF version of the ticket, it's printed next to the HTML file.
+func TicketPDFFileName(ticketID string, revision int) string {
+	return ticketFileBaseName(ticketID, revision) + ".pdf"
+}
+
+// TicketFileNames returns the names of all files printed for the revision of the ticket.
+func TicketFileNames(ticketID string, revision int) []string {
+	return []string{TicketFileName(ticketID, revision), TicketPDFFileName(ticketID, revision)}
+}
+
+func ticketFileBaseName(ticketID string, revision int) string {
+	if revision == 0 {
+		return ticketID + "-ticket"
+	}
+	return fmt.Sprintf("%s-ticket-r%d", ticketID, revision)
 }
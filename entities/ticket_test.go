@@ -0,0 +1,123 @@
+package entities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+func TestNewTicketRefund(t *testing.T) {
+	price := entities.MustNewMoney("30.00", "EUR")
+	money := func(amount string) *entities.Money {
+		m := entities.MustNewMoney(amount, "EUR")
+		return &m
+	}
+
+	testCases := []struct {
+		Name                  string
+		Refunded              string
+		Amount                *entities.Money
+		ExpectedAmount        string
+		ExpectedFullyRefunded bool
+	}{
+		{
+			Name:                  "full_price",
+			Refunded:              "0",
+			ExpectedFullyRefunded: true,
+		},
+		{
+			Name:                  "requested_full_price",
+			Refunded:              "0",
+			Amount:                money("30"),
+			ExpectedFullyRefunded: true,
+		},
+		{
+			Name:           "partial",
+			Refunded:       "0",
+			Amount:         money("10.00"),
+			ExpectedAmount: "10.00 EUR",
+		},
+		{
+			Name:                  "rest_after_partial_refunds",
+			Refunded:              "25.50",
+			ExpectedAmount:        "4.50 EUR",
+			ExpectedFullyRefunded: true,
+		},
+		{
+			Name:                  "partial_refunds_up_to_the_price",
+			Refunded:              "20.00",
+			Amount:                money("10.00"),
+			ExpectedAmount:        "10.00 EUR",
+			ExpectedFullyRefunded: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			refund, err := entities.NewTicketRefund(price, entities.MustNewMoney(tc.Refunded, "EUR"), tc.Amount, nil)
+			require.NoError(t, err)
+
+			if tc.ExpectedAmount == "" {
+				assert.Nil(t, refund.Amount)
+			} else {
+				require.NotNil(t, refund.Amount)
+				assert.Equal(t, tc.ExpectedAmount, refund.Amount.String())
+			}
+			assert.Equal(t, tc.ExpectedFullyRefunded, refund.FullyRefunded)
+		})
+	}
+}
+
+func TestNewTicketRefund_exceeds_price(t *testing.T) {
+	price := entities.MustNewMoney("30.00", "EUR")
+
+	amount := entities.MustNewMoney("10.01", "EUR")
+	_, err := entities.NewTicketRefund(price, entities.MustNewMoney("20.00", "EUR"), &amount, nil)
+	assert.ErrorIs(t, err, entities.ErrRefundExceedsPrice)
+
+	amount = entities.MustNewMoney("10.00", "USD")
+	_, err = entities.NewTicketRefund(price, entities.MustNewMoney("0", "EUR"), &amount, nil)
+	assert.ErrorIs(t, err, entities.ErrRefundExceedsPrice)
+
+	_, err = entities.NewTicketRefund(price, entities.MustNewMoney("30.00", "EUR"), nil, nil)
+	assert.ErrorIs(t, err, entities.ErrTicketAlreadyRefunded)
+}
+
+func TestNewTicketRefund_limit(t *testing.T) {
+	price := entities.MustNewMoney("30.00", "EUR")
+	limit := entities.MustNewMoney("15.00", "EUR")
+	zero := entities.MustNewMoney("0", "EUR")
+
+	refund, err := entities.NewTicketRefund(price, zero, nil, &limit)
+	require.NoError(t, err)
+	require.NotNil(t, refund.Amount)
+	assert.Equal(t, "15.00 EUR", refund.Amount.String(), "a refund without amount is lowered to the limit")
+	assert.False(t, refund.FullyRefunded)
+
+	// two partial refunds within the limit each, but not together
+	amount := entities.MustNewMoney("15.00", "EUR")
+	refund, err = entities.NewTicketRefund(price, zero, &amount, &limit)
+	require.NoError(t, err)
+	require.NotNil(t, refund.Amount)
+	assert.Equal(t, "15.00 EUR", refund.Amount.String())
+
+	_, err = entities.NewTicketRefund(price, *refund.Amount, &amount, &limit)
+	assert.ErrorIs(t, err, entities.ErrRefundExceedsPolicy)
+
+	_, err = entities.NewTicketRefund(price, *refund.Amount, nil, &limit)
+	assert.ErrorIs(t, err, entities.ErrRefundExceedsPolicy)
+
+	amount = entities.MustNewMoney("5.00", "EUR")
+	_, err = entities.NewTicketRefund(price, entities.MustNewMoney("12.00", "EUR"), &amount, &limit)
+	assert.ErrorIs(t, err, entities.ErrRefundExceedsPolicy)
+
+	// without the limit, e.g. when the show is canceled, the rest of the price is refunded
+	refund, err = entities.NewTicketRefund(price, limit, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, refund.Amount)
+	assert.Equal(t, "15.00 EUR", refund.Amount.String())
+	assert.True(t, refund.FullyRefunded)
+}
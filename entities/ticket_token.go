@@ -0,0 +1,180 @@
+package entities
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTicketToken      = errors.New("invalid ticket token")
+	ErrInvalidTicketSigningKey = errors.New("invalid ticket signing key")
+)
+
+// TicketTokenVersion prefixes signed ticket tokens, so the format can change without breaking printed tickets.
+const TicketTokenVersion = "v1"
+
+const (
+	minTicketSigningKeyLength = 32
+
+	// maxTicketSigningKeyIDLength keeps tokens of UUID tickets short enough for a QR code
+	maxTicketSigningKeyIDLength = 16
+)
+
+// TicketToken is printed on tickets as a QR code, door staff verify it at check-in.
+// Revision is the ticket revision at the time of printing, tickets printed before a transfer are outdated.
+type TicketToken struct {
+	TicketID string    `json:"ticket_id"`
+	ShowID   string    `json:"show_id,omitempty"`
+	Revision int       `json:"revision"`
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// TicketSigningKey is a HMAC key, its ID is part of the token so keys can be rotated.
+type TicketSigningKey struct {
+	ID     string
+	Secret []byte
+}
+
+func (k TicketSigningKey) Validate() error {
+	if k.ID == "" || len(k.ID) > maxTicketSigningKeyIDLength || strings.ContainsAny(k.ID, ".:,") {
+		return fmt.Errorf(
+			"%w: key ID must have 1 to %d characters and can't contain '.', ':' or ','",
+			ErrInvalidTicketSigningKey, maxTicketSigningKeyIDLength,
+		)
+	}
+	if len(k.Secret) < minTicketSigningKeyLength {
+		return fmt.Errorf("%w: key %s must be at least %d bytes", ErrInvalidTicketSigningKey, k.ID, minTicketSigningKeyLength)
+	}
+
+	return nil
+}
+
+// TicketSigner signs tokens with the first key, tokens signed with any of the keys are verified.
+// Rotating a key means adding a new key in front and removing the old one after tickets signed with it are used.
+type TicketSigner struct {
+	keys []TicketSigningKey
+}
+
+func NewTicketSigner(keys []TicketSigningKey) (TicketSigner, error) {
+	if len(keys) == 0 {
+		return TicketSigner{}, fmt.Errorf("%w: at least one key is required", ErrInvalidTicketSigningKey)
+	}
+
+	ids := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if err := key.Validate(); err != nil {
+			return TicketSigner{}, err
+		}
+		if _, ok := ids[key.ID]; ok {
+			return TicketSigner{}, fmt.Errorf("%w: duplicate key ID %s", ErrInvalidTicketSigningKey, key.ID)
+		}
+		ids[key.ID] = struct{}{}
+	}
+
+	return TicketSigner{keys: keys}, nil
+}
+
+// Sign returns the token as "v1.<key ID>.<payload>.<signature>", payload and signature are base64url encoded.
+func (s TicketSigner) Sign(token TicketToken) (string, error) {
+	if token.TicketID == "" || strings.Contains(token.TicketID, "\n") || strings.Contains(token.ShowID, "\n") {
+		return "", fmt.Errorf("%w: invalid ticket or show ID", ErrInvalidTicketToken)
+	}
+
+	key := s.keys[0]
+
+	payload := strings.Join([]string{
+		token.TicketID,
+		token.ShowID,
+		strconv.Itoa(token.Revision),
+		strconv.FormatInt(token.IssuedAt.Unix(), 10),
+	}, "\n")
+
+	signed := TicketTokenVersion + "." + key.ID + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(ticketTokenMAC(key, signed)), nil
+}
+
+// Verify checks the signature of the token and returns its content.
+func (s TicketSigner) Verify(signedToken string) (TicketToken, error) {
+	parts := strings.Split(signedToken, ".")
+	if len(parts) != 4 {
+		return TicketToken{}, fmt.Errorf("%w: malformed token", ErrInvalidTicketToken)
+	}
+	if parts[0] != TicketTokenVersion {
+		return TicketToken{}, fmt.Errorf("%w: unsupported version %q", ErrInvalidTicketToken, parts[0])
+	}
+
+	key, ok := s.key(parts[1])
+	if !ok {
+		return TicketToken{}, fmt.Errorf("%w: unknown key %q", ErrInvalidTicketToken, parts[1])
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return TicketToken{}, fmt.Errorf("%w: malformed signature", ErrInvalidTicketToken)
+	}
+	if !hmac.Equal(signature, ticketTokenMAC(key, strings.Join(parts[:3], "."))) {
+		return TicketToken{}, fmt.Errorf("%w: signature mismatch", ErrInvalidTicketToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return TicketToken{}, fmt.Errorf("%w: malformed payload", ErrInvalidTicketToken)
+	}
+
+	fields := strings.Split(string(payload), "\n")
+	if len(fields) != 4 {
+		return TicketToken{}, fmt.Errorf("%w: malformed payload", ErrInvalidTicketToken)
+	}
+
+	revision, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return TicketToken{}, fmt.Errorf("%w: malformed revision", ErrInvalidTicketToken)
+	}
+	issuedAt, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return TicketToken{}, fmt.Errorf("%w: malformed issue time", ErrInvalidTicketToken)
+	}
+
+	return TicketToken{
+		TicketID: fields[0],
+		ShowID:   fields[1],
+		Revision: revision,
+		IssuedAt: time.Unix(issuedAt, 0).UTC(),
+	}, nil
+}
+
+func (s TicketSigner) key(id string) (TicketSigningKey, bool) {
+	for _, key := range s.keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return TicketSigningKey{}, false
+}
+
+func ticketTokenMAC(key TicketSigningKey, signed string) []byte {
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+// ParseTicketSigningKeys parses comma separated "<key ID>:<secret>" pairs, the first key signs new tokens.
+func ParseTicketSigningKeys(s string) ([]TicketSigningKey, error) {
+	var keys []TicketSigningKey
+	for _, pair := range strings.Split(s, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: expected <key ID>:<secret>", ErrInvalidTicketSigningKey)
+		}
+		keys = append(keys, TicketSigningKey{ID: id, Secret: []byte(secret)})
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,100 @@
+package entities_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+var (
+	oldSigningKey = entities.TicketSigningKey{ID: "2024-01", Secret: []byte("old-secret-old-secret-old-secret")}
+	newSigningKey = entities.TicketSigningKey{ID: "2024-06", Secret: []byte("new-secret-new-secret-new-secret")}
+)
+
+func TestTicketSigner(t *testing.T) {
+	signer, err := entities.NewTicketSigner([]entities.TicketSigningKey{oldSigningKey})
+	require.NoError(t, err)
+
+	token := entities.TicketToken{
+		TicketID: "5f1e9b9a-3f2c-4a8e-9d3b-1c2d3e4f5a6b",
+		ShowID:   "0c9f5e1a-7b2d-4e3f-8a9b-0c1d2e3f4a5b",
+		Revision: 1,
+		IssuedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	signed, err := signer.Sign(token)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "v1.2024-01."))
+
+	verified, err := signer.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, token, verified)
+
+	t.Run("tampered", func(t *testing.T) {
+		parts := strings.Split(signed, ".")
+		otherSigned, err := signer.Sign(entities.TicketToken{TicketID: "other", IssuedAt: token.IssuedAt})
+		require.NoError(t, err)
+		parts[2] = strings.Split(otherSigned, ".")[2]
+
+		_, err = signer.Verify(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, entities.ErrInvalidTicketToken)
+	})
+
+	t.Run("rotated_key", func(t *testing.T) {
+		rotated, err := entities.NewTicketSigner([]entities.TicketSigningKey{newSigningKey, oldSigningKey})
+		require.NoError(t, err)
+
+		_, err = rotated.Verify(signed)
+		assert.NoError(t, err, "tokens signed with the old key are still valid")
+
+		newSigned, err := rotated.Sign(token)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(newSigned, "v1.2024-06."))
+
+		_, err = signer.Verify(newSigned)
+		assert.ErrorIs(t, err, entities.ErrInvalidTicketToken, "the old signer doesn't know the new key")
+	})
+
+	t.Run("unsupported_version", func(t *testing.T) {
+		_, err := signer.Verify("v0" + strings.TrimPrefix(signed, "v1"))
+		assert.ErrorIs(t, err, entities.ErrInvalidTicketToken)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := signer.Verify("not a token")
+		assert.ErrorIs(t, err, entities.ErrInvalidTicketToken)
+	})
+}
+
+func TestNewTicketSigner_invalid_keys(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Keys []entities.TicketSigningKey
+	}{
+		{Name: "no_keys"},
+		{Name: "short_secret", Keys: []entities.TicketSigningKey{{ID: "k1", Secret: []byte("short")}}},
+		{Name: "dot_in_id", Keys: []entities.TicketSigningKey{{ID: "k.1", Secret: oldSigningKey.Secret}}},
+		{Name: "duplicate_id", Keys: []entities.TicketSigningKey{oldSigningKey, oldSigningKey}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := entities.NewTicketSigner(tc.Keys)
+			assert.ErrorIs(t, err, entities.ErrInvalidTicketSigningKey)
+		})
+	}
+}
+
+func TestParseTicketSigningKeys(t *testing.T) {
+	keys, err := entities.ParseTicketSigningKeys("2024-06:new-secret-new-secret-new-secret, 2024-01:old-secret-old-secret-old-secret")
+	require.NoError(t, err)
+	assert.Equal(t, []entities.TicketSigningKey{newSigningKey, oldSigningKey}, keys)
+
+	_, err = entities.ParseTicketSigningKeys("no-secret")
+	assert.ErrorIs(t, err, entities.ErrInvalidTicketSigningKey)
+}
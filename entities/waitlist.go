@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistEntry struct {
+	EntryID         uuid.UUID `json:"entry_id" db:"entry_id"`
+	ShowID          uuid.UUID `json:"show_id" db:"show_id"`
+	NumberOfTickets int       `json:"number_of_tickets" db:"number_of_tickets"`
+	CustomerEmail   string    `json:"customer_email" db:"customer_email"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
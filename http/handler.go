@@ -3,8 +3,10 @@ package http
 import (
 	"context"
 	"tickets/entities"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/google/uuid"
 )
 
 type Handler struct {
@@ -13,20 +15,78 @@ type Handler struct {
 	tickets     TicketRepository
 	shows       ShowRepository
 	bookings    BookingRepository
+	holds       HoldRepository
+	waitlist    WaitlistRepository
 	opsBookings OpsBookingRepository
+
+	showAvailability ShowAvailabilityRepository
+	exchangeRates    ExchangeRateRepository
+	promoCodes       PromoCodeRepository
+
+	refundPolicy entities.RefundPolicy
+	ticketSigner TicketSigner
+
+	deadLetters        DeadLetterRepository
+	deadLetterReplayer DeadLetterReplayer
 }
 
 type ShowRepository interface {
 	AddShow(ctx context.Context, show entities.Show) error
 	FindAll(ctx context.Context) ([]entities.Show, error)
+	CancelShow(ctx context.Context, showID uuid.UUID) error
+	FindSeats(ctx context.Context, showID uuid.UUID) ([]entities.ShowSeat, error)
+	ShowByTicketID(ctx context.Context, ticketID string) (entities.Show, error)
 }
 
 type TicketRepository interface {
 	FindAll(ctx context.Context) ([]entities.Ticket, error)
+	TicketByID(ctx context.Context, ticketID string) (entities.Ticket, error)
+	CheckInTicket(ctx context.Context, token entities.TicketToken, at time.Time) (entities.TicketCheckIn, error)
+	AddRefund(ctx context.Context, ticketID string, idempotencyKey string, amount, maxRefundedTotal *entities.Money) (entities.TicketRefund, error)
+}
+
+type TicketSigner interface {
+	Verify(signedToken string) (entities.TicketToken, error)
 }
 
 type BookingRepository interface {
-	AddBooking(ctx context.Context, booking entities.Booking) error
+	AddBooking(ctx context.Context, booking entities.Booking) (entities.Booking, error)
+	CancelBooking(ctx context.Context, bookingID uuid.UUID) error
+}
+
+type HoldRepository interface {
+	AddHold(ctx context.Context, hold entities.SeatHold) (entities.SeatHold, error)
+	ConfirmHold(ctx context.Context, holdID uuid.UUID, priceCategory string) (entities.Booking, error)
+}
+
+type WaitlistRepository interface {
+	AddEntry(ctx context.Context, entry entities.WaitlistEntry) error
+}
+
+type ShowAvailabilityRepository interface {
+	FindAll(ctx context.Context) ([]entities.ShowAvailability, error)
+	FindByShowID(ctx context.Context, showID uuid.UUID) (entities.ShowAvailability, error)
+}
+
+type ExchangeRateRepository interface {
+	AddRates(ctx context.Context, rates []entities.ExchangeRate) error
+	FindAll(ctx context.Context) ([]entities.ExchangeRate, error)
+}
+
+type PromoCodeRepository interface {
+	AddPromoCode(ctx context.Context, promoCode entities.PromoCode) error
+	FindAll(ctx context.Context) ([]entities.PromoCode, error)
+}
+
+type DeadLetterRepository interface {
+	FindAll(ctx context.Context, handler string) ([]entities.DeadLetter, error)
+	FindByID(ctx context.Context, id uuid.UUID) (entities.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id uuid.UUID, replay func(context.Context, entities.DeadLetter) error) error
+	DiscardDeadLetter(ctx context.Context, id uuid.UUID) error
+}
+
+type DeadLetterReplayer interface {
+	Replay(ctx context.Context, deadLetter entities.DeadLetter) error
 }
 
 type OpsBookingRepository interface {
@@ -15,10 +15,35 @@ type PostBookTicketsRequest struct {
 	ShowID          uuid.UUID `json:"show_id"`
 	NumberOfTickets int       `json:"number_of_tickets"`
 	CustomerEmail   string    `json:"customer_email"`
+
+	// PriceCategory is required for shows with price categories.
+	PriceCategory string `json:"price_category,omitempty"`
+
+	// PromoCode is optional, it discounts the price of the price category.
+	PromoCode string `json:"promo_code,omitempty"`
+
+	// Seats are optional, when not provided the best available seats are booked.
+	Seats []entities.Seat `json:"seats,omitempty"`
 }
 
+// PostBookTicketsResponse is returned for bookings made directly and from confirmed seat holds.
 type PostBookTicketsResponse struct {
-	BookingID uuid.UUID `json:"booking_id"`
+	BookingID     uuid.UUID       `json:"booking_id"`
+	Seats         []entities.Seat `json:"seats,omitempty"`
+	PriceCategory string          `json:"price_category,omitempty"`
+	TicketPrice   *entities.Money `json:"ticket_price,omitempty"`
+
+	Discount *entities.BookingDiscount `json:"discount,omitempty"`
+}
+
+func newPostBookTicketsResponse(booking entities.Booking) PostBookTicketsResponse {
+	return PostBookTicketsResponse{
+		BookingID:     booking.BookingID,
+		Seats:         booking.Seats,
+		PriceCategory: booking.PriceCategory,
+		TicketPrice:   booking.TicketPrice,
+		Discount:      booking.Discount,
+	}
 }
 
 func (h Handler) PostBookTickets(c echo.Context) error {
@@ -28,25 +53,91 @@ func (h Handler) PostBookTickets(c echo.Context) error {
 		return err
 	}
 
-	if request.NumberOfTickets < 1 {
-		return echo.NewHTTPError(http.StatusBadRequest, "number of tickets must be greater than 0")
+	numberOfTickets, err := numberOfTicketsForSeats(request.NumberOfTickets, request.Seats)
+	if err != nil {
+		return err
 	}
 
-	bookingID := uuid.New()
-
 	booking := entities.Booking{
-		BookingID:       bookingID,
+		BookingID:       uuid.New(),
 		ShowID:          request.ShowID,
-		NumberOfTickets: request.NumberOfTickets,
+		NumberOfTickets: numberOfTickets,
 		CustomerEmail:   request.CustomerEmail,
+		PriceCategory:   request.PriceCategory,
+		PromoCode:       entities.NormalizePromoCode(request.PromoCode),
+		Seats:           request.Seats,
+		// retried requests with the same key get the already made booking
+		IdempotencyKey: c.Request().Header.Get("Idempotency-Key"),
 	}
 
-	if err := h.bookings.AddBooking(c.Request().Context(), booking); err != nil {
-		if errors.Is(err, db.ErrNotEnoughSeats) {
-			return echo.NewHTTPError(http.StatusBadRequest, "not enough seats available")
+	booking, err = h.bookings.AddBooking(c.Request().Context(), booking)
+	if err != nil {
+		if err := bookingHTTPError(err); err != nil {
+			return err
 		}
 		return fmt.Errorf("failed to add booking: %w", err)
 	}
 
-	return c.JSON(http.StatusCreated, PostBookTicketsResponse{BookingID: bookingID})
+	return c.JSON(http.StatusCreated, newPostBookTicketsResponse(booking))
+}
+
+func (h Handler) DeleteBooking(c echo.Context) error {
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid booking ID format")
+	}
+
+	if err := h.bookings.CancelBooking(c.Request().Context(), bookingID); err != nil {
+		if errors.Is(err, db.ErrBookingNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "booking not found")
+		}
+		if errors.Is(err, db.ErrBookingAlreadyCanceled) {
+			return echo.NewHTTPError(http.StatusConflict, "booking is already canceled")
+		}
+		return fmt.Errorf("failed to cancel booking: %w", err)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// numberOfTicketsForSeats returns the number of tickets to book, when seats are selected it can be omitted.
+func numberOfTicketsForSeats(numberOfTickets int, seats []entities.Seat) (int, error) {
+	if len(seats) > 0 {
+		if numberOfTickets == 0 {
+			numberOfTickets = len(seats)
+		}
+		if numberOfTickets != len(seats) {
+			return 0, echo.NewHTTPError(http.StatusBadRequest, "number of tickets must match the number of seats")
+		}
+	}
+
+	if numberOfTickets < 1 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "number of tickets must be greater than 0")
+	}
+
+	return numberOfTickets, nil
+}
+
+// bookingHTTPError maps errors of booking seats to HTTP errors, it returns nil for unexpected errors.
+func bookingHTTPError(err error) error {
+	if errors.Is(err, db.ErrNotEnoughSeats) {
+		return echo.NewHTTPError(http.StatusBadRequest, "not enough seats available")
+	}
+	if errors.Is(err, db.ErrShowCanceled) {
+		return echo.NewHTTPError(http.StatusBadRequest, "show is canceled")
+	}
+	if errors.Is(err, db.ErrPurchaseLimitExceeded) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, db.ErrIdempotencyKeyReused) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+	if errors.Is(err, db.ErrSeatNotAvailable) || errors.Is(err, db.ErrShowHasNoSeatMap) ||
+		errors.Is(err, db.ErrPriceCategoryRequired) || errors.Is(err, db.ErrPriceCategoryNotFound) ||
+		errors.Is(err, db.ErrPromoCodeNotFound) || errors.Is(err, db.ErrPromoCodeExhausted) ||
+		errors.Is(err, entities.ErrPromoCodeNotApplicable) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return nil
 }
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"tickets/entities"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PostCheckInRequest struct {
+	Token string `json:"token"`
+}
+
+// PostCheckIn checks in the ticket of a token scanned at the venue, rejections are returned with a reason code.
+func (h Handler) PostCheckIn(c echo.Context) error {
+	var request PostCheckInRequest
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	token, err := h.ticketSigner.Verify(strings.TrimSpace(request.Token))
+	if err != nil {
+		return echo.NewHTTPError(
+			http.StatusUnprocessableEntity,
+			entities.NewCheckInRejection(entities.CheckInRejectionInvalidToken, "the ticket is not genuine"),
+		)
+	}
+
+	checkIn, err := h.tickets.CheckInTicket(c.Request().Context(), token, time.Now())
+
+	var rejection *entities.CheckInRejection
+	if errors.As(err, &rejection) {
+		switch rejection.Code {
+		case entities.CheckInRejectionTicketNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, rejection)
+		case entities.CheckInRejectionAlreadyCheckedIn:
+			return echo.NewHTTPError(http.StatusConflict, rejection)
+		default:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, rejection)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, checkIn)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tickets/db"
+	"tickets/entities"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultHoldMinutes = 10
+	maxHoldMinutes     = 60
+)
+
+type PostHoldsRequest struct {
+	ShowID          uuid.UUID       `json:"show_id"`
+	NumberOfTickets int             `json:"number_of_tickets"`
+	CustomerEmail   string          `json:"customer_email"`
+	Seats           []entities.Seat `json:"seats,omitempty"`
+
+	// PriceCategory is required for shows with price categories.
+	PriceCategory string `json:"price_category,omitempty"`
+
+	// HoldMinutes is optional, by default seats are held for 10 minutes.
+	HoldMinutes int `json:"hold_minutes,omitempty"`
+}
+
+// PostHoldConfirmRequest is optional, the price category is only needed for holds made without one,
+// like waitlist offers.
+type PostHoldConfirmRequest struct {
+	PriceCategory string `json:"price_category,omitempty"`
+}
+
+type PostHoldsResponse struct {
+	HoldID    uuid.UUID       `json:"hold_id"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Seats     []entities.Seat `json:"seats,omitempty"`
+}
+
+func (h Handler) PostHolds(c echo.Context) error {
+	var request PostHoldsRequest
+
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	numberOfTickets, err := numberOfTicketsForSeats(request.NumberOfTickets, request.Seats)
+	if err != nil {
+		return err
+	}
+
+	if request.HoldMinutes == 0 {
+		request.HoldMinutes = defaultHoldMinutes
+	}
+	if request.HoldMinutes < 0 || request.HoldMinutes > maxHoldMinutes {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("hold minutes must be between 1 and %d", maxHoldMinutes))
+	}
+
+	hold := entities.SeatHold{
+		HoldID:          uuid.New(),
+		ShowID:          request.ShowID,
+		NumberOfTickets: numberOfTickets,
+		CustomerEmail:   request.CustomerEmail,
+		ExpiresAt:       time.Now().UTC().Add(time.Duration(request.HoldMinutes) * time.Minute),
+		Seats:           request.Seats,
+		PriceCategory:   request.PriceCategory,
+	}
+
+	hold, err = h.holds.AddHold(c.Request().Context(), hold)
+	if err != nil {
+		if err := bookingHTTPError(err); err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to add seat hold: %w", err)
+	}
+
+	return c.JSON(http.StatusCreated, PostHoldsResponse{
+		HoldID:    hold.HoldID,
+		ExpiresAt: hold.ExpiresAt,
+		Seats:     hold.Seats,
+	})
+}
+
+func (h Handler) PostHoldConfirm(c echo.Context) error {
+	holdID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid hold ID format")
+	}
+
+	var request PostHoldConfirmRequest
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	booking, err := h.holds.ConfirmHold(c.Request().Context(), holdID, request.PriceCategory)
+	if err != nil {
+		if errors.Is(err, db.ErrHoldNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "seat hold not found")
+		}
+		if errors.Is(err, db.ErrHoldExpired) {
+			return echo.NewHTTPError(http.StatusGone, "seat hold expired")
+		}
+		if errors.Is(err, db.ErrHoldPriceCategoryMismatch) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if err := bookingHTTPError(err); err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to confirm seat hold: %w", err)
+	}
+
+	return c.JSON(http.StatusCreated, newPostBookTicketsResponse(booking))
+}
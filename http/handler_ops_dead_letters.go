@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tickets/db"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (h Handler) GetOpsDeadLetters(c echo.Context) error {
+	deadLetters, err := h.deadLetters.FindAll(c.Request().Context(), c.QueryParam("handler"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deadLetters)
+}
+
+func (h Handler) GetOpsDeadLetterByID(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid dead letter ID format")
+	}
+
+	deadLetter, err := h.deadLetters.FindByID(c.Request().Context(), id)
+	if errors.Is(err, db.ErrDeadLetterNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "dead letter not found")
+	} else if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deadLetter)
+}
+
+// PostOpsDeadLetterReplay publishes the message again for the handler which failed to process it,
+// the dead letter is removed. If the handler fails again, the message is dead lettered again.
+func (h Handler) PostOpsDeadLetterReplay(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid dead letter ID format")
+	}
+
+	err = h.deadLetters.ReplayDeadLetter(c.Request().Context(), id, h.deadLetterReplayer.Replay)
+	if errors.Is(err, db.ErrDeadLetterNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "dead letter not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (h Handler) DeleteOpsDeadLetter(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid dead letter ID format")
+	}
+
+	err = h.deadLetters.DiscardDeadLetter(c.Request().Context(), id)
+	if errors.Is(err, db.ErrDeadLetterNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "dead letter not found")
+	} else if err != nil {
+		return fmt.Errorf("failed to discard dead letter: %w", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
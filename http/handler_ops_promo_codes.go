@@ -0,0 +1,44 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tickets/db"
+	"tickets/entities"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h Handler) GetOpsPromoCodes(c echo.Context) error {
+	promoCodes, err := h.promoCodes.FindAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, promoCodes)
+}
+
+func (h Handler) PostOpsPromoCodes(c echo.Context) error {
+	var promoCode entities.PromoCode
+	if err := c.Bind(&promoCode); err != nil {
+		return err
+	}
+
+	promoCode.Code = entities.NormalizePromoCode(promoCode.Code)
+	promoCode.Redemptions = 0
+
+	if err := promoCode.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.promoCodes.AddPromoCode(c.Request().Context(), promoCode); err != nil {
+		if errors.Is(err, db.ErrPromoCodeAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, "promo code already exists")
+		}
+		return fmt.Errorf("failed to add promo code: %w", err)
+	}
+
+	return c.JSON(http.StatusCreated, promoCode)
+}
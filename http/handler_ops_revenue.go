@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"tickets/entities"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h Handler) GetOpsExchangeRates(c echo.Context) error {
+	rates, err := h.exchangeRates.FindAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, rates)
+}
+
+// PostOpsExchangeRates accepts a JSON array of rates or, with the text/csv content type,
+// the same CSV format as the EXCHANGE_RATES_FILE.
+func (h Handler) PostOpsExchangeRates(c echo.Context) error {
+	var (
+		rates []entities.ExchangeRate
+		err   error
+	)
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		rates, err = entities.ParseExchangeRatesCSV(c.Request().Body)
+	} else {
+		rates, err = entities.ParseExchangeRatesJSON(c.Request().Body)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.exchangeRates.AddRates(c.Request().Context(), rates); err != nil {
+		return fmt.Errorf("failed to add exchange rates: %w", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetOpsRevenue reports revenue of tickets confirmed between the from (inclusive) and to (exclusive) dates
+// in the reporting currency.
+func (h Handler) GetOpsRevenue(c echo.Context) error {
+	currency := c.QueryParam("currency")
+	if !entities.IsValidCurrency(currency) {
+		return echo.NewHTTPError(http.StatusBadRequest, "currency must be an ISO 4217 code")
+	}
+
+	from, err := parseDateQueryParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := parseDateQueryParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	bookings, err := h.opsBookings.FindAll(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	rates, err := h.exchangeRates.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := entities.NewRevenueReport(bookings, entities.NewExchangeRates(rates), currency, from, to)
+	if err != nil {
+		if errors.Is(err, entities.ErrExchangeRateNotFound) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		}
+		if errors.Is(err, entities.ErrDecimalOverflow) {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "revenue is too large to report in "+currency)
+		}
+		return fmt.Errorf("failed to create revenue report: %w", err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func parseDateQueryParam(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s must be a YYYY-MM-DD date", name))
+	}
+
+	return date, nil
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"tickets/db"
+	"tickets/entities"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (h Handler) GetOpsShowAttendance(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show ID format")
+	}
+
+	availability, err := h.showAvailability.FindByShowID(c.Request().Context(), showID)
+	if err != nil {
+		if errors.Is(err, db.ErrShowNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "show not found")
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entities.NewShowAttendance(availability))
+}
@@ -1,7 +1,10 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"tickets/db"
 	"tickets/entities"
 	"time"
 
@@ -15,6 +18,15 @@ type PostShowsRequest struct {
 	StartTime       time.Time `json:"start_time"`
 	Title           string    `json:"title"`
 	Venue           string    `json:"venue"`
+
+	// SeatMap is optional, shows without it have general admission with NumberOfTickets places.
+	SeatMap *entities.SeatMap `json:"seat_map,omitempty"`
+
+	// MaxTicketsPerCustomer is optional, by default customers are limited only by the per-booking limit.
+	MaxTicketsPerCustomer *int `json:"max_tickets_per_customer,omitempty"`
+
+	// PriceCategories are optional, when set tickets have to be booked in one of the categories.
+	PriceCategories []entities.PriceCategory `json:"price_categories,omitempty"`
 }
 
 type PostShowsResponse struct {
@@ -27,9 +39,42 @@ func (h Handler) GetShows(c echo.Context) error {
 		return err
 	}
 
+	availability, err := h.showAvailability.FindAll(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	availabilityByShowID := make(map[uuid.UUID]entities.ShowAvailability, len(availability))
+	for _, a := range availability {
+		availabilityByShowID[a.ShowID] = a
+	}
+
+	for i := range shows {
+		if a, ok := availabilityByShowID[shows[i].ShowID]; ok {
+			shows[i].Availability = &a
+		}
+	}
+
 	return c.JSON(http.StatusOK, shows)
 }
 
+func (h Handler) GetShowAvailability(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show ID format")
+	}
+
+	availability, err := h.showAvailability.FindByShowID(c.Request().Context(), showID)
+	if err != nil {
+		if errors.Is(err, db.ErrShowNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "show not found")
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, availability)
+}
+
 func (h Handler) PostShows(c echo.Context) error {
 	var request PostShowsRequest
 
@@ -37,6 +82,28 @@ func (h Handler) PostShows(c echo.Context) error {
 		return err
 	}
 
+	if request.SeatMap != nil {
+		if err := request.SeatMap.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		seats := len(request.SeatMap.Seats())
+		if request.NumberOfTickets == 0 {
+			request.NumberOfTickets = seats
+		}
+		if request.NumberOfTickets != seats {
+			return echo.NewHTTPError(http.StatusBadRequest, "number of tickets must match the number of seats in the seat map")
+		}
+	}
+
+	if err := validatePriceCategories(request.PriceCategories); err != nil {
+		return err
+	}
+
+	if request.MaxTicketsPerCustomer != nil && *request.MaxTicketsPerCustomer < 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "max tickets per customer must be greater than 0")
+	}
+
 	showID := uuid.New()
 
 	show := entities.Show{
@@ -46,6 +113,10 @@ func (h Handler) PostShows(c echo.Context) error {
 		StartTime:       request.StartTime,
 		Title:           request.Title,
 		Venue:           request.Venue,
+		SeatMap:         request.SeatMap,
+		PriceCategories: request.PriceCategories,
+
+		MaxTicketsPerCustomer: request.MaxTicketsPerCustomer,
 	}
 
 	if err := h.shows.AddShow(c.Request().Context(), show); err != nil {
@@ -54,3 +125,56 @@ func (h Handler) PostShows(c echo.Context) error {
 
 	return c.JSON(http.StatusCreated, PostShowsResponse{ShowID: showID})
 }
+
+func validatePriceCategories(categories []entities.PriceCategory) error {
+	names := map[string]struct{}{}
+
+	for _, category := range categories {
+		if category.Name == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "price category name is required")
+		}
+		if _, ok := names[category.Name]; ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("duplicated price category %s", category.Name))
+		}
+		names[category.Name] = struct{}{}
+
+		if err := category.Price.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid price of category %s: %s", category.Name, err))
+		}
+	}
+
+	return nil
+}
+
+func (h Handler) PostShowCancel(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show ID format")
+	}
+
+	if err := h.shows.CancelShow(c.Request().Context(), showID); err != nil {
+		if errors.Is(err, db.ErrShowNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "show not found")
+		}
+		if errors.Is(err, db.ErrShowAlreadyCanceled) {
+			return echo.NewHTTPError(http.StatusConflict, "show is already canceled")
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (h Handler) GetShowSeats(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show ID format")
+	}
+
+	seats, err := h.shows.FindSeats(c.Request().Context(), showID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, seats)
+}
@@ -33,6 +33,13 @@ func (h Handler) PostTicketsStatus(c echo.Context) error {
 		return err
 	}
 
+	// validate all tickets first, so a malformed price doesn't leave the request half processed
+	for _, t := range request.Tickets {
+		if err := t.Price.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid price of ticket %s: %s", t.TicketID, err))
+		}
+	}
+
 	for _, t := range request.Tickets {
 		if t.Status == "confirmed" {
 			e := &entities.TicketBookingConfirmed_v1{
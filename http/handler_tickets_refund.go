@@ -1,12 +1,32 @@
 package http
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"tickets/db"
 	"tickets/entities"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// PutTicketRefundRequest is optional, without it the full ticket price is refunded on customer request.
+type PutTicketRefundRequest struct {
+	Amount *entities.Money       `json:"amount,omitempty"`
+	Reason entities.RefundReason `json:"reason,omitempty"`
+
+	// PolicyOverride lets ops refund a ticket which the refund policy rejects, the override is audited.
+	PolicyOverride *RefundPolicyOverride `json:"policy_override,omitempty"`
+}
+
+type RefundPolicyOverride struct {
+	OverriddenBy string `json:"overridden_by"`
+	Reason       string `json:"reason"`
+}
+
 func (h Handler) PutTicketRefund(c echo.Context) error {
 	ticketID := c.Param("ticket_id")
 
@@ -14,12 +34,171 @@ func (h Handler) PutTicketRefund(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "ticket_id is required")
 	}
 
+	var request PutTicketRefundRequest
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	if request.Reason == "" {
+		request.Reason = entities.RefundReasonCustomerRequested
+	}
+	if !request.Reason.IsValid() {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unknown refund reason %q", request.Reason))
+	}
+	if override := request.PolicyOverride; override != nil &&
+		(strings.TrimSpace(override.OverriddenBy) == "" || strings.TrimSpace(override.Reason) == "") {
+		return echo.NewHTTPError(http.StatusBadRequest, "policy override requires overridden_by and reason")
+	}
+
+	ctx := c.Request().Context()
+
 	// Use ticketID as idempotency key to ensure refund is idempotent
 	idempotencyKey := "refund-" + ticketID
 
+	if request.Amount != nil {
+		// a ticket can be refunded partially more than once, retries are deduplicated with the Idempotency-Key header
+		key := c.Request().Header.Get("Idempotency-Key")
+		if key == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key header is required for partial refunds")
+		}
+		idempotencyKey = "partial-refund-" + ticketID + "-" + key
+	}
+
+	ticket, err := h.tickets.TicketByID(ctx, ticketID)
+	if errors.Is(err, db.ErrTicketNotFound) {
+		if request.Amount != nil {
+			return echo.NewHTTPError(http.StatusNotFound, "ticket not found")
+		}
+		// the ticket may not be stored yet, without its price and show there is no policy to check
+		return h.sendRefundTicket(c, ticketID, idempotencyKey, entities.TicketRefund{FullyRefunded: true}, request.Reason)
+	} else if err != nil {
+		return err
+	}
+
+	if request.Amount != nil {
+		if err := validateRefundAmount(*request.Amount, ticket.Price); err != nil {
+			return err
+		}
+	}
+
+	refund, idempotencyKey, err := h.addRefund(c, ticket, idempotencyKey, request.Amount, request.PolicyOverride)
+	if err != nil {
+		return err
+	}
+
+	return h.sendRefundTicket(c, ticketID, idempotencyKey, refund, request.Reason)
+}
+
+// addRefund checks the refund against the refund policy and adds it to the refunded total of the ticket,
+// a retried request gets the refund it added before. Tickets of canceled shows and tickets booked outside
+// our system are always refunded. Refunds lowered by the policy get their own idempotency key,
+// so refunding the rest of the price when the show is canceled isn't deduplicated with them.
+func (h Handler) addRefund(
+	c echo.Context,
+	ticket entities.Ticket,
+	idempotencyKey string,
+	amount *entities.Money,
+	override *RefundPolicyOverride,
+) (entities.TicketRefund, string, error) {
+	ctx := c.Request().Context()
+
+	var (
+		showStartTime    time.Time
+		maxRefundedTotal *entities.Money
+	)
+
+	show, err := h.shows.ShowByTicketID(ctx, ticket.TicketID)
+	if err != nil && !errors.Is(err, db.ErrShowNotFound) {
+		return entities.TicketRefund{}, "", err
+	}
+
+	if err == nil && show.CanceledAt == nil {
+		showStartTime = show.StartTime
+
+		decision := h.refundPolicy.Evaluate(ticket.Price, amount, show.StartTime, time.Now())
+		if decision.Allowed {
+			amount, maxRefundedTotal = decision.Amount, decision.MaxRefundedTotal
+			if amount == nil && maxRefundedTotal != nil {
+				idempotencyKey = "policy-refund-" + ticket.TicketID
+			}
+		} else if err := h.overrideRefundPolicy(c, ticket.TicketID, amount, *decision.Rejection, override); err != nil {
+			return entities.TicketRefund{}, "", err
+		}
+	}
+
+	refund, err := h.tickets.AddRefund(ctx, ticket.TicketID, idempotencyKey, amount, maxRefundedTotal)
+	if errors.Is(err, entities.ErrRefundExceedsPolicy) {
+		// each refund is within the policy, but not together with the refunds before
+		rejection := entities.RefundRejection{
+			Code:          entities.RefundRejectionAmountExceedsPolicy,
+			Message:       err.Error(),
+			ShowStartTime: showStartTime,
+		}
+		if err := h.overrideRefundPolicy(c, ticket.TicketID, amount, rejection, override); err != nil {
+			return entities.TicketRefund{}, "", err
+		}
+
+		refund, err = h.tickets.AddRefund(ctx, ticket.TicketID, idempotencyKey, amount, nil)
+	}
+
+	if errors.Is(err, entities.ErrRefundExceedsPrice) {
+		return entities.TicketRefund{}, "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if errors.Is(err, entities.ErrTicketAlreadyRefunded) {
+		return entities.TicketRefund{}, "", echo.NewHTTPError(http.StatusConflict, err.Error())
+	} else if err != nil {
+		return entities.TicketRefund{}, "", err
+	}
+
+	return refund, idempotencyKey, nil
+}
+
+// overrideRefundPolicy returns the rejection to the client, unless ops override the policy, then the override is audited.
+func (h Handler) overrideRefundPolicy(
+	c echo.Context,
+	ticketID string,
+	amount *entities.Money,
+	rejection entities.RefundRejection,
+	override *RefundPolicyOverride,
+) error {
+	if override == nil {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, rejection)
+	}
+
+	slog.Warn(
+		"refund policy overridden",
+		"ticket_id", ticketID,
+		"rejection", rejection.Code,
+		"overridden_by", override.OverriddenBy,
+	)
+
+	err := h.eventBus.Publish(c.Request().Context(), entities.RefundPolicyOverridden_v1{
+		Header:       entities.NewMessageHeader(),
+		TicketID:     ticketID,
+		Amount:       amount,
+		Rejection:    rejection,
+		OverriddenBy: override.OverriddenBy,
+		Reason:       override.Reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish refund policy override: %w", err)
+	}
+
+	return nil
+}
+
+func (h Handler) sendRefundTicket(
+	c echo.Context,
+	ticketID string,
+	idempotencyKey string,
+	refund entities.TicketRefund,
+	reason entities.RefundReason,
+) error {
 	cmd := &entities.RefundTicket{
-		Header:   entities.NewMessageHeaderWithIdempotencyKey(idempotencyKey),
-		TicketID: ticketID,
+		Header:        entities.NewMessageHeaderWithIdempotencyKey(idempotencyKey),
+		TicketID:      ticketID,
+		Amount:        refund.Amount,
+		FullyRefunded: refund.FullyRefunded,
+		Reason:        reason,
 	}
 
 	if err := h.commandBus.Send(c.Request().Context(), cmd); err != nil {
@@ -28,3 +207,20 @@ func (h Handler) PutTicketRefund(c echo.Context) error {
 
 	return c.NoContent(http.StatusAccepted)
 }
+
+func validateRefundAmount(amount entities.Money, ticketPrice entities.Money) error {
+	if err := amount.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if amount.Amount.Sign() == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "refund amount must be greater than 0")
+	}
+	if amount.Currency != ticketPrice.Currency {
+		return echo.NewHTTPError(http.StatusBadRequest, "refund currency must match the ticket price currency")
+	}
+	if amount.Amount.Cmp(ticketPrice.Amount) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "refund amount can't exceed the ticket price")
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"tickets/db"
+	"tickets/entities"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PutTicketTransferRequest struct {
+	CustomerEmail string `json:"customer_email"`
+}
+
+func (h Handler) PutTicketTransfer(c echo.Context) error {
+	ticketID := c.Param("ticket_id")
+
+	var request PutTicketTransferRequest
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	customerEmail := strings.TrimSpace(request.CustomerEmail)
+	if _, err := mail.ParseAddress(customerEmail); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "customer_email must be a valid email address")
+	}
+
+	ctx := c.Request().Context()
+
+	ticket, err := h.tickets.TicketByID(ctx, ticketID)
+	if errors.Is(err, db.ErrTicketNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "ticket not found")
+	} else if err != nil {
+		return err
+	}
+
+	if ticket.CustomerEmail == customerEmail {
+		return echo.NewHTTPError(http.StatusBadRequest, "ticket already belongs to this customer")
+	}
+
+	// a ticket can go back to its previous holder, so the key is unique per revision, not per email
+	cmd := &entities.TransferTicket{
+		Header:           entities.NewMessageHeaderWithIdempotencyKey(fmt.Sprintf("transfer-%s-%d", ticketID, ticket.Revision)),
+		TicketID:         ticketID,
+		NewCustomerEmail: customerEmail,
+	}
+
+	if err := h.commandBus.Send(ctx, cmd); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tickets/db"
+	"tickets/entities"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type PostShowWaitlistRequest struct {
+	NumberOfTickets int    `json:"number_of_tickets"`
+	CustomerEmail   string `json:"customer_email"`
+}
+
+type PostShowWaitlistResponse struct {
+	EntryID uuid.UUID `json:"entry_id"`
+}
+
+func (h Handler) PostShowWaitlist(c echo.Context) error {
+	showID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid show ID format")
+	}
+
+	var request PostShowWaitlistRequest
+	if err := c.Bind(&request); err != nil {
+		return err
+	}
+
+	if request.NumberOfTickets < 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "number of tickets must be greater than 0")
+	}
+
+	entry := entities.WaitlistEntry{
+		EntryID:         uuid.New(),
+		ShowID:          showID,
+		NumberOfTickets: request.NumberOfTickets,
+		CustomerEmail:   request.CustomerEmail,
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	if err := h.waitlist.AddEntry(c.Request().Context(), entry); err != nil {
+		if errors.Is(err, db.ErrShowNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "show not found")
+		}
+		if errors.Is(err, db.ErrShowCanceled) {
+			return echo.NewHTTPError(http.StatusBadRequest, "show is canceled")
+		}
+		if errors.Is(err, db.ErrPurchaseLimitExceeded) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return fmt.Errorf("failed to add waitlist entry: %w", err)
+	}
+
+	return c.JSON(http.StatusCreated, PostShowWaitlistResponse{EntryID: entry.EntryID})
+}
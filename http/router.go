@@ -3,6 +3,7 @@ package http
 import (
 	"strings"
 	"tickets/db"
+	"tickets/entities"
 
 	libHttp "github.com/ThreeDotsLabs/go-event-driven/v2/common/http"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
@@ -12,7 +13,7 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 )
 
-func NewHttpRouter(eventBus *cqrs.EventBus, commandBus *cqrs.CommandBus, tickets db.TicketRepository, shows db.ShowRepository, bookings db.BookingRepository, opsBookings db.OpsBookingReadModel) *echo.Echo {
+func NewHttpRouter(eventBus *cqrs.EventBus, commandBus *cqrs.CommandBus, tickets db.TicketRepository, shows db.ShowRepository, bookings db.BookingRepository, holds db.HoldRepository, waitlist db.WaitlistRepository, opsBookings db.OpsBookingReadModel, showAvailability db.ShowAvailabilityReadModel, exchangeRates db.ExchangeRateRepository, promoCodes db.PromoCodeRepository, refundPolicy entities.RefundPolicy, ticketSigner entities.TicketSigner, deadLetters db.DeadLetterRepository, deadLetterReplayer DeadLetterReplayer) *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
 	e.HTTPErrorHandler = libHttp.HandleError
@@ -20,7 +21,7 @@ func NewHttpRouter(eventBus *cqrs.EventBus, commandBus *cqrs.CommandBus, tickets
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"http://localhost:3000"},
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "Idempotency-Key"},
 	}))
 	e.Use(RequestIDMiddleware())
 	e.Use(BodyDumpMiddleware(func(c echo.Context) bool {
@@ -37,7 +38,19 @@ func NewHttpRouter(eventBus *cqrs.EventBus, commandBus *cqrs.CommandBus, tickets
 		tickets:     tickets,
 		shows:       shows,
 		bookings:    bookings,
+		holds:       holds,
+		waitlist:    waitlist,
 		opsBookings: opsBookings,
+
+		showAvailability: showAvailability,
+		exchangeRates:    exchangeRates,
+		promoCodes:       promoCodes,
+
+		refundPolicy: refundPolicy,
+		ticketSigner: ticketSigner,
+
+		deadLetters:        deadLetters,
+		deadLetterReplayer: deadLetterReplayer,
 	}
 
 	api := e.Group("/api")
@@ -45,11 +58,30 @@ func NewHttpRouter(eventBus *cqrs.EventBus, commandBus *cqrs.CommandBus, tickets
 	api.GET("/tickets", handler.GetTickets)
 	api.GET("/shows", handler.GetShows)
 	api.POST("/shows", handler.PostShows)
+	api.POST("/shows/:id/cancel", handler.PostShowCancel)
+	api.GET("/shows/:id/seats", handler.GetShowSeats)
+	api.GET("/shows/:id/availability", handler.GetShowAvailability)
+	api.POST("/shows/:id/waitlist", handler.PostShowWaitlist)
 	api.POST("/book-tickets", handler.PostBookTickets)
+	api.DELETE("/bookings/:id", handler.DeleteBooking)
+	api.POST("/holds", handler.PostHolds)
+	api.POST("/holds/:id/confirm", handler.PostHoldConfirm)
 	api.PUT("/ticket-refund/:ticket_id", handler.PutTicketRefund)
+	api.PUT("/tickets/:ticket_id/transfer", handler.PutTicketTransfer)
+	api.POST("/check-in", handler.PostCheckIn)
 
 	api.GET("/ops/bookings", handler.GetOpsBookings)
 	api.GET("/ops/bookings/:id", handler.GetOpsBookingByID)
+	api.GET("/ops/shows/:id/attendance", handler.GetOpsShowAttendance)
+	api.GET("/ops/exchange-rates", handler.GetOpsExchangeRates)
+	api.POST("/ops/exchange-rates", handler.PostOpsExchangeRates)
+	api.GET("/ops/revenue", handler.GetOpsRevenue)
+	api.GET("/ops/promo-codes", handler.GetOpsPromoCodes)
+	api.POST("/ops/promo-codes", handler.PostOpsPromoCodes)
+	api.GET("/ops/dead-letters", handler.GetOpsDeadLetters)
+	api.GET("/ops/dead-letters/:id", handler.GetOpsDeadLetterByID)
+	api.POST("/ops/dead-letters/:id/replay", handler.PostOpsDeadLetterReplay)
+	api.DELETE("/ops/dead-letters/:id", handler.DeleteOpsDeadLetter)
 
 	e.GET("/health", handler.GetHealthCheck)
 	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
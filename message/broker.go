@@ -0,0 +1,105 @@
+package message
+
+import (
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+type BrokerKind string
+
+const (
+	BrokerRedis    BrokerKind = "redis"
+	BrokerPostgres BrokerKind = "postgres"
+	BrokerMemory   BrokerKind = "memory"
+)
+
+// Broker is the pub/sub carrying messages between handlers. Every consumer group gets all messages
+// of a topic, the consumers in a consumer group share them.
+type Broker interface {
+	Publisher() message.Publisher
+	Subscriber(consumerGroup string) (message.Subscriber, error)
+	Close() error
+}
+
+// NewBroker creates the broker of the kind, Redis Streams when it's empty. The Postgres broker keeps messages
+// in db, the memory broker only delivers them within the process, so it's meant for development and tests.
+func NewBroker(kind BrokerKind, redisAddr string, db *sqlx.DB, logger watermill.LoggerAdapter) (Broker, error) {
+	switch kind {
+	case BrokerRedis, "":
+		return NewRedisBroker(NewRedisClient(redisAddr), logger), nil
+	case BrokerPostgres:
+		return NewPostgresBroker(db, logger), nil
+	case BrokerMemory:
+		return NewMemoryBroker(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown message broker %q, expected %q, %q or %q", kind, BrokerRedis, BrokerPostgres, BrokerMemory)
+	}
+}
+
+type RedisBroker struct {
+	rdb       *redis.Client
+	publisher message.Publisher
+	logger    watermill.LoggerAdapter
+}
+
+func NewRedisBroker(rdb *redis.Client, logger watermill.LoggerAdapter) RedisBroker {
+	if rdb == nil {
+		panic("rdb is nil")
+	}
+
+	return RedisBroker{
+		rdb:       rdb,
+		publisher: NewRedisPublisher(rdb, logger),
+		logger:    logger,
+	}
+}
+
+func (b RedisBroker) Publisher() message.Publisher {
+	return b.publisher
+}
+
+func (b RedisBroker) Subscriber(consumerGroup string) (message.Subscriber, error) {
+	return NewRedisSubscriber(b.rdb, consumerGroup, b.logger), nil
+}
+
+func (b RedisBroker) Close() error {
+	return b.rdb.Close()
+}
+
+// MemoryBroker delivers messages to every subscriber of the topic, each consumer group subscribes once,
+// so the consumer group doesn't need to be tracked. Messages published while nobody subscribes are lost.
+type MemoryBroker struct {
+	pubSub *gochannel.GoChannel
+}
+
+func NewMemoryBroker(logger watermill.LoggerAdapter) MemoryBroker {
+	return MemoryBroker{
+		pubSub: gochannel.NewGoChannel(gochannel.Config{}, logger),
+	}
+}
+
+func (b MemoryBroker) Publisher() message.Publisher {
+	return b.pubSub
+}
+
+func (b MemoryBroker) Subscriber(consumerGroup string) (message.Subscriber, error) {
+	return sharedSubscriber{Subscriber: b.pubSub}, nil
+}
+
+func (b MemoryBroker) Close() error {
+	return b.pubSub.Close()
+}
+
+// sharedSubscriber is closed with its broker, the router closes the subscriber of every handler.
+type sharedSubscriber struct {
+	message.Subscriber
+}
+
+func (s sharedSubscriber) Close() error {
+	return nil
+}
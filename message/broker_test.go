@@ -0,0 +1,50 @@
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	watermillMessage "github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/message"
+)
+
+func TestMemoryBroker(t *testing.T) {
+	broker, err := message.NewBroker(message.BrokerMemory, "", nil, watermill.NopLogger{})
+	require.NoError(t, err)
+	defer broker.Close()
+
+	var received []<-chan *watermillMessage.Message
+	for _, consumerGroup := range []string{"first", "second"} {
+		sub, err := broker.Subscriber(consumerGroup)
+		require.NoError(t, err)
+
+		messages, err := sub.Subscribe(t.Context(), "topic")
+		require.NoError(t, err)
+		received = append(received, messages)
+
+		// the router closes subscribers of handlers when it stops, the broker stays open for the other handlers
+		require.NoError(t, sub.Close())
+	}
+
+	msg := watermillMessage.NewMessage("1", []byte("payload"))
+	require.NoError(t, broker.Publisher().Publish("topic", msg))
+
+	for _, messages := range received {
+		select {
+		case m := <-messages:
+			assert.Equal(t, "1", m.UUID)
+			m.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("every consumer group should receive the message")
+		}
+	}
+}
+
+func TestNewBroker_unknown_kind(t *testing.T) {
+	_, err := message.NewBroker("kafka", "", nil, watermill.NopLogger{})
+	assert.Error(t, err)
+}
@@ -5,9 +5,13 @@ import (
 
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
+
+	"tickets/contracts"
 )
 
 func NewCommandBus(pub message.Publisher) *cqrs.CommandBus {
+	pub = contracts.ValidatingPublisherDecorator{Publisher: pub, Catalog: contracts.Default}
+
 	bus, err := cqrs.NewCommandBusWithConfig(pub, cqrs.CommandBusConfig{
 		Marshaler: marshaler,
 		GeneratePublishTopic: func(params cqrs.CommandBusGeneratePublishTopicParams) (string, error) {
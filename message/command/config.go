@@ -4,26 +4,21 @@ import (
 	"fmt"
 
 	"github.com/ThreeDotsLabs/watermill"
-	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/redis/go-redis/v9"
 )
 
 var marshaler = cqrs.JSONMarshaler{
 	GenerateName: cqrs.StructName,
 }
 
-func NewProcessorConfig(redisClient *redis.Client, watermillLogger watermill.LoggerAdapter) cqrs.CommandProcessorConfig {
+func NewProcessorConfig(newSubscriber func(consumerGroup string) (message.Subscriber, error), watermillLogger watermill.LoggerAdapter) cqrs.CommandProcessorConfig {
 	return cqrs.CommandProcessorConfig{
 		GenerateSubscribeTopic: func(params cqrs.CommandProcessorGenerateSubscribeTopicParams) (string, error) {
 			return fmt.Sprintf("commands.%s", params.CommandName), nil
 		},
 		SubscriberConstructor: func(params cqrs.CommandProcessorSubscriberConstructorParams) (message.Subscriber, error) {
-			return redisstream.NewSubscriber(redisstream.SubscriberConfig{
-				Client:        redisClient,
-				ConsumerGroup: "svc-tickets.commands." + params.HandlerName,
-			}, watermillLogger)
+			return newSubscriber("svc-tickets.commands." + params.HandlerName)
 		},
 		Marshaler: marshaler,
 		Logger:    watermillLogger,
@@ -9,43 +9,72 @@ import (
 )
 
 type ReceiptsService interface {
-	VoidReceipt(ctx context.Context, ticketID, idempotencyKey string) error
+	VoidReceipt(ctx context.Context, request entities.VoidReceipt) error
 }
 
 type PaymentsService interface {
-	RefundPayment(ctx context.Context, ticketID, idempotencyKey string) error
+	RefundPayment(ctx context.Context, request entities.RefundPaymentRequest) error
+}
+
+type TicketsRepository interface {
+	TransferTicket(ctx context.Context, ticketID string, newCustomerEmail string) error
 }
 
 type Handlers struct {
-	receiptsService ReceiptsService
-	paymentsService PaymentsService
-	events          *cqrs.EventBus
+	receiptsService   ReceiptsService
+	paymentsService   PaymentsService
+	ticketsRepository TicketsRepository
+	events            *cqrs.EventBus
 }
 
-func NewHandlers(receiptsService ReceiptsService, paymentsService PaymentsService, eventBus *cqrs.EventBus) Handlers {
+func NewHandlers(
+	receiptsService ReceiptsService,
+	paymentsService PaymentsService,
+	ticketsRepository TicketsRepository,
+	eventBus *cqrs.EventBus,
+) Handlers {
 	return Handlers{
-		receiptsService: receiptsService,
-		paymentsService: paymentsService,
-		events:          eventBus,
+		receiptsService:   receiptsService,
+		paymentsService:   paymentsService,
+		ticketsRepository: ticketsRepository,
+		events:            eventBus,
 	}
 }
 
 func (h Handlers) RefundTicketHandler(ctx context.Context, cmd *entities.RefundTicket) error {
-	slog.Info("refunding ticket", "ticket_id", cmd.TicketID)
+	reason := cmd.Reason
+	if reason == "" {
+		reason = entities.RefundReasonCustomerRequested
+	}
 
-	err := h.receiptsService.VoidReceipt(ctx, cmd.TicketID, cmd.Header.IdempotencyKey)
+	slog.Info("refunding ticket", "ticket_id", cmd.TicketID, "reason", reason, "partial", cmd.Amount != nil)
+
+	err := h.receiptsService.VoidReceipt(ctx, entities.VoidReceipt{
+		TicketID:       cmd.TicketID,
+		Amount:         cmd.Amount,
+		Reason:         reason,
+		IdempotencyKey: cmd.Header.IdempotencyKey,
+	})
 	if err != nil {
 		return err
 	}
 
-	err = h.paymentsService.RefundPayment(ctx, cmd.TicketID, cmd.Header.IdempotencyKey)
+	err = h.paymentsService.RefundPayment(ctx, entities.RefundPaymentRequest{
+		TicketID:       cmd.TicketID,
+		Amount:         cmd.Amount,
+		Reason:         reason,
+		IdempotencyKey: cmd.Header.IdempotencyKey,
+	})
 	if err != nil {
 		return err
 	}
 
 	ticketRefunded := entities.TicketRefunded_v1{
-		Header:   cmd.Header,
-		TicketID: cmd.TicketID,
+		Header:        cmd.Header,
+		TicketID:      cmd.TicketID,
+		Amount:        cmd.Amount,
+		Reason:        reason,
+		FullyRefunded: cmd.FullyRefunded,
 	}
 
 	if err := h.events.Publish(ctx, ticketRefunded); err != nil {
@@ -55,3 +84,14 @@ func (h Handlers) RefundTicketHandler(ctx context.Context, cmd *entities.RefundT
 	slog.Info("ticket refunded successfully", "ticket_id", cmd.TicketID)
 	return nil
 }
+
+func (h Handlers) TransferTicketHandler(ctx context.Context, cmd *entities.TransferTicket) error {
+	slog.Info("transferring ticket", "ticket_id", cmd.TicketID)
+
+	if err := h.ticketsRepository.TransferTicket(ctx, cmd.TicketID, cmd.NewCustomerEmail); err != nil {
+		return err
+	}
+
+	slog.Info("ticket transferred successfully", "ticket_id", cmd.TicketID)
+	return nil
+}
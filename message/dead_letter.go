@@ -0,0 +1,189 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/go-event-driven/v2/common/log"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"tickets/entities"
+)
+
+const (
+	deadLetterTopicPrefix   = "poison."
+	deadLetterHandlerPrefix = "dead_letters."
+
+	// forwarderHandlerName is the handler forwarding messages from the outbox, its messages stay in the outbox
+	// until they are published, so there is nothing to dead letter.
+	forwarderHandlerName = "events_forwarder"
+
+	deadLetteredAtMetadataKey = "dead_lettered_at"
+
+	// replayHandlerMetadataKey limits a replayed message to the handler which failed to process it,
+	// other handlers subscribed to the topic already processed it.
+	replayHandlerMetadataKey = "replay_handler"
+)
+
+var messagesDeadLetteredTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "messages",
+		Name:      "dead_lettered_total",
+		Help:      "The total number of messages moved to a dead letter topic after all retries failed",
+	},
+	[]string{"topic", "handler"},
+)
+
+type DeadLetterStore interface {
+	AddDeadLetter(ctx context.Context, deadLetter entities.DeadLetter) error
+}
+
+// DeadLetterTopic is the poison topic of the handler, messages the handler failed to process are published there.
+func DeadLetterTopic(handlerName string) string {
+	return deadLetterTopicPrefix + handlerName
+}
+
+func isDeadLettered(handlerName string) bool {
+	return handlerName != forwarderHandlerName && !strings.HasPrefix(handlerName, deadLetterHandlerPrefix)
+}
+
+// deadLetterMiddleware moves messages which failed after all retries to the poison topic of their handler,
+// so one poison message doesn't block the consumer group. It must wrap the retry middleware.
+func deadLetterMiddleware(publisher message.Publisher) message.HandlerMiddleware {
+	return func(next message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			ctx := msg.Context()
+			handler := message.HandlerNameFromCtx(ctx)
+
+			if !isDeadLettered(handler) {
+				return next(msg)
+			}
+
+			if replayHandler := msg.Metadata.Get(replayHandlerMetadataKey); replayHandler != "" && replayHandler != handler {
+				return nil, nil
+			}
+
+			msgs, err := next(msg)
+			if err == nil {
+				return msgs, nil
+			}
+
+			topic := message.SubscribeTopicFromCtx(ctx)
+
+			poisoned := msg.Copy()
+			poisoned.SetContext(ctx)
+			poisoned.Metadata.Set(middleware.ReasonForPoisonedKey, err.Error())
+			poisoned.Metadata.Set(middleware.PoisonedTopicKey, topic)
+			poisoned.Metadata.Set(middleware.PoisonedHandlerKey, handler)
+			poisoned.Metadata.Set(deadLetteredAtMetadataKey, time.Now().UTC().Format(time.RFC3339Nano))
+			delete(poisoned.Metadata, replayHandlerMetadataKey)
+
+			if pubErr := publisher.Publish(DeadLetterTopic(handler), poisoned); pubErr != nil {
+				return nil, errors.Join(err, fmt.Errorf("could not publish message to dead letter topic: %w", pubErr))
+			}
+
+			messagesDeadLetteredTotal.With(prometheus.Labels{"topic": topic, "handler": handler}).Inc()
+
+			log.FromContext(ctx).With(
+				"message_id", msg.UUID,
+				"handler", handler,
+				"error", err,
+			).Error("Message moved to dead letter topic")
+
+			return nil, nil
+		}
+	}
+}
+
+// addDeadLetterHandlers stores messages from poison topics of all handlers added to the router so far.
+func addDeadLetterHandlers(router *message.Router, subscriber message.Subscriber, deadLetters DeadLetterStore) {
+	for _, handler := range slices.Sorted(maps.Keys(router.Handlers())) {
+		if !isDeadLettered(handler) {
+			continue
+		}
+
+		name := deadLetterHandlerPrefix + handler
+
+		h := router.AddConsumerHandler(
+			name,
+			DeadLetterTopic(handler),
+			subscriber,
+			func(msg *message.Message) error {
+				return deadLetters.AddDeadLetter(msg.Context(), newDeadLetter(msg))
+			},
+		)
+		h.AddMiddleware(RetryPolicyFor(name).Middlewares(router.Logger())...)
+	}
+}
+
+func newDeadLetter(msg *message.Message) entities.DeadLetter {
+	metadata := maps.Clone(msg.Metadata)
+
+	deadLetteredAt, err := time.Parse(time.RFC3339Nano, metadata[deadLetteredAtMetadataKey])
+	if err != nil {
+		deadLetteredAt = time.Now().UTC()
+	}
+
+	deadLetter := entities.DeadLetter{
+		ID:             uuid.New(),
+		MessageID:      msg.UUID,
+		Topic:          metadata[middleware.PoisonedTopicKey],
+		Handler:        metadata[middleware.PoisonedHandlerKey],
+		Error:          metadata[middleware.ReasonForPoisonedKey],
+		CorrelationID:  metadata["correlation_id"],
+		Payload:        string(msg.Payload),
+		DeadLetteredAt: deadLetteredAt,
+	}
+
+	// only the metadata of the original message is kept, so it can be published again as it was
+	for _, key := range []string{
+		middleware.ReasonForPoisonedKey,
+		middleware.PoisonedTopicKey,
+		middleware.PoisonedHandlerKey,
+		deadLetteredAtMetadataKey,
+	} {
+		delete(metadata, key)
+	}
+	deadLetter.Metadata = metadata
+
+	return deadLetter
+}
+
+// DeadLetterReplayer publishes dead letters to their original topic again.
+type DeadLetterReplayer struct {
+	publisher message.Publisher
+}
+
+func NewDeadLetterReplayer(publisher message.Publisher) DeadLetterReplayer {
+	if publisher == nil {
+		panic("publisher is nil")
+	}
+
+	return DeadLetterReplayer{publisher: publisher}
+}
+
+// Replay publishes the message with its original ID and metadata, only the handler which failed to process it
+// handles it again.
+func (r DeadLetterReplayer) Replay(ctx context.Context, deadLetter entities.DeadLetter) error {
+	msg := message.NewMessage(deadLetter.MessageID, []byte(deadLetter.Payload))
+	msg.SetContext(ctx)
+	for key, value := range deadLetter.Metadata {
+		msg.Metadata.Set(key, value)
+	}
+	msg.Metadata.Set(replayHandlerMetadataKey, deadLetter.Handler)
+
+	if err := r.publisher.Publish(deadLetter.Topic, msg); err != nil {
+		return fmt.Errorf("could not replay message %s to %s: %w", deadLetter.MessageID, deadLetter.Topic, err)
+	}
+
+	return nil
+}
@@ -3,9 +3,13 @@ package event
 import (
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
+
+	"tickets/contracts"
 )
 
 func NewEventBus(pub message.Publisher) *cqrs.EventBus {
+	pub = contracts.ValidatingPublisherDecorator{Publisher: pub, Catalog: contracts.Default}
+
 	bus, err := cqrs.NewEventBusWithConfig(pub, cqrs.EventBusConfig{
 		Marshaler: Marshaler,
 		GeneratePublishTopic: func(params cqrs.GenerateEventPublishTopicParams) (string, error) {
@@ -4,26 +4,17 @@ import (
 	"fmt"
 
 	"github.com/ThreeDotsLabs/watermill"
-	"github.com/ThreeDotsLabs/watermill-redisstream/pkg/redisstream"
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/redis/go-redis/v9"
 )
 
-var Marshaler = cqrs.JSONMarshaler{
-	GenerateName: cqrs.StructName,
-}
-
-func NewProcessorConfig(redisClient *redis.Client, watermillLogger watermill.LoggerAdapter) cqrs.EventProcessorConfig {
+func NewProcessorConfig(newSubscriber func(consumerGroup string) (message.Subscriber, error), watermillLogger watermill.LoggerAdapter) cqrs.EventProcessorConfig {
 	return cqrs.EventProcessorConfig{
 		GenerateSubscribeTopic: func(params cqrs.EventProcessorGenerateSubscribeTopicParams) (string, error) {
 			return fmt.Sprintf("events.%s", params.EventName), nil
 		},
 		SubscriberConstructor: func(params cqrs.EventProcessorSubscriberConstructorParams) (message.Subscriber, error) {
-			return redisstream.NewSubscriber(redisstream.SubscriberConfig{
-				Client:        redisClient,
-				ConsumerGroup: "svc-tickets.events" + params.HandlerName,
-			}, watermillLogger)
+			return newSubscriber("svc-tickets.events" + params.HandlerName)
 		},
 		Marshaler: Marshaler,
 		Logger:    watermillLogger,
@@ -2,14 +2,21 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"tickets/entities"
+	"tickets/printing"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
 	"github.com/google/uuid"
 )
 
+// waitlistOfferDuration is how long the customer from the waitlist has to confirm the offered seats.
+const waitlistOfferDuration = 30 * time.Minute
+
 type SpreadsheetsAPI interface {
 	AppendRow(ctx context.Context, sheetName string, row []string) error
 }
@@ -26,24 +33,52 @@ type TicketRepository interface {
 	Add(ctx context.Context, ticket entities.Ticket) error
 	Remove(ctx context.Context, ticketID string) error
 	FindAll(context.Context) ([]entities.Ticket, error)
+	FindAllByShowID(ctx context.Context, showID uuid.UUID) ([]entities.Ticket, error)
+	FindAllByBookingID(ctx context.Context, bookingID uuid.UUID) ([]entities.Ticket, error)
+	AddRefund(ctx context.Context, ticketID string, idempotencyKey string, amount, maxRefundedTotal *entities.Money) (entities.TicketRefund, error)
 }
 
 type ShowRepository interface {
 	ShowByID(ctx context.Context, showID uuid.UUID) (entities.Show, error)
 }
 
+type BookingRepository interface {
+	FindAllByShowID(ctx context.Context, showID uuid.UUID) ([]entities.Booking, error)
+	AssignSeatToTicket(ctx context.Context, bookingID uuid.UUID, ticketID string) (*entities.Seat, error)
+	ReleaseTicketSeat(ctx context.Context, ticketID string) error
+	BookingByID(ctx context.Context, bookingID uuid.UUID) (entities.Booking, error)
+}
+
+type WaitlistRepository interface {
+	OfferFreeSeats(ctx context.Context, showID uuid.UUID, offerExpiresAt time.Time) error
+}
+
 type DeadNationAPI interface {
 	BookTicket(ctx context.Context, request entities.DeadNationBooking) error
 }
 
+type TicketSigner interface {
+	Sign(token entities.TicketToken) (string, error)
+}
+
+type TicketRenderer interface {
+	RenderTicket(doc printing.TicketDocument) (string, error)
+	RenderTicketPDF(doc printing.TicketDocument) (string, error)
+}
+
 type Handlers struct {
 	fileAPI         FileAPI
 	spreadsheetsAPI SpreadsheetsAPI
 	receiptsService ReceiptsService
 	tickets         TicketRepository
 	shows           ShowRepository
+	bookings        BookingRepository
+	waitlist        WaitlistRepository
 	deadnation      DeadNationAPI
+	ticketSigner    TicketSigner
+	ticketRenderer  TicketRenderer
 	eventBus        *cqrs.EventBus
+	commandBus      *cqrs.CommandBus
 }
 
 func NewHandlers(
@@ -52,10 +87,15 @@ func NewHandlers(
 	receiptsService ReceiptsService,
 	tickets TicketRepository,
 	shows ShowRepository,
+	bookings BookingRepository,
+	waitlist WaitlistRepository,
 	deadnation DeadNationAPI,
+	ticketSigner TicketSigner,
+	ticketRenderer TicketRenderer,
 	eventBus *cqrs.EventBus,
+	commandBus *cqrs.CommandBus,
 ) Handlers {
-	return Handlers{fileAPI, spreadsheetsAPI, receiptsService, tickets, shows, deadnation, eventBus}
+	return Handlers{fileAPI, spreadsheetsAPI, receiptsService, tickets, shows, bookings, waitlist, deadnation, ticketSigner, ticketRenderer, eventBus, commandBus}
 }
 
 func (h Handlers) IssueReceipt(ctx context.Context, e *entities.TicketBookingConfirmed_v1) error {
@@ -89,9 +129,26 @@ func (h Handlers) StoreTicket(ctx context.Context, e *entities.TicketBookingConf
 		TicketID:      e.TicketID,
 		Price:         e.Price,
 		CustomerEmail: e.CustomerEmail,
+		BookingID:     e.BookingID,
 	}
 
-	return h.tickets.Add(ctx, ticket)
+	if err := h.tickets.Add(ctx, ticket); err != nil {
+		return err
+	}
+
+	// The show can be canceled before the ticket is confirmed, RefundTicketsForCanceledShow doesn't see it then.
+	// The ticket is stored first, so either it sees the ticket or the cancellation is visible here.
+	show, err := h.ticketShow(ctx, e.BookingID)
+	if err != nil {
+		return err
+	}
+	if show == nil || show.CanceledAt == nil {
+		return nil
+	}
+
+	slog.Info("refunding ticket confirmed for canceled show", "ticket_id", e.TicketID, "show_id", show.ShowID)
+
+	return h.refundTickets(ctx, []entities.Ticket{ticket}, entities.RefundReasonShowCanceled)
 }
 
 func (h Handlers) AppendToTracker(ctx context.Context, e *entities.TicketBookingConfirmed_v1) error {
@@ -100,7 +157,7 @@ func (h Handlers) AppendToTracker(ctx context.Context, e *entities.TicketBooking
 	row := []string{
 		e.TicketID,
 		e.CustomerEmail,
-		e.Price.Amount,
+		e.Price.Amount.String(),
 		e.Price.Currency,
 	}
 
@@ -113,7 +170,7 @@ func (h Handlers) TicketRefundToSheet(ctx context.Context, e *entities.TicketBoo
 	row := []string{
 		e.TicketID,
 		e.CustomerEmail,
-		e.Price.Amount,
+		e.Price.Amount.String(),
 		e.Price.Currency,
 	}
 
@@ -129,22 +186,166 @@ func (h Handlers) RemoveCanceledTicket(ctx context.Context, e *entities.TicketBo
 func (h Handlers) PrintTicket(ctx context.Context, e *entities.TicketBookingConfirmed_v1) error {
 	slog.Info("creating ticket file", "ticket_id", e.TicketID)
 
-	fileID := e.TicketID + "-ticket.html"
-	fileContent := fmt.Sprintf("<html><body>Ticket ID: %s, Price: %s %s</body></html>", e.TicketID, e.Price.Amount, e.Price.Currency)
+	ticket := entities.Ticket{
+		TicketID:      e.TicketID,
+		Price:         e.Price,
+		CustomerEmail: e.CustomerEmail,
+		BookingID:     e.BookingID,
+	}
+
+	return h.printTicket(ctx, ticket, 0, e.Header.PublishedAt)
+}
+
+// PrintTransferredTicket prints a new revision of the ticket file for the new holder,
+// the file of the previous holder is invalidated by the ops read model.
+func (h Handlers) PrintTransferredTicket(ctx context.Context, e *entities.TicketTransferred_v1) error {
+	slog.Info("creating transferred ticket file", "ticket_id", e.TicketID, "revision", e.Revision)
 
-	if err := h.fileAPI.UploadFile(ctx, fileID, fileContent); err != nil {
+	ticket := entities.Ticket{
+		TicketID:      e.TicketID,
+		Price:         e.Price,
+		CustomerEmail: e.CustomerEmail,
+		BookingID:     e.BookingID,
+	}
+
+	return h.printTicket(ctx, ticket, e.Revision, e.Header.PublishedAt)
+}
+
+// printTicket uploads the ticket file with a signed QR code, issuedAt comes from the event,
+// so a redelivered event prints the same file.
+func (h Handlers) printTicket(ctx context.Context, ticket entities.Ticket, revision int, issuedAt time.Time) error {
+	seat, err := h.ticketSeat(ctx, ticket.BookingID, ticket.TicketID)
+	if err != nil {
+		return err
+	}
+
+	show, err := h.ticketShow(ctx, ticket.BookingID)
+	if err != nil {
+		return err
+	}
+
+	var showID string
+	if show != nil {
+		showID = show.ShowID.String()
+	}
+
+	token, err := h.ticketSigner.Sign(entities.TicketToken{
+		TicketID: ticket.TicketID,
+		ShowID:   showID,
+		Revision: revision,
+		IssuedAt: issuedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign ticket %s: %w", ticket.TicketID, err)
+	}
+
+	doc := printing.TicketDocument{
+		TicketID:    ticket.TicketID,
+		HolderEmail: ticket.CustomerEmail,
+		Price:       ticket.Price,
+		Revision:    revision,
+		Seat:        seat,
+		Show:        show,
+		Token:       token,
+	}
+
+	htmlContent, err := h.ticketRenderer.RenderTicket(doc)
+	if err != nil {
+		return err
+	}
+	pdfContent, err := h.ticketRenderer.RenderTicketPDF(doc)
+	if err != nil {
+		return err
+	}
+
+	htmlFileID := entities.TicketFileName(ticket.TicketID, revision)
+	pdfFileID := entities.TicketPDFFileName(ticket.TicketID, revision)
+
+	if err := h.fileAPI.UploadFile(ctx, htmlFileID, htmlContent); err != nil {
+		return err
+	}
+	if err := h.fileAPI.UploadFile(ctx, pdfFileID, pdfContent); err != nil {
 		return err
 	}
 
 	ticketPrinted := entities.TicketPrinted_v1{
-		Header:   entities.NewMessageHeader(),
-		TicketID: e.TicketID,
-		FileName: fileID,
+		Header:    entities.NewMessageHeader(),
+		TicketID:  ticket.TicketID,
+		FileName:  htmlFileID,
+		FileNames: []string{htmlFileID, pdfFileID},
 	}
 
 	return h.eventBus.Publish(ctx, ticketPrinted)
 }
 
+func (h Handlers) VerifyTicketPrice(ctx context.Context, e *entities.TicketBookingConfirmed_v1) error {
+	bookingID, err := uuid.Parse(e.BookingID)
+	if err != nil {
+		// tickets booked outside of our system are not in our price catalog
+		return nil
+	}
+
+	booking, err := h.bookings.BookingByID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking %s: %w", bookingID, err)
+	}
+
+	if booking.TicketPrice == nil || booking.TicketPrice.Equal(e.Price) {
+		return nil
+	}
+
+	slog.Warn(
+		"confirmed ticket price doesn't match the price catalog",
+		"ticket_id", e.TicketID,
+		"expected_price", booking.TicketPrice.String(),
+		"confirmed_price", e.Price.String(),
+	)
+
+	return h.eventBus.Publish(ctx, entities.TicketPriceMismatchDetected_v1{
+		Header:         entities.NewMessageHeaderWithIdempotencyKey("price-mismatch-" + e.TicketID),
+		TicketID:       e.TicketID,
+		BookingID:      bookingID,
+		PriceCategory:  booking.PriceCategory,
+		ExpectedPrice:  *booking.TicketPrice,
+		ConfirmedPrice: e.Price,
+	})
+}
+
+func (h Handlers) ticketSeat(ctx context.Context, bookingID string, ticketID string) (*entities.Seat, error) {
+	parsedBookingID, err := uuid.Parse(bookingID)
+	if err != nil {
+		// tickets booked outside of our system don't have a booking, so they don't have a seat
+		return nil, nil
+	}
+
+	seat, err := h.bookings.AssignSeatToTicket(ctx, parsedBookingID, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign seat to ticket %s: %w", ticketID, err)
+	}
+
+	return seat, nil
+}
+
+// ticketShow returns nil for tickets booked outside of our system.
+func (h Handlers) ticketShow(ctx context.Context, bookingID string) (*entities.Show, error) {
+	parsedBookingID, err := uuid.Parse(bookingID)
+	if err != nil {
+		return nil, nil
+	}
+
+	booking, err := h.bookings.BookingByID(ctx, parsedBookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking %s: %w", bookingID, err)
+	}
+
+	show, err := h.shows.ShowByID(ctx, booking.ShowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get show %s: %w", booking.ShowID, err)
+	}
+
+	return &show, nil
+}
+
 func (h Handlers) BookPlaceInDeadNation(ctx context.Context, e *entities.BookingMade_v1) error {
 	slog.Info("booking ticket on Dead Nation", "booking_id", e.BookingID)
 
@@ -162,3 +363,133 @@ func (h Handlers) BookPlaceInDeadNation(ctx context.Context, e *entities.Booking
 
 	return h.deadnation.BookTicket(ctx, request)
 }
+
+func (h Handlers) RefundTicketsForCanceledShow(ctx context.Context, e *entities.ShowCanceled_v1) error {
+	slog.Info("refunding tickets of canceled show", "show_id", e.ShowID)
+
+	tickets, err := h.tickets.FindAllByShowID(ctx, e.ShowID)
+	if err != nil {
+		return err
+	}
+
+	return h.refundTickets(ctx, tickets, entities.RefundReasonShowCanceled)
+}
+
+func (h Handlers) RefundCanceledBookingTickets(ctx context.Context, e *entities.BookingCanceled_v1) error {
+	slog.Info("refunding tickets of canceled booking", "booking_id", e.BookingID)
+
+	tickets, err := h.tickets.FindAllByBookingID(ctx, e.BookingID)
+	if err != nil {
+		return err
+	}
+
+	return h.refundTickets(ctx, tickets, entities.RefundReasonBookingCanceled)
+}
+
+// refundTickets refunds what's left of the price of each ticket after partial refunds.
+func (h Handlers) refundTickets(ctx context.Context, tickets []entities.Ticket, reason entities.RefundReason) error {
+	for _, ticket := range tickets {
+		// the same key as for refunds requested via API, so a ticket is never refunded twice
+		idempotencyKey := "refund-" + ticket.TicketID
+
+		refund, err := h.tickets.AddRefund(ctx, ticket.TicketID, idempotencyKey, nil, nil)
+		if errors.Is(err, entities.ErrTicketAlreadyRefunded) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to add refund for ticket %s: %w", ticket.TicketID, err)
+		}
+
+		cmd := &entities.RefundTicket{
+			Header:        entities.NewMessageHeaderWithIdempotencyKey(idempotencyKey),
+			TicketID:      ticket.TicketID,
+			Amount:        refund.Amount,
+			FullyRefunded: refund.FullyRefunded,
+			Reason:        reason,
+		}
+
+		if err := h.commandBus.Send(ctx, cmd); err != nil {
+			return fmt.Errorf("failed to send refund for ticket %s: %w", ticket.TicketID, err)
+		}
+	}
+
+	return nil
+}
+
+func (h Handlers) CancelDeadNationBookings(ctx context.Context, e *entities.ShowCanceled_v1) error {
+	slog.Info("canceling Dead Nation bookings of canceled show", "show_id", e.ShowID)
+
+	show, err := h.shows.ShowByID(ctx, e.ShowID)
+	if err != nil {
+		return fmt.Errorf("failed to get show: %w", err)
+	}
+
+	bookings, err := h.bookings.FindAllByShowID(ctx, e.ShowID)
+	if err != nil {
+		return err
+	}
+
+	for _, booking := range bookings {
+		if err := h.cancelDeadNationBooking(ctx, show, booking); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h Handlers) CancelDeadNationBooking(ctx context.Context, e *entities.BookingCanceled_v1) error {
+	slog.Info("canceling Dead Nation booking", "booking_id", e.BookingID)
+
+	show, err := h.shows.ShowByID(ctx, e.ShowID)
+	if err != nil {
+		return fmt.Errorf("failed to get show: %w", err)
+	}
+
+	return h.cancelDeadNationBooking(ctx, show, entities.Booking{
+		BookingID:       e.BookingID,
+		ShowID:          e.ShowID,
+		NumberOfTickets: e.NumberOfTickets,
+		CustomerEmail:   e.CustomerEmail,
+	})
+}
+
+func (h Handlers) cancelDeadNationBooking(ctx context.Context, show entities.Show, booking entities.Booking) error {
+	// Dead Nation API doesn't support canceling bookings, ops are canceling them manually from the sheet
+	row := []string{
+		booking.BookingID.String(),
+		show.DeadNationID.String(),
+		strconv.Itoa(booking.NumberOfTickets),
+		booking.CustomerEmail,
+	}
+
+	return h.spreadsheetsAPI.AppendRow(ctx, "dead-nation-bookings-to-cancel", row)
+}
+
+func (h Handlers) ReleaseRefundedTicketSeat(ctx context.Context, e *entities.TicketRefunded_v1) error {
+	if e.Amount != nil && !e.FullyRefunded {
+		// the ticket is still valid after a partial refund
+		return nil
+	}
+
+	slog.Info("releasing seat of refunded ticket", "ticket_id", e.TicketID)
+
+	return h.bookings.ReleaseTicketSeat(ctx, e.TicketID)
+}
+
+func (h Handlers) OfferReleasedSeatsToWaitlist(ctx context.Context, e *entities.SeatsReleased_v1) error {
+	slog.Info("offering released seats to waitlist", "show_id", e.ShowID)
+
+	return h.waitlist.OfferFreeSeats(ctx, e.ShowID, time.Now().UTC().Add(waitlistOfferDuration))
+}
+
+func (h Handlers) OfferExpiredHoldSeatsToWaitlist(ctx context.Context, e *entities.SeatHoldExpired_v1) error {
+	slog.Info("offering seats of expired hold to waitlist", "show_id", e.ShowID)
+
+	return h.waitlist.OfferFreeSeats(ctx, e.ShowID, time.Now().UTC().Add(waitlistOfferDuration))
+}
+
+func (h Handlers) OfferCanceledBookingSeatsToWaitlist(ctx context.Context, e *entities.BookingCanceled_v1) error {
+	slog.Info("offering seats of canceled booking to waitlist", "show_id", e.ShowID)
+
+	return h.waitlist.OfferFreeSeats(ctx, e.ShowID, time.Now().UTC().Add(waitlistOfferDuration))
+}
@@ -0,0 +1,45 @@
+package event
+
+import (
+	"encoding/json"
+
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"tickets/entities"
+)
+
+// Marshaler upcasts events published in older versions, so handlers always get the latest version.
+var Marshaler = NewUpcastingMarshaler(
+	cqrs.JSONMarshaler{GenerateName: cqrs.StructName},
+	entities.DefaultEventUpcasters(),
+)
+
+// UpcastingMarshaler reports events by the name of their latest version, so they are routed to handlers
+// of the latest version, and upcasts their payload when unmarshaling.
+type UpcastingMarshaler struct {
+	cqrs.JSONMarshaler
+	upcasters entities.EventUpcasters
+}
+
+func NewUpcastingMarshaler(marshaler cqrs.JSONMarshaler, upcasters entities.EventUpcasters) UpcastingMarshaler {
+	return UpcastingMarshaler{JSONMarshaler: marshaler, upcasters: upcasters}
+}
+
+func (m UpcastingMarshaler) NameFromMessage(msg *message.Message) string {
+	return m.upcasters.LatestName(m.JSONMarshaler.NameFromMessage(msg))
+}
+
+// PublishedNameFromMessage returns the name of the event version the message was published with.
+func (m UpcastingMarshaler) PublishedNameFromMessage(msg *message.Message) string {
+	return m.JSONMarshaler.NameFromMessage(msg)
+}
+
+func (m UpcastingMarshaler) Unmarshal(msg *message.Message, v any) error {
+	_, payload, err := m.upcasters.Upcast(m.JSONMarshaler.NameFromMessage(msg), msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(payload, v)
+}
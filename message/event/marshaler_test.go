@@ -0,0 +1,41 @@
+package event_test
+
+import (
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+	"tickets/message/event"
+)
+
+func TestMarshaler_upcasts_old_event_versions(t *testing.T) {
+	msg := message.NewMessage("1", []byte(`{"header": {"id": "1"}, "ticket_id": "ticket-1", "file_name": "ticket-1-ticket.html"}`))
+	msg.Metadata.Set("name", "TicketPrinted_v0")
+
+	assert.Equal(t, "TicketPrinted_v1", event.Marshaler.NameFromMessage(msg), "old versions are routed to handlers of the latest version")
+	assert.Equal(t, "TicketPrinted_v0", event.Marshaler.PublishedNameFromMessage(msg))
+
+	var e entities.TicketPrinted_v1
+	require.NoError(t, event.Marshaler.Unmarshal(msg, &e))
+	assert.Equal(t, "ticket-1", e.TicketID)
+	assert.Equal(t, "ticket-1-ticket.html", e.FileName)
+}
+
+func TestMarshaler_latest_event_version(t *testing.T) {
+	published := entities.TicketPrinted_v1{
+		Header:   entities.NewMessageHeader(),
+		TicketID: "ticket-1",
+		FileName: "ticket-1-ticket.html",
+	}
+
+	msg, err := event.Marshaler.Marshal(published)
+	require.NoError(t, err)
+	assert.Equal(t, "TicketPrinted_v1", event.Marshaler.NameFromMessage(msg))
+
+	var e entities.TicketPrinted_v1
+	require.NoError(t, event.Marshaler.Unmarshal(msg, &e))
+	assert.Equal(t, published.TicketID, e.TicketID)
+}
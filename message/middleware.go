@@ -16,6 +16,8 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
+
+	"tickets/contracts"
 )
 
 var (
@@ -48,16 +50,12 @@ var (
 	)
 )
 
-func useMiddlewares(router *message.Router) {
-	router.AddMiddleware(middleware.Recoverer)
+func useMiddlewares(router *message.Router, publisher message.Publisher) {
+	// outside of Recoverer and the retry policies of handlers, so messages are dead lettered after panics
+	// and after all retries
+	router.AddMiddleware(deadLetterMiddleware(publisher))
 
-	router.AddMiddleware(middleware.Retry{
-		MaxRetries:      3,
-		InitialInterval: time.Millisecond * 400,
-		MaxInterval:     time.Second,
-		Multiplier:      2,
-		Logger:          router.Logger(),
-	}.Middleware)
+	router.AddMiddleware(middleware.Recoverer)
 
 	router.AddMiddleware(tracingMiddleware)
 
@@ -66,6 +64,8 @@ func useMiddlewares(router *message.Router) {
 	router.AddMiddleware(logMiddleware)
 
 	router.AddMiddleware(metricsMiddleware)
+
+	router.AddMiddleware(contractMiddleware(contracts.Default))
 }
 
 func metricsMiddleware(next message.HandlerFunc) message.HandlerFunc {
@@ -160,3 +160,21 @@ func tracingMiddleware(next message.HandlerFunc) message.HandlerFunc {
 		return msgs, err
 	}
 }
+
+// contractMiddleware rejects messages which don't match their contract without calling the handler.
+// The error is returned past the retries of the handler, so the message is quarantined in the dead letter store.
+func contractMiddleware(catalog contracts.Catalog) message.HandlerMiddleware {
+	return func(next message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			if !isDeadLettered(message.HandlerNameFromCtx(msg.Context())) {
+				return next(msg)
+			}
+
+			if err := catalog.ValidateMessage(msg); err != nil {
+				return nil, err
+			}
+
+			return next(msg)
+		}
+	}
+}
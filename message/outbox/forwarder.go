@@ -12,12 +12,13 @@ func AddForwarderHandler(
 	publisher message.Publisher,
 	router *message.Router,
 	logger watermill.LoggerAdapter,
+	middlewares ...message.HandlerMiddleware,
 ) {
 
 	config := forwarder.Config{
 		ForwarderTopic: outboxTopic,
 		Router:         router,
-		Middlewares:    []message.HandlerMiddleware{loggerMW},
+		Middlewares:    append([]message.HandlerMiddleware{loggerMW}, middlewares...),
 	}
 
 	_, err := forwarder.NewForwarder(subscriber, publisher, logger, config)
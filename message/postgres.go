@@ -0,0 +1,88 @@
+package message
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-sql/v3/pkg/sql"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+)
+
+// postgresMaxIdentifierLength is the limit of Postgres table names, longer names are truncated.
+const postgresMaxIdentifierLength = 63
+
+// PostgresBroker keeps messages in a table per topic, created on the first publish or subscribe.
+type PostgresBroker struct {
+	db        *sqlx.DB
+	publisher message.Publisher
+	logger    watermill.LoggerAdapter
+}
+
+func NewPostgresBroker(db *sqlx.DB, logger watermill.LoggerAdapter) PostgresBroker {
+	if db == nil {
+		panic("db is nil")
+	}
+
+	pub, err := sql.NewPublisher(db, sql.PublisherConfig{
+		SchemaAdapter:        postgresSchemaAdapter,
+		AutoInitializeSchema: true,
+	}, logger)
+	if err != nil {
+		panic(err)
+	}
+
+	return PostgresBroker{
+		db:        db,
+		publisher: pub,
+		logger:    logger,
+	}
+}
+
+func (b PostgresBroker) Publisher() message.Publisher {
+	return b.publisher
+}
+
+func (b PostgresBroker) Subscriber(consumerGroup string) (message.Subscriber, error) {
+	return sql.NewSubscriber(b.db, sql.SubscriberConfig{
+		ConsumerGroup:    consumerGroup,
+		PollInterval:     time.Millisecond * 100,
+		SchemaAdapter:    postgresSchemaAdapter,
+		OffsetsAdapter:   postgresOffsetsAdapter,
+		InitializeSchema: true,
+	}, b.logger)
+}
+
+// Close doesn't close db, it's shared with the repositories.
+func (b PostgresBroker) Close() error {
+	return nil
+}
+
+var (
+	postgresSchemaAdapter = sql.DefaultPostgreSQLSchema{
+		GenerateMessagesTableName: func(topic string) string {
+			return postgresTableName("watermill_", topic)
+		},
+	}
+	postgresOffsetsAdapter = sql.DefaultPostgreSQLOffsetsAdapter{
+		GenerateMessagesOffsetsTableName: func(topic string) string {
+			return postgresTableName("watermill_offsets_", topic)
+		},
+	}
+)
+
+// postgresTableName keeps the table names of watermill-sql, but shortens names of long topics,
+// like poison topics of read model handlers, with a hash of the topic, so they don't collide when truncated.
+func postgresTableName(prefix, topic string) string {
+	name := prefix + topic
+
+	if len(name) > postgresMaxIdentifierLength {
+		hash := sha256.Sum256([]byte(topic))
+		suffix := "_" + hex.EncodeToString(hash[:4])
+		name = name[:postgresMaxIdentifierLength-len(suffix)] + suffix
+	}
+
+	return `"` + name + `"`
+}
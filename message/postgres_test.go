@@ -0,0 +1,71 @@
+package message
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresTableName(t *testing.T) {
+	assert.Equal(t, `"watermill_events"`, postgresTableName("watermill_", "events"))
+	assert.Equal(t, `"watermill_offsets_events"`, postgresTableName("watermill_offsets_", "events"))
+
+	first := postgresTableName("watermill_offsets_", DeadLetterTopic("show_availability_read_model.OnBookingCanceled"))
+	second := postgresTableName("watermill_offsets_", DeadLetterTopic("show_availability_read_model.OnTicketCheckedIn"))
+
+	for _, name := range []string{first, second} {
+		unquoted := strings.Trim(name, `"`)
+		assert.Len(t, unquoted, postgresMaxIdentifierLength, name)
+		assert.True(t, strings.HasPrefix(unquoted, "watermill_offsets_poison.show_availability_read_model."), name)
+	}
+	assert.NotEqual(t, first, second, "long topics with the same prefix get different tables")
+}
+
+func TestPostgresBroker(t *testing.T) {
+	postgresURL := os.Getenv("POSTGRES_URL")
+	if postgresURL == "" {
+		t.Skip("POSTGRES_URL is not set")
+	}
+
+	db, err := sqlx.Open("postgres", postgresURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	broker := NewPostgresBroker(db, watermill.NopLogger{})
+	defer broker.Close()
+
+	topic := "broker_test_" + watermill.NewShortUUID()
+
+	var received []<-chan *message.Message
+	for _, consumerGroup := range []string{"first", "second"} {
+		sub, err := broker.Subscriber(consumerGroup)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		messages, err := sub.Subscribe(t.Context(), topic)
+		require.NoError(t, err)
+		received = append(received, messages)
+	}
+
+	msg := message.NewMessage(watermill.NewUUID(), []byte(`{"payload":true}`))
+	require.NoError(t, broker.Publisher().Publish(topic, msg))
+
+	for _, messages := range received {
+		select {
+		case m := <-messages:
+			assert.Equal(t, msg.UUID, m.UUID)
+			assert.JSONEq(t, `{"payload":true}`, string(m.Payload))
+			m.Ack()
+		case <-time.After(10 * time.Second):
+			t.Fatal("every consumer group should receive the message")
+		}
+	}
+}
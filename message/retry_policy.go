@@ -0,0 +1,152 @@
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+)
+
+// RetryPolicy decides how a handler retries a message before it's moved to the dead letter topic.
+type RetryPolicy struct {
+	MaxRetries int
+
+	// InitialInterval is the delay before the first retry, every next delay is Multiplier times longer
+	// up to MaxInterval.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+
+	// Timeout limits each attempt, the message context is canceled after it. Zero means no limit.
+	Timeout time.Duration
+
+	// Retryable classifies errors, a message failing with an error which is not retryable is dead lettered
+	// right away. Nil means isRetryable.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy is used by handlers without their own policy in retryPolicies.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:      3,
+	InitialInterval: 400 * time.Millisecond,
+	MaxInterval:     time.Second,
+	Multiplier:      2,
+}
+
+var (
+	// externalAPIRetryPolicy gives external services time to recover, they are called with idempotency keys,
+	// so a call which timed out can be retried.
+	externalAPIRetryPolicy = RetryPolicy{
+		MaxRetries:      6,
+		InitialInterval: time.Second,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      2,
+		Timeout:         10 * time.Second,
+	}
+
+	// readModelRetryPolicy spins until an event that arrived out of order can be applied,
+	// the missing read model is usually created within milliseconds.
+	readModelRetryPolicy = RetryPolicy{
+		MaxRetries:      20,
+		InitialInterval: 50 * time.Millisecond,
+		MaxInterval:     500 * time.Millisecond,
+		Multiplier:      1.5,
+		Timeout:         5 * time.Second,
+	}
+)
+
+// retryPolicies are the policies of handlers which don't use DefaultRetryPolicy, by handler name.
+var retryPolicies = map[string]RetryPolicy{
+	"IssueReceipt":             externalAPIRetryPolicy,
+	"BookPlaceInDeadNation":    externalAPIRetryPolicy,
+	"CancelDeadNationBooking":  externalAPIRetryPolicy,
+	"CancelDeadNationBookings": externalAPIRetryPolicy,
+	"RefundTicket":             externalAPIRetryPolicy,
+
+	"ops_read_model.OnBookingCanceled":        readModelRetryPolicy,
+	"ops_read_model.OnTicketBookingConfirmed": readModelRetryPolicy,
+	"ops_read_model.OnTicketRefunded":         readModelRetryPolicy,
+	"ops_read_model.OnTicketPrinted":          readModelRetryPolicy,
+	"ops_read_model.OnTicketReceiptIssued":    readModelRetryPolicy,
+	"ops_read_model.OnTicketTransferred":      readModelRetryPolicy,
+	"ops_read_model.OnTicketCheckedIn":        readModelRetryPolicy,
+
+	"show_availability_read_model.OnBookingMade":     readModelRetryPolicy,
+	"show_availability_read_model.OnSeatsHeld":       readModelRetryPolicy,
+	"show_availability_read_model.OnSeatHoldExpired": readModelRetryPolicy,
+	"show_availability_read_model.OnSeatsReleased":   readModelRetryPolicy,
+	"show_availability_read_model.OnBookingCanceled": readModelRetryPolicy,
+	"show_availability_read_model.OnTicketCheckedIn": readModelRetryPolicy,
+}
+
+// RetryPolicyFor returns the policy of the handler.
+func RetryPolicyFor(handlerName string) RetryPolicy {
+	if policy, ok := retryPolicies[handlerName]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// Middlewares returns the handler level middlewares applying the policy.
+func (p RetryPolicy) Middlewares(logger watermill.LoggerAdapter) []message.HandlerMiddleware {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = isRetryable
+	}
+
+	middlewares := []message.HandlerMiddleware{
+		middleware.Retry{
+			MaxRetries:      p.MaxRetries,
+			InitialInterval: p.InitialInterval,
+			MaxInterval:     p.MaxInterval,
+			Multiplier:      p.Multiplier,
+			ShouldRetry: func(params middleware.RetryParams) bool {
+				return retryable(params.Err)
+			},
+			// every attempt gets a new timeout, the context of the previous attempt is already canceled
+			ResetContextOnRetry: true,
+			Logger:              logger,
+		}.Middleware,
+	}
+
+	if p.Timeout > 0 {
+		// added after Retry, so it's called for every attempt
+		middlewares = append(middlewares, middleware.Timeout(p.Timeout))
+	}
+
+	return middlewares
+}
+
+// isRetryable doesn't retry messages which can't be unmarshaled, they will never succeed.
+func isRetryable(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	return !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr)
+}
+
+func addEventHandler(ep *cqrs.EventProcessor, handler cqrs.EventHandler, logger watermill.LoggerAdapter) error {
+	h, err := ep.AddHandler(handler)
+	if err != nil {
+		return err
+	}
+
+	h.AddMiddleware(RetryPolicyFor(handler.HandlerName()).Middlewares(logger)...)
+
+	return nil
+}
+
+func addCommandHandler(cp *cqrs.CommandProcessor, handler cqrs.CommandHandler, logger watermill.LoggerAdapter) error {
+	h, err := cp.AddHandler(handler)
+	if err != nil {
+		return err
+	}
+
+	h.AddMiddleware(RetryPolicyFor(handler.HandlerName()).Middlewares(logger)...)
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	watermillMessage "github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/message"
+)
+
+func TestRetryPolicy_Middlewares(t *testing.T) {
+	policy := message.RetryPolicy{
+		MaxRetries:      3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      1,
+		Timeout:         50 * time.Millisecond,
+	}
+
+	testCases := []struct {
+		Name             string
+		Handler          func(attempt int, msg *watermillMessage.Message) error
+		ExpectedAttempts int
+		ExpectError      bool
+	}{
+		{
+			Name: "succeeds_after_retry",
+			Handler: func(attempt int, msg *watermillMessage.Message) error {
+				if attempt < 3 {
+					return errors.New("temporary failure")
+				}
+				return nil
+			},
+			ExpectedAttempts: 3,
+		},
+		{
+			Name: "retries_exhausted",
+			Handler: func(attempt int, msg *watermillMessage.Message) error {
+				return errors.New("failure")
+			},
+			ExpectedAttempts: 4,
+			ExpectError:      true,
+		},
+		{
+			Name: "malformed_message_not_retried",
+			Handler: func(attempt int, msg *watermillMessage.Message) error {
+				var v struct{}
+				return fmt.Errorf("cannot unmarshal: %w", json.Unmarshal([]byte("not json"), &v))
+			},
+			ExpectedAttempts: 1,
+			ExpectError:      true,
+		},
+		{
+			Name: "timeout_per_attempt",
+			Handler: func(attempt int, msg *watermillMessage.Message) error {
+				if attempt == 1 {
+					<-msg.Context().Done()
+					return msg.Context().Err()
+				}
+				// the previous attempt timed out, this one has a new timeout
+				return msg.Context().Err()
+			},
+			ExpectedAttempts: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			attempts := 0
+			handler := func(msg *watermillMessage.Message) ([]*watermillMessage.Message, error) {
+				attempts++
+				return nil, tc.Handler(attempts, msg)
+			}
+
+			middlewares := policy.Middlewares(watermill.NopLogger{})
+			for i := len(middlewares) - 1; i >= 0; i-- {
+				handler = middlewares[i](handler)
+			}
+
+			msg := watermillMessage.NewMessage("1", nil)
+			msg.SetContext(context.Background())
+
+			_, err := handler(msg)
+			if tc.ExpectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.ExpectedAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryPolicyFor(t *testing.T) {
+	assert.Equal(t, message.DefaultRetryPolicy.MaxRetries, message.RetryPolicyFor("unknown").MaxRetries)
+	assert.Greater(t, message.RetryPolicyFor("ops_read_model.OnTicketPrinted").MaxRetries, message.DefaultRetryPolicy.MaxRetries)
+	assert.Greater(t, message.RetryPolicyFor("BookPlaceInDeadNation").MaxInterval, message.DefaultRetryPolicy.MaxInterval)
+}
@@ -20,7 +20,7 @@ type SpreadsheetsAPI interface {
 
 type ReceiptsService interface {
 	IssueReceipt(context.Context, entities.IssueReceiptRequest) (entities.IssueReceiptResponse, error)
-	VoidReceipt(ctx context.Context, ticketID, idempotencyKey string) error
+	VoidReceipt(ctx context.Context, request entities.VoidReceipt) error
 }
 
 type FileAPI interface {
@@ -28,15 +28,27 @@ type FileAPI interface {
 }
 
 type PaymentsService interface {
-	RefundPayment(ctx context.Context, ticketID, idempotencyKey string) error
+	RefundPayment(ctx context.Context, request entities.RefundPaymentRequest) error
 }
 
 type OpsBookingReadModel interface {
 	OnBookingMade(context.Context, *entities.BookingMade_v1) error
+	OnBookingCanceled(context.Context, *entities.BookingCanceled_v1) error
 	OnTicketBookingConfirmed(context.Context, *entities.TicketBookingConfirmed_v1) error
 	OnTicketRefunded(context.Context, *entities.TicketRefunded_v1) error
 	OnTicketPrinted(context.Context, *entities.TicketPrinted_v1) error
 	OnTicketReceiptIssued(context.Context, *entities.TicketReceiptIssued_v1) error
+	OnTicketTransferred(context.Context, *entities.TicketTransferred_v1) error
+	OnTicketCheckedIn(context.Context, *entities.TicketCheckedIn_v1) error
+}
+
+type ShowAvailabilityReadModel interface {
+	OnBookingMade(context.Context, *entities.BookingMade_v1) error
+	OnSeatsHeld(context.Context, *entities.SeatsHeld_v1) error
+	OnSeatHoldExpired(context.Context, *entities.SeatHoldExpired_v1) error
+	OnSeatsReleased(context.Context, *entities.SeatsReleased_v1) error
+	OnBookingCanceled(context.Context, *entities.BookingCanceled_v1) error
+	OnTicketCheckedIn(context.Context, *entities.TicketCheckedIn_v1) error
 }
 
 type DataLake interface {
@@ -55,16 +67,19 @@ func NewRouter(
 	eventHandlers event.Handlers,
 	commandHandlers command.Handlers,
 	opsBookings OpsBookingReadModel,
+	showAvailability ShowAvailabilityReadModel,
 	logger watermill.LoggerAdapter,
 	db *sqlx.DB,
 	eventsSplitterSubscriber message.Subscriber,
 	dataLakeSubscriber message.Subscriber,
 	dataLake DataLake,
+	deadLetterSubscriber message.Subscriber,
+	deadLetters DeadLetterStore,
 ) (*Router, error) {
-	router := message.NewDefaultRouter(logger)
-	outbox.AddForwarderHandler(subscriber, publisher, router, logger)
+	router := newRouter(publisher, logger)
+	outbox.AddForwarderHandler(subscriber, publisher, router, logger, RetryPolicyFor(forwarderHandlerName).Middlewares(logger)...)
 
-	router.AddConsumerHandler(
+	eventsSplitter := router.AddConsumerHandler(
 		"events_splitter",
 		"events",
 		eventsSplitterSubscriber,
@@ -77,13 +92,15 @@ func NewRouter(
 			return publisher.Publish("events."+eventName, msg)
 		},
 	)
+	eventsSplitter.AddMiddleware(RetryPolicyFor("events_splitter").Middlewares(logger)...)
 
-	router.AddConsumerHandler(
+	storeToDataLake := router.AddConsumerHandler(
 		"store_to_data_lake",
 		"events",
 		dataLakeSubscriber,
 		func(msg *message.Message) error {
-			eventName := eventProcessorConfig.Marshaler.NameFromMessage(msg)
+			// the data lake keeps events as they were published, they are upcasted when read
+			eventName := event.Marshaler.PublishedNameFromMessage(msg)
 			if eventName == "" {
 				return fmt.Errorf("cannot get event name from message")
 			}
@@ -99,64 +116,136 @@ func NewRouter(
 			return dataLake.StoreEvent(msg.Context(), e.Header.ID, e.Header, eventName, msg.Payload)
 		},
 	)
-
-	useMiddlewares(router)
+	storeToDataLake.AddMiddleware(RetryPolicyFor("store_to_data_lake").Middlewares(logger)...)
 
 	ep, err := cqrs.NewEventProcessorWithConfig(router, eventProcessorConfig)
 	if err != nil {
 		panic(err)
 	}
 
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("StoreTicket", eventHandlers.StoreTicket)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("StoreTicket", eventHandlers.StoreTicket), logger); err != nil {
 		return nil, fmt.Errorf("failed to add StoreTicket handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("AppendToTracker", eventHandlers.AppendToTracker)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("AppendToTracker", eventHandlers.AppendToTracker), logger); err != nil {
 		return nil, fmt.Errorf("failed to add AppendToTracker handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("PrintTicket", eventHandlers.PrintTicket)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("PrintTicket", eventHandlers.PrintTicket), logger); err != nil {
 		return nil, fmt.Errorf("failed to add PrintTicket handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("TicketRefundToSheet", eventHandlers.TicketRefundToSheet)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("PrintTransferredTicket", eventHandlers.PrintTransferredTicket), logger); err != nil {
+		return nil, fmt.Errorf("failed to add PrintTransferredTicket handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("VerifyTicketPrice", eventHandlers.VerifyTicketPrice), logger); err != nil {
+		return nil, fmt.Errorf("failed to add VerifyTicketPrice handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("TicketRefundToSheet", eventHandlers.TicketRefundToSheet), logger); err != nil {
 		return nil, fmt.Errorf("failed to add TicketRefundToSheet handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("IssueReceipt", eventHandlers.IssueReceipt)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("IssueReceipt", eventHandlers.IssueReceipt), logger); err != nil {
 		return nil, fmt.Errorf("failed to add IssueReceipt handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("RemoveCanceledTicket", eventHandlers.RemoveCanceledTicket)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("RemoveCanceledTicket", eventHandlers.RemoveCanceledTicket), logger); err != nil {
 		return nil, fmt.Errorf("failed to add RemoveCanceledTicket handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("BookPlaceInDeadNation", eventHandlers.BookPlaceInDeadNation)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("BookPlaceInDeadNation", eventHandlers.BookPlaceInDeadNation), logger); err != nil {
 		return nil, fmt.Errorf("failed to add BookPlaceInDeadNation handler: %w", err)
 	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("RefundTicketsForCanceledShow", eventHandlers.RefundTicketsForCanceledShow), logger); err != nil {
+		return nil, fmt.Errorf("failed to add RefundTicketsForCanceledShow handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("CancelDeadNationBookings", eventHandlers.CancelDeadNationBookings), logger); err != nil {
+		return nil, fmt.Errorf("failed to add CancelDeadNationBookings handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ReleaseRefundedTicketSeat", eventHandlers.ReleaseRefundedTicketSeat), logger); err != nil {
+		return nil, fmt.Errorf("failed to add ReleaseRefundedTicketSeat handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("OfferReleasedSeatsToWaitlist", eventHandlers.OfferReleasedSeatsToWaitlist), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OfferReleasedSeatsToWaitlist handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("OfferExpiredHoldSeatsToWaitlist", eventHandlers.OfferExpiredHoldSeatsToWaitlist), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OfferExpiredHoldSeatsToWaitlist handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("RefundCanceledBookingTickets", eventHandlers.RefundCanceledBookingTickets), logger); err != nil {
+		return nil, fmt.Errorf("failed to add RefundCanceledBookingTickets handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("CancelDeadNationBooking", eventHandlers.CancelDeadNationBooking), logger); err != nil {
+		return nil, fmt.Errorf("failed to add CancelDeadNationBooking handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("OfferCanceledBookingSeatsToWaitlist", eventHandlers.OfferCanceledBookingSeatsToWaitlist), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OfferCanceledBookingSeatsToWaitlist handler: %w", err)
+	}
 
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("ops_read_model.OnBookingMade", opsBookings.OnBookingMade)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnBookingMade", opsBookings.OnBookingMade), logger); err != nil {
 		return nil, fmt.Errorf("failed to add OnBookingMade handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("ops_read_model.OnTicketBookingConfirmed", opsBookings.OnTicketBookingConfirmed)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnBookingCanceled", opsBookings.OnBookingCanceled), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnBookingCanceled handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnTicketBookingConfirmed", opsBookings.OnTicketBookingConfirmed), logger); err != nil {
 		return nil, fmt.Errorf("failed to add OnTicketBookingConfirmed handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("ops_read_model.OnTicketRefunded", opsBookings.OnTicketRefunded)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnTicketRefunded", opsBookings.OnTicketRefunded), logger); err != nil {
 		return nil, fmt.Errorf("failed to add OnTicketRefunded handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("ops_read_model.OnTicketPrinted", opsBookings.OnTicketPrinted)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnTicketPrinted", opsBookings.OnTicketPrinted), logger); err != nil {
 		return nil, fmt.Errorf("failed to add OnTicketPrinted handler: %w", err)
 	}
-	if _, err := ep.AddHandler(cqrs.NewEventHandler("ops_read_model.OnTicketReceiptIssued", opsBookings.OnTicketReceiptIssued)); err != nil {
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnTicketReceiptIssued", opsBookings.OnTicketReceiptIssued), logger); err != nil {
 		return nil, fmt.Errorf("failed to add OnTicketReceiptIssued handler: %w", err)
 	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnTicketTransferred", opsBookings.OnTicketTransferred), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnTicketTransferred handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("ops_read_model.OnTicketCheckedIn", opsBookings.OnTicketCheckedIn), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnTicketCheckedIn handler: %w", err)
+	}
+
+	if err := addEventHandler(ep, cqrs.NewEventHandler("show_availability_read_model.OnBookingMade", showAvailability.OnBookingMade), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnBookingMade handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("show_availability_read_model.OnSeatsHeld", showAvailability.OnSeatsHeld), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnSeatsHeld handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("show_availability_read_model.OnSeatHoldExpired", showAvailability.OnSeatHoldExpired), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnSeatHoldExpired handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("show_availability_read_model.OnSeatsReleased", showAvailability.OnSeatsReleased), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnSeatsReleased handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("show_availability_read_model.OnBookingCanceled", showAvailability.OnBookingCanceled), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnBookingCanceled handler: %w", err)
+	}
+	if err := addEventHandler(ep, cqrs.NewEventHandler("show_availability_read_model.OnTicketCheckedIn", showAvailability.OnTicketCheckedIn), logger); err != nil {
+		return nil, fmt.Errorf("failed to add OnTicketCheckedIn handler: %w", err)
+	}
 
 	cp, err := cqrs.NewCommandProcessorWithConfig(router, commandProcessorConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create command processor: %w", err)
 	}
 
-	if _, err := cp.AddHandler(cqrs.NewCommandHandler("RefundTicket", commandHandlers.RefundTicketHandler)); err != nil {
+	if err := addCommandHandler(cp, cqrs.NewCommandHandler("RefundTicket", commandHandlers.RefundTicketHandler), logger); err != nil {
 		return nil, fmt.Errorf("failed to add RefundTicket handler: %w", err)
 	}
+	if err := addCommandHandler(cp, cqrs.NewCommandHandler("TransferTicket", commandHandlers.TransferTicketHandler), logger); err != nil {
+		return nil, fmt.Errorf("failed to add TransferTicket handler: %w", err)
+	}
+
+	// after all other handlers, so each of them gets its dead letter handler
+	addDeadLetterHandlers(router, deadLetterSubscriber, deadLetters)
 
 	return &Router{router}, nil
 }
 
+// newRouter adds the router level middlewares before any handler is added. Middlewares wrap the ones added
+// after them, so the dead letter middleware and Recoverer must come before the retry policies of handlers.
+func newRouter(publisher message.Publisher, logger watermill.LoggerAdapter) *message.Router {
+	router := message.NewDefaultRouter(logger)
+	useMiddlewares(router, publisher)
+
+	return router
+}
+
 func (r Router) Run(ctx context.Context) error {
 	return r.router.Run(ctx)
 }
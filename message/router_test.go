@@ -0,0 +1,97 @@
+package message
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRouter_retries_before_dead_lettering(t *testing.T) {
+	const maxRetries = 3
+
+	testCases := []struct {
+		Name               string
+		Failures           int
+		ExpectedAttempts   int
+		ExpectDeadLettered bool
+	}{
+		{
+			Name:             "succeeds_on_last_retry",
+			Failures:         maxRetries,
+			ExpectedAttempts: maxRetries + 1,
+		},
+		{
+			Name:               "retries_exhausted",
+			Failures:           maxRetries + 1,
+			ExpectedAttempts:   maxRetries + 1,
+			ExpectDeadLettered: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			logger := watermill.NopLogger{}
+			pubSub := gochannel.NewGoChannel(gochannel.Config{}, logger)
+			defer pubSub.Close()
+
+			router := newRouter(pubSub, logger)
+
+			var attempts atomic.Int32
+			handled := make(chan struct{}, 1)
+
+			// added like the handlers of NewRouter, after the router level middlewares
+			h := router.AddConsumerHandler("flaky", "topic", pubSub, func(msg *message.Message) error {
+				defer func() {
+					if int(attempts.Load()) >= tc.ExpectedAttempts {
+						handled <- struct{}{}
+					}
+				}()
+
+				if int(attempts.Add(1)) <= tc.Failures {
+					return errors.New("temporary failure")
+				}
+				return nil
+			})
+			h.AddMiddleware(RetryPolicy{
+				MaxRetries:      maxRetries,
+				InitialInterval: time.Millisecond,
+				MaxInterval:     time.Millisecond,
+				Multiplier:      1,
+			}.Middlewares(logger)...)
+
+			deadLetters, err := pubSub.Subscribe(t.Context(), DeadLetterTopic("flaky"))
+			require.NoError(t, err)
+
+			go func() {
+				_ = router.Run(t.Context())
+			}()
+			defer router.Close()
+			<-router.Running()
+
+			require.NoError(t, pubSub.Publish("topic", message.NewMessage(watermill.NewUUID(), []byte("{}"))))
+
+			select {
+			case <-handled:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("handler was called %d times, expected %d", attempts.Load(), tc.ExpectedAttempts)
+			}
+
+			select {
+			case msg := <-deadLetters:
+				msg.Ack()
+				assert.True(t, tc.ExpectDeadLettered, "message dead lettered before all retries")
+			case <-time.After(200 * time.Millisecond):
+				assert.False(t, tc.ExpectDeadLettered, "message not dead lettered after all retries")
+			}
+
+			assert.Equal(t, tc.ExpectedAttempts, int(attempts.Load()))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package printing
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// pdfPage collects the content stream of a single page PDF.
+// Only the standard fonts are used, so nothing has to be embedded, and the output is plain ASCII,
+// which makes it safe to upload through text APIs.
+type pdfPage struct {
+	width, height float64
+	content       bytes.Buffer
+}
+
+// pdfFonts are the standard fonts available in content streams by their resource names.
+var pdfFonts = []struct {
+	resource string
+	baseFont string
+}{
+	{resource: "F1", baseFont: "Helvetica"},
+	{resource: "F2", baseFont: "Helvetica-Bold"},
+	{resource: "F3", baseFont: "Courier"},
+}
+
+const (
+	pdfFontRegular = "F1"
+	pdfFontBold    = "F2"
+	pdfFontMono    = "F3"
+)
+
+func newPDFPage(width, height float64) *pdfPage {
+	return &pdfPage{width: width, height: height}
+}
+
+// text draws s with its baseline starting at x, y, measured from the bottom left corner of the page.
+func (p *pdfPage) text(x, y float64, font string, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, pdfNumber(size), pdfNumber(x), pdfNumber(y), pdfString(s))
+}
+
+func (p *pdfPage) fillRect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", pdfNumber(x), pdfNumber(y), pdfNumber(width), pdfNumber(height))
+}
+
+func (p *pdfPage) strokeRect(x, y, width, height, lineWidth float64) {
+	fmt.Fprintf(
+		&p.content, "%s w %s %s %s %s re S\n",
+		pdfNumber(lineWidth), pdfNumber(x), pdfNumber(y), pdfNumber(width), pdfNumber(height),
+	)
+}
+
+// bytes returns the complete PDF file.
+func (p *pdfPage) bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+	}
+
+	var fonts strings.Builder
+	for i, font := range pdfFonts {
+		fmt.Fprintf(&fonts, "/%s %d 0 R ", font.resource, 5+i)
+	}
+	objects = append(objects, fmt.Sprintf(
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents 4 0 R >>",
+		pdfNumber(p.width), pdfNumber(p.height), fonts.String(),
+	))
+	objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", p.content.Len(), p.content.String()))
+	for _, font := range pdfFonts {
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.baseFont,
+		))
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xrefOffset := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	return out.Bytes()
+}
+
+// pdfNumber formats f with at most two decimals, which is more precise than any printer.
+func pdfNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsiEncoding, characters outside of Latin-1 are replaced with "?".
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7F:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r < 0x20:
+			// control characters have no glyphs
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// wrapText splits s into lines of at most maxChars characters, breaking at spaces where possible.
+func wrapText(s string, maxChars int) []string {
+	var lines []string
+	var line []rune
+
+	for _, word := range strings.Fields(s) {
+		runes := []rune(word)
+		for len(runes) > 0 {
+			space := 0
+			if len(line) > 0 {
+				space = 1
+			}
+
+			if len(line)+space+len(runes) <= maxChars {
+				if space == 1 {
+					line = append(line, ' ')
+				}
+				line = append(line, runes...)
+				runes = nil
+				continue
+			}
+
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = nil
+				continue
+			}
+
+			// the word alone is longer than a line
+			line = append(line, runes[:maxChars]...)
+			runes = runes[maxChars:]
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+
+	return lines
+}
@@ -0,0 +1,150 @@
+// Package printing renders ticket documents from html/template templates.
+package printing
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"tickets/entities"
+	"tickets/qrcode"
+)
+
+//go:embed templates/*.html
+var embeddedTemplates embed.FS
+
+const (
+	defaultTemplateName = "default"
+	qrCodeModuleSize    = 4
+)
+
+// TicketDocument is the data available to ticket templates.
+type TicketDocument struct {
+	TicketID    string
+	HolderEmail string
+	Price       entities.Money
+	Revision    int
+
+	// Seat is nil without reserved seating, Show is nil for tickets booked outside our system.
+	Seat *entities.Seat
+	Show *entities.Show
+
+	// Token is the signed ticket token, it's rendered as QRCode too.
+	Token  string
+	QRCode template.HTML
+}
+
+// Renderer renders tickets with the template of the show, the template of the venue or the default template,
+// whichever exists first. Templates are named after their file: "show-<show ID>.html", "venue-<venue>.html"
+// and "default.html", where the venue name is lowercased with everything but letters and digits replaced by "-".
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// NewRenderer loads the embedded templates and then templates from dirs, a template from a later source
+// replaces the template of the same name.
+func NewRenderer(dirs ...fs.FS) (Renderer, error) {
+	templates := map[string]*template.Template{}
+
+	for _, fsys := range append([]fs.FS{mustSub(embeddedTemplates, "templates")}, dirs...) {
+		if err := loadTemplates(fsys, templates); err != nil {
+			return Renderer{}, err
+		}
+	}
+
+	if _, ok := templates[defaultTemplateName]; !ok {
+		return Renderer{}, errors.New("missing default ticket template")
+	}
+
+	return Renderer{templates: templates}, nil
+}
+
+func loadTemplates(fsys fs.FS, templates map[string]*template.Template) error {
+	files, err := fs.Glob(fsys, "*.html")
+	if err != nil {
+		return fmt.Errorf("could not list ticket templates: %w", err)
+	}
+
+	for _, file := range files {
+		tmpl, err := template.New(file).Funcs(templateFuncs).ParseFS(fsys, file)
+		if err != nil {
+			return fmt.Errorf("could not parse ticket template %s: %w", file, err)
+		}
+
+		templates[strings.TrimSuffix(path.Base(file), ".html")] = tmpl
+	}
+
+	return nil
+}
+
+var templateFuncs = template.FuncMap{
+	"formatTime": formatTime,
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("Monday, 2 January 2006, 15:04 MST")
+}
+
+// RenderTicket renders the document to HTML, the QR code is generated from the token.
+func (r Renderer) RenderTicket(doc TicketDocument) (string, error) {
+	qr, err := qrcode.Encode([]byte(doc.Token))
+	if err != nil {
+		return "", fmt.Errorf("could not encode QR code of ticket %s: %w", doc.TicketID, err)
+	}
+	// the SVG is generated by us, it has no user content
+	doc.QRCode = template.HTML(qr.SVG(qrCodeModuleSize))
+
+	tmpl := r.template(doc.Show)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, doc); err != nil {
+		return "", fmt.Errorf("could not render ticket %s with template %s: %w", doc.TicketID, tmpl.Name(), err)
+	}
+
+	return buf.String(), nil
+}
+
+func (r Renderer) template(show *entities.Show) *template.Template {
+	if show != nil {
+		if tmpl, ok := r.templates["show-"+show.ShowID.String()]; ok {
+			return tmpl
+		}
+		if tmpl, ok := r.templates["venue-"+VenueTemplateName(show.Venue)]; ok {
+			return tmpl
+		}
+	}
+
+	return r.templates[defaultTemplateName]
+}
+
+// VenueTemplateName returns the venue part of the venue template name, "Main Hall" has "venue-main-hall.html".
+func VenueTemplateName(venue string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(venue)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
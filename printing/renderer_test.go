@@ -0,0 +1,125 @@
+package printing_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+	"tickets/printing"
+)
+
+func TestRenderer_RenderTicket(t *testing.T) {
+	renderer, err := printing.NewRenderer()
+	require.NoError(t, err)
+
+	doc := printing.TicketDocument{
+		TicketID:    uuid.NewString(),
+		HolderEmail: "holder@example.com",
+		Price:       entities.MustNewMoney("30.00", "EUR"),
+		Seat:        &entities.Seat{Section: "A", Row: "1", Number: 7},
+		Show: &entities.Show{
+			ShowID:    uuid.New(),
+			Title:     "Summer <Concert>",
+			Venue:     "Main Hall",
+			StartTime: time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC),
+		},
+		Token: "v1.key.payload.signature",
+	}
+
+	html, err := renderer.RenderTicket(doc)
+	require.NoError(t, err)
+
+	assert.Contains(t, html, doc.TicketID)
+	assert.Contains(t, html, "holder@example.com")
+	assert.Contains(t, html, "30.00 EUR")
+	assert.Contains(t, html, "Section A, Row 1, Seat 7")
+	assert.Contains(t, html, "Summer &lt;Concert&gt;", "show details should be escaped")
+	assert.Contains(t, html, "Main Hall")
+	assert.Contains(t, html, "Monday, 10 June 2024, 20:00 UTC")
+	assert.Contains(t, html, "v1.key.payload.signature")
+	assert.Contains(t, html, "<svg", "QR code should be rendered")
+
+	t.Run("without_show", func(t *testing.T) {
+		doc := doc
+		doc.Show = nil
+		doc.Seat = nil
+
+		html, err := renderer.RenderTicket(doc)
+		require.NoError(t, err)
+		assert.Contains(t, html, doc.TicketID)
+		assert.NotContains(t, html, "Main Hall")
+	})
+}
+
+func TestRenderer_template_selection(t *testing.T) {
+	showID := uuid.New()
+
+	renderer, err := printing.NewRenderer(fstest.MapFS{
+		"venue-main-hall.html":              {Data: []byte(`venue {{ .TicketID }}`)},
+		"show-" + showID.String() + ".html": {Data: []byte(`show {{ .TicketID }}`)},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name     string
+		Show     *entities.Show
+		Expected string
+	}{
+		{
+			Name:     "show_template",
+			Show:     &entities.Show{ShowID: showID, Venue: "Main Hall"},
+			Expected: "show ticket",
+		},
+		{
+			Name:     "venue_template",
+			Show:     &entities.Show{ShowID: uuid.New(), Venue: "Main  Hall!"},
+			Expected: "venue ticket",
+		},
+		{
+			Name:     "default_template",
+			Show:     &entities.Show{ShowID: uuid.New(), Venue: "Other Venue"},
+			Expected: "<!DOCTYPE html>",
+		},
+		{
+			Name:     "no_show",
+			Expected: "<!DOCTYPE html>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			html, err := renderer.RenderTicket(printing.TicketDocument{TicketID: "ticket", Show: tc.Show, Token: "token"})
+			require.NoError(t, err)
+			assert.Contains(t, html, tc.Expected)
+		})
+	}
+}
+
+func TestNewRenderer_overrides_default_template(t *testing.T) {
+	renderer, err := printing.NewRenderer(fstest.MapFS{
+		"default.html": {Data: []byte(`custom {{ .HolderEmail }}`)},
+	})
+	require.NoError(t, err)
+
+	html, err := renderer.RenderTicket(printing.TicketDocument{HolderEmail: "<b>@example.com", Token: "token"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom &lt;b&gt;@example.com", html)
+}
+
+func TestNewRenderer_invalid_template(t *testing.T) {
+	_, err := printing.NewRenderer(fstest.MapFS{
+		"default.html": {Data: []byte(`{{ .TicketID `)},
+	})
+	assert.Error(t, err)
+}
+
+func TestVenueTemplateName(t *testing.T) {
+	assert.Equal(t, "main-hall", printing.VenueTemplateName("Main Hall"))
+	assert.Equal(t, "o2-arena", printing.VenueTemplateName(" O2 -- Arena! "))
+	assert.Equal(t, "", printing.VenueTemplateName("!!"))
+}
@@ -0,0 +1,117 @@
+package printing
+
+import (
+	"fmt"
+
+	"tickets/qrcode"
+)
+
+// A4 in points, the ticket is drawn at the top of the page so it can be cut out.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 40
+	pdfPadding    = 20
+
+	pdfQRModuleSize = 3
+
+	// pdfAverageCharWidth approximates the width of Helvetica characters relative to the font size,
+	// it's used to wrap long titles.
+	pdfAverageCharWidth = 0.55
+	pdfMonoCharWidth    = 0.6
+)
+
+// RenderTicketPDF renders the document as a single page PDF, it has the same information as the HTML ticket.
+// The layout is the same for all shows, templates apply only to HTML tickets.
+func (r Renderer) RenderTicketPDF(doc TicketDocument) (string, error) {
+	qr, err := qrcode.Encode([]byte(doc.Token))
+	if err != nil {
+		return "", fmt.Errorf("could not encode QR code of ticket %s: %w", doc.TicketID, err)
+	}
+
+	page := newPDFPage(pdfPageWidth, pdfPageHeight)
+
+	boxLeft := float64(pdfMargin)
+	boxWidth := float64(pdfPageWidth - 2*pdfMargin)
+	boxTop := float64(pdfPageHeight - pdfMargin)
+
+	qrSize := float64((qr.Size() + 2*qrcode.QuietZone) * pdfQRModuleSize)
+	qrLeft := boxLeft + boxWidth - pdfPadding - qrSize
+	drawQRCode(page, qr, qrLeft+qrcode.QuietZone*pdfQRModuleSize, boxTop-pdfPadding-qrcode.QuietZone*pdfQRModuleSize)
+
+	textLeft := boxLeft + pdfPadding
+	textWidth := qrLeft - textLeft - pdfPadding
+	y := boxTop - pdfPadding
+
+	line := func(font string, size float64, s string) {
+		y -= size * 1.3
+		page.text(textLeft, y, font, size, s)
+	}
+
+	title := "Ticket"
+	if doc.Show != nil {
+		title = doc.Show.Title
+	}
+	const titleSize = 18
+	for _, titleLine := range wrapText(title, int(textWidth/(titleSize*pdfAverageCharWidth))) {
+		line(pdfFontBold, titleSize, titleLine)
+	}
+
+	if doc.Show != nil {
+		y -= 4
+		line(pdfFontRegular, 12, doc.Show.Venue)
+		line(pdfFontRegular, 12, formatTime(doc.Show.StartTime))
+	}
+
+	field := func(label, value string) {
+		y -= 8
+		line(pdfFontBold, 9, label)
+		line(pdfFontRegular, 12, value)
+	}
+
+	field("Holder", doc.HolderEmail)
+	if doc.Seat != nil {
+		field("Seat", doc.Seat.String())
+	}
+	field("Price", doc.Price.String())
+	field("Ticket ID", doc.TicketID)
+
+	// the token goes below the QR code when the details are shorter
+	y = min(y, boxTop-pdfPadding-qrSize) - 6
+
+	const tokenSize = 6.5
+	tokenChars := int((boxWidth - 2*pdfPadding) / (tokenSize * pdfMonoCharWidth))
+	for _, tokenLine := range wrapText(doc.Token, tokenChars) {
+		y -= tokenSize * 1.3
+		page.text(textLeft, y, pdfFontMono, tokenSize, tokenLine)
+	}
+
+	boxBottom := y - pdfPadding
+	page.strokeRect(boxLeft, boxBottom, boxWidth, boxTop-boxBottom, 1.5)
+
+	return string(page.bytes()), nil
+}
+
+// drawQRCode draws the symbol with its top left corner at x, y, adjacent dark modules of a row are one rectangle.
+func drawQRCode(page *pdfPage, qr *qrcode.Code, x, y float64) {
+	for row := 0; row < qr.Size(); row++ {
+		for col := 0; col < qr.Size(); {
+			if !qr.Dark(col, row) {
+				col++
+				continue
+			}
+
+			start := col
+			for col < qr.Size() && qr.Dark(col, row) {
+				col++
+			}
+
+			page.fillRect(
+				x+float64(start*pdfQRModuleSize),
+				y-float64((row+1)*pdfQRModuleSize),
+				float64((col-start)*pdfQRModuleSize),
+				pdfQRModuleSize,
+			)
+		}
+	}
+}
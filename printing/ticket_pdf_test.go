@@ -0,0 +1,97 @@
+package printing_test
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+	"tickets/printing"
+)
+
+func TestRenderer_RenderTicketPDF(t *testing.T) {
+	renderer, err := printing.NewRenderer()
+	require.NoError(t, err)
+
+	doc := printing.TicketDocument{
+		TicketID:    uuid.NewString(),
+		HolderEmail: "holder@example.com",
+		Price:       entities.MustNewMoney("30.00", "EUR"),
+		Seat:        &entities.Seat{Section: "A", Row: "1", Number: 7},
+		Show: &entities.Show{
+			ShowID:    uuid.New(),
+			Title:     "Summer Concert (Live)",
+			Venue:     "Main Hall",
+			StartTime: time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC),
+		},
+		Token: "v1.key.payload.signature",
+	}
+
+	pdf, err := renderer.RenderTicketPDF(doc)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+
+	assert.Contains(t, pdf, "("+doc.TicketID+")")
+	assert.Contains(t, pdf, "(holder@example.com)")
+	assert.Contains(t, pdf, "(30.00 EUR)")
+	assert.Contains(t, pdf, "(Section A, Row 1, Seat 7)")
+	assert.Contains(t, pdf, `(Summer Concert \(Live\))`, "parentheses should be escaped")
+	assert.Contains(t, pdf, "(Main Hall)")
+	assert.Contains(t, pdf, "(Monday, 10 June 2024, 20:00 UTC)")
+	assert.Contains(t, pdf, "(v1.key.payload.signature)")
+	assert.Contains(t, pdf, " re f\n", "QR code should be drawn")
+
+	for _, r := range pdf {
+		if r > 0x7F {
+			t.Fatalf("PDF should be ASCII, found %q", r)
+		}
+	}
+
+	assertPDFCrossReferences(t, pdf)
+
+	t.Run("without_show", func(t *testing.T) {
+		doc := doc
+		doc.Show = nil
+		doc.Seat = nil
+
+		pdf, err := renderer.RenderTicketPDF(doc)
+		require.NoError(t, err)
+		assert.Contains(t, pdf, "("+doc.TicketID+")")
+		assert.NotContains(t, pdf, "Main Hall")
+		assertPDFCrossReferences(t, pdf)
+	})
+}
+
+var xrefEntry = regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`)
+
+// assertPDFCrossReferences checks that the offsets in the cross-reference table point to the objects,
+// readers rely on them to find objects.
+func assertPDFCrossReferences(t *testing.T, pdf string) {
+	t.Helper()
+
+	entries := xrefEntry.FindAllStringSubmatch(pdf, -1)
+	require.NotEmpty(t, entries)
+
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(entry[1])
+		require.NoError(t, err)
+
+		assert.True(
+			t, strings.HasPrefix(pdf[offset:], strconv.Itoa(i+1)+" 0 obj\n"),
+			"xref entry %d should point to its object", i+1,
+		)
+	}
+
+	startXref := pdf[strings.LastIndex(pdf, "startxref\n")+len("startxref\n"):]
+	xrefOffset, err := strconv.Atoi(strings.TrimSuffix(startXref, "\n%%EOF\n"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pdf[xrefOffset:], "xref\n"))
+}
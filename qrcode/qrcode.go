@@ -0,0 +1,516 @@
+// Package qrcode encodes short byte strings, like signed ticket tokens, as QR codes (ISO/IEC 18004).
+//
+// Only what printed tickets need is supported: byte mode, error correction level M and versions 1 to 10,
+// which is up to 213 bytes of data.
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrDataTooLong = errors.New("data too long for a QR code")
+
+const (
+	minVersion = 1
+	maxVersion = 10
+)
+
+// QuietZone is the light border around the symbol required by scanners, in modules.
+const QuietZone = 4
+
+// eccCodewordsPerBlock and numBlocks are the error correction level M layout of versions 1 to 10.
+var (
+	eccCodewordsPerBlock = [maxVersion + 1]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	numBlocks            = [maxVersion + 1]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+)
+
+// formatBitsM is the error correction level M indicator of the format information.
+const formatBitsM = 0
+
+// Code is an encoded QR code symbol.
+type Code struct {
+	version  int
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// Encode encodes data in byte mode using the smallest version that fits.
+func Encode(data []byte) (*Code, error) {
+	version := 0
+	for v := minVersion; v <= maxVersion; v++ {
+		if dataBitsNeeded(len(data), v) <= numDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrDataTooLong, len(data))
+	}
+
+	codewords := addErrorCorrection(encodeData(data, version), version)
+
+	c := newCode(version)
+	c.drawFunctionPatterns()
+	c.drawCodewords(codewords)
+
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		// masks are XORed, applying the same mask again reverts it
+		c.applyMask(mask)
+	}
+
+	c.applyMask(bestMask)
+	c.drawFormatBits(bestMask)
+
+	return c, nil
+}
+
+// Size is the width and height of the symbol in modules, without the quiet zone.
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark reports whether the module at column x and row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// SVG renders the symbol with its quiet zone, each module is moduleSize pixels wide.
+func (c *Code) SVG(moduleSize int) string {
+	dimension := c.size + 2*QuietZone
+
+	var path strings.Builder
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+
+	return fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="%d" height="%d" fill="#ffffff"/><path d="%s" fill="#000000"/></svg>`,
+		dimension*moduleSize, dimension*moduleSize, dimension, dimension,
+		dimension, dimension, path.String(),
+	)
+}
+
+func newCode(version int) *Code {
+	size := version*4 + 17
+
+	c := &Code{
+		version:  version,
+		size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+	for i := range c.modules {
+		c.modules[i] = make([]bool, size)
+		c.function[i] = make([]bool, size)
+	}
+
+	return c
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinderPattern(3, 3)
+	c.drawFinderPattern(c.size-4, 3)
+	c.drawFinderPattern(3, c.size-4)
+
+	positions := alignmentPatternPositions(c.version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// the corners with finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignmentPattern(x, y)
+		}
+	}
+
+	// reserves the format areas, they are drawn again once the mask is chosen
+	c.drawFormatBits(0)
+	c.drawVersionBits()
+}
+
+// drawFinderPattern draws the finder pattern centered at x, y together with its separator.
+func (c *Code) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.size || yy < 0 || yy >= c.size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+
+	// around the top left finder pattern
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	// split between the other two finder patterns
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.size-15+i, bit(bits, i))
+	}
+	c.setFunction(8, c.size-8, true)
+}
+
+func (c *Code) drawVersionBits() {
+	if c.version < 7 {
+		return
+	}
+
+	bits := versionBits(c.version)
+	for i := 0; i < 18; i++ {
+		a, b := c.size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords fills the data area in the zigzag order, two columns at a time from the bottom right corner.
+// Modules left over after the last codeword are remainder bits and stay light.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// skips the vertical timing pattern
+			right = 5
+		}
+		for vertical := 0; vertical < c.size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = c.size - 1 - vertical
+				}
+				if c.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y][x] = bit(int(codewords[i/8]), 7-i%8)
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.function[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			c.modules[y][x] = c.modules[y][x] != invert
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to scan, the mask with the lowest score is used.
+func (c *Code) penalty() int {
+	const (
+		penaltyRun        = 3
+		penaltyBlock      = 3
+		penaltyFinderLike = 40
+		penaltyBalance    = 10
+	)
+
+	result := 0
+
+	lines := make([][]bool, 0, 2*c.size)
+	for y := 0; y < c.size; y++ {
+		lines = append(lines, c.modules[y])
+	}
+	for x := 0; x < c.size; x++ {
+		column := make([]bool, c.size)
+		for y := 0; y < c.size; y++ {
+			column[y] = c.modules[y][x]
+		}
+		lines = append(lines, column)
+	}
+
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	for _, line := range lines {
+		run := 1
+		for i := 1; i <= len(line); i++ {
+			if i < len(line) && line[i] == line[i-1] {
+				run++
+				continue
+			}
+			if run >= 5 {
+				result += penaltyRun + run - 5
+			}
+			run = 1
+		}
+
+		for _, pattern := range finderLike {
+			for i := 0; i+len(pattern) <= len(line); i++ {
+				if equal(line[i:i+len(pattern)], pattern) {
+					result += penaltyFinderLike
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.size && y+1 < c.size {
+				color := c.modules[y][x]
+				if c.modules[y][x+1] == color && c.modules[y+1][x] == color && c.modules[y+1][x+1] == color {
+					result += penaltyBlock
+				}
+			}
+		}
+	}
+
+	total := c.size * c.size
+	// each 5% of deviation from half dark modules
+	deviation := abs(dark*20-total*10) / total
+	result += deviation * penaltyBalance
+
+	return result
+}
+
+// encodeData encodes data in byte mode and pads it to the data capacity of the version.
+func encodeData(data []byte, version int) []byte {
+	capacity := numDataCodewords(version)
+
+	var bb bitBuffer
+	bb.append(0b0100, 4)
+	bb.append(len(data), charCountBits(version))
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	// terminator, then zeros up to a byte boundary
+	bb.append(0, min(4, capacity*8-bb.len()))
+	bb.append(0, (8-bb.len()%8)%8)
+
+	codewords := bb.bytes()
+	for pad := byte(0xEC); len(codewords) < capacity; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+
+	return codewords
+}
+
+// addErrorCorrection splits data into blocks, adds error correction codewords to each block and interleaves them.
+func addErrorCorrection(data []byte, version int) []byte {
+	blocks := numBlocks[version]
+	eccLen := eccCodewordsPerBlock[version]
+	divisor := reedSolomonDivisor(eccLen)
+
+	// the last blocks are one codeword longer when the data doesn't split evenly
+	shortBlockLen := len(data) / blocks
+	numShortBlocks := blocks - len(data)%blocks
+
+	dataBlocks := make([][]byte, blocks)
+	eccBlocks := make([][]byte, blocks)
+	offset := 0
+	for i := 0; i < blocks; i++ {
+		blockLen := shortBlockLen
+		if i >= numShortBlocks {
+			blockLen++
+		}
+		dataBlocks[i] = data[offset : offset+blockLen]
+		eccBlocks[i] = reedSolomonRemainder(dataBlocks[i], divisor)
+		offset += blockLen
+	}
+
+	result := make([]byte, 0, numRawCodewords(version))
+	for i := 0; i <= shortBlockLen; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+func dataBitsNeeded(dataLen int, version int) int {
+	return 4 + charCountBits(version) + dataLen*8
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// numRawCodewords is the number of codewords that fit the modules left after the function patterns.
+func numRawCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		modules -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules / 8
+}
+
+func numDataCodewords(version int) int {
+	return numRawCodewords(version) - eccCodewordsPerBlock[version]*numBlocks[version]
+}
+
+// alignmentPatternPositions returns the row and column coordinates of the alignment pattern centers.
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+
+	positions := make([]int, numAlign)
+	positions[0] = 6
+	for i, pos := numAlign-1, version*4+17-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+
+	return positions
+}
+
+// formatBits is the BCH(15,5) code of the error correction level and mask, XORed with the fixed format mask.
+func formatBits(mask int) int {
+	data := formatBitsM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionBits is the BCH(18,6) code of the version.
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+func bit(x int, i int) bool {
+	return (x>>i)&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func equal(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) append(value int, length int) {
+	for i := length - 1; i >= 0; i-- {
+		b.bits = append(b.bits, bit(value, i))
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) bytes() []byte {
+	result := make([]byte, len(b.bits)/8)
+	for i, set := range b.bits {
+		if set {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// "HELLO WORLD" encoded in version 1-M, from the ISO/IEC 18004 worked example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+
+	ecc := reedSolomonRemainder(data, reedSolomonDivisor(10))
+
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+}
+
+func TestNumDataCodewords(t *testing.T) {
+	expected := []int{16, 28, 44, 64, 86, 108, 124, 154, 182, 216}
+
+	for version := minVersion; version <= maxVersion; version++ {
+		assert.Equal(t, expected[version-1], numDataCodewords(version), "version %d", version)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	expected := []int{
+		0b101010000010010,
+		0b101000100100101,
+		0b101111001111100,
+		0b101101101001011,
+		0b100010111111001,
+		0b100000011001110,
+		0b100111110010111,
+		0b100101010100000,
+	}
+
+	for mask, bits := range expected {
+		assert.Equal(t, bits, formatBits(mask), "mask %d", mask)
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	assert.Equal(t, 0x07C94, versionBits(7))
+	assert.Equal(t, 0x0A4D3, versionBits(10))
+}
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		dataLen         int
+		expectedVersion int
+	}{
+		{dataLen: 1, expectedVersion: 1},
+		{dataLen: 14, expectedVersion: 1},
+		{dataLen: 15, expectedVersion: 2},
+		{dataLen: 122, expectedVersion: 7},
+		{dataLen: 213, expectedVersion: 10},
+	}
+
+	for _, tc := range testCases {
+		c, err := Encode(bytes.Repeat([]byte("a"), tc.dataLen))
+		require.NoError(t, err)
+
+		assert.Equal(t, tc.expectedVersion, c.version, "%d bytes", tc.dataLen)
+		assert.Equal(t, tc.expectedVersion*4+17, c.Size())
+
+		// finder pattern centers and the dark module
+		assert.True(t, c.Dark(3, 3))
+		assert.True(t, c.Dark(c.Size()-4, 3))
+		assert.True(t, c.Dark(3, c.Size()-4))
+		assert.True(t, c.Dark(8, c.Size()-8))
+	}
+}
+
+func TestEncode_too_long(t *testing.T) {
+	_, err := Encode(bytes.Repeat([]byte("a"), 214))
+	assert.True(t, errors.Is(err, ErrDataTooLong))
+}
+
+func TestCode_SVG(t *testing.T) {
+	c, err := Encode([]byte("ticket"))
+	require.NoError(t, err)
+
+	svg := c.SVG(4)
+
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="116" height="116" viewBox="0 0 29 29"`))
+	// top left module of the finder pattern, shifted by the quiet zone
+	assert.Contains(t, svg, "M4,4h1v1h-1z")
+}
@@ -2,40 +2,52 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	stdHTTP "net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ThreeDotsLabs/go-event-driven/v2/common/log"
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
-	"github.com/redis/go-redis/v9"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	"golang.org/x/sync/errgroup"
 
 	"tickets/db"
+	"tickets/entities"
 	ticketsHttp "tickets/http"
 	"tickets/message"
 	"tickets/message/command"
 	"tickets/message/event"
 	"tickets/message/outbox"
 	"tickets/observability"
+	"tickets/printing"
 )
 
+const releaseExpiredSeatHoldsInterval = 5 * time.Second
+
 type Service struct {
 	db            *sqlx.DB
 	echoRouter    *echo.Echo
 	msgsRouter    *message.Router
 	dataLake      db.DataLake
 	opsBookings   db.OpsBookingReadModel
+	holds         db.HoldRepository
+	exchangeRates db.ExchangeRateRepository
 	traceProvider *tracesdk.TracerProvider
 }
 
 func New(
 	sqldb *sqlx.DB,
-	rdb *redis.Client,
+	broker message.Broker,
 	spreadsheetsAPI message.SpreadsheetsAPI,
 	receiptsService message.ReceiptsService,
 	paymentsService message.PaymentsService,
@@ -47,31 +59,63 @@ func New(
 
 	publisher := log.CorrelationPublisherDecorator{
 		Publisher: observability.TracingPublisherDecorator{
-			Publisher: message.NewRedisPublisher(rdb, logger),
+			Publisher: broker.Publisher(),
 		},
 	}
 
 	eventBus := event.NewEventBus(publisher)
-	epConfig := event.NewProcessorConfig(rdb, logger)
-	cpConfig := command.NewProcessorConfig(rdb, logger)
+	epConfig := event.NewProcessorConfig(broker.Subscriber, logger)
+	cpConfig := command.NewProcessorConfig(broker.Subscriber, logger)
 
 	commandBus := command.NewCommandBus(publisher)
 
 	tickets := db.NewTicketRepository(sqldb)
 	shows := db.NewShowRepository(sqldb)
 	bookings := db.NewBookingRepository(sqldb)
-	eHandlers := event.NewHandlers(fileAPI, spreadsheetsAPI, receiptsService, tickets, shows, deadNationAPI, eventBus)
-	cHandlers := command.NewHandlers(receiptsService, paymentsService, eventBus)
+	holds := db.NewHoldRepository(sqldb)
+	waitlist := db.NewWaitlistRepository(sqldb)
+
+	ticketSigner, err := ticketSignerFromEnv()
+	if err != nil {
+		return Service{}, err
+	}
+
+	ticketRenderer, err := ticketRendererFromEnv()
+	if err != nil {
+		return Service{}, err
+	}
+
+	eHandlers := event.NewHandlers(fileAPI, spreadsheetsAPI, receiptsService, tickets, shows, bookings, waitlist, deadNationAPI, ticketSigner, ticketRenderer, eventBus, commandBus)
+	cHandlers := command.NewHandlers(receiptsService, paymentsService, tickets, eventBus)
 
 	opsBookings := db.NewOpsBookingReadModel(sqldb)
+	showAvailability := db.NewShowAvailabilityReadModel(sqldb)
+	exchangeRates := db.NewExchangeRateRepository(sqldb)
+	promoCodes := db.NewPromoCodeRepository(sqldb)
 
 	subscriber := outbox.NewPostgresSubscriber(sqldb, logger)
-	eventsSplitterSubscriber := message.NewRedisSubscriber(rdb, "svc-tickets.events_splitter", logger)
-	dataLakeSubscriber := message.NewRedisSubscriber(rdb, "svc-tickets.store_to_data_lake", logger)
+	eventsSplitterSubscriber, err := broker.Subscriber("svc-tickets.events_splitter")
+	if err != nil {
+		return Service{}, fmt.Errorf("failed to create events splitter subscriber: %w", err)
+	}
+	dataLakeSubscriber, err := broker.Subscriber("svc-tickets.store_to_data_lake")
+	if err != nil {
+		return Service{}, fmt.Errorf("failed to create data lake subscriber: %w", err)
+	}
 	dataLake := db.NewDataLake(sqldb)
+	deadLetterSubscriber, err := broker.Subscriber("svc-tickets.dead_letters")
+	if err != nil {
+		return Service{}, fmt.Errorf("failed to create dead letter subscriber: %w", err)
+	}
+	deadLetters := db.NewDeadLetterRepository(sqldb)
 
-	echoRouter := ticketsHttp.NewHttpRouter(eventBus, commandBus, tickets, shows, bookings, opsBookings)
-	msgsRouter, err := message.NewRouter(subscriber, publisher, epConfig, cpConfig, eHandlers, cHandlers, opsBookings, logger, sqldb, eventsSplitterSubscriber, dataLakeSubscriber, dataLake)
+	refundPolicy, err := refundPolicyFromEnv()
+	if err != nil {
+		return Service{}, err
+	}
+
+	echoRouter := ticketsHttp.NewHttpRouter(eventBus, commandBus, tickets, shows, bookings, holds, waitlist, opsBookings, showAvailability, exchangeRates, promoCodes, refundPolicy, ticketSigner, deadLetters, message.NewDeadLetterReplayer(publisher))
+	msgsRouter, err := message.NewRouter(subscriber, publisher, epConfig, cpConfig, eHandlers, cHandlers, opsBookings, showAvailability, logger, sqldb, eventsSplitterSubscriber, dataLakeSubscriber, dataLake, deadLetterSubscriber, deadLetters)
 	if err != nil {
 		return Service{}, fmt.Errorf("failed to create message router: %w", err)
 	}
@@ -82,6 +126,8 @@ func New(
 		msgsRouter:    msgsRouter,
 		dataLake:      dataLake,
 		opsBookings:   opsBookings,
+		holds:         holds,
+		exchangeRates: exchangeRates,
 		traceProvider: traceProvider,
 	}, nil
 }
@@ -91,6 +137,10 @@ func (s Service) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to initialize database schema: %w", err)
 	}
 
+	if err := s.loadExchangeRatesFile(ctx, os.Getenv("EXCHANGE_RATES_FILE")); err != nil {
+		return fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
@@ -108,6 +158,10 @@ func (s Service) Run(ctx context.Context) error {
 		return nil
 	})
 
+	g.Go(func() error {
+		return s.releaseExpiredSeatHolds(ctx)
+	})
+
 	g.Go(func() error {
 		<-ctx.Done()
 		return s.echoRouter.Shutdown(ctx)
@@ -120,3 +174,138 @@ func (s Service) Run(ctx context.Context) error {
 
 	return g.Wait()
 }
+
+func (s Service) releaseExpiredSeatHolds(ctx context.Context) error {
+	ticker := time.NewTicker(releaseExpiredSeatHoldsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		released, err := s.holds.ReleaseExpiredHolds(ctx)
+		if err != nil {
+			slog.Error("Failed to release expired seat holds", "error", err)
+			continue
+		}
+
+		if released > 0 {
+			slog.Info("Released expired seat holds", "count", released)
+		}
+	}
+}
+
+// loadExchangeRatesFile adds exchange rates from a .csv or .json file, the file is optional.
+func (s Service) loadExchangeRatesFile(ctx context.Context, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var rates []entities.ExchangeRate
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		rates, err = entities.ParseExchangeRatesCSV(file)
+	case ".json":
+		rates, err = entities.ParseExchangeRatesJSON(file)
+	default:
+		return fmt.Errorf("unsupported exchange rates file extension %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	if err := s.exchangeRates.AddRates(ctx, rates); err != nil {
+		return err
+	}
+
+	slog.Info("Loaded exchange rates", "file", path, "count", len(rates))
+
+	return nil
+}
+
+// refundPolicyFromEnv reads REFUND_FULL_REFUND_DAYS and REFUND_PARTIAL_PERCENTAGE, unset variables keep the defaults.
+func refundPolicyFromEnv() (entities.RefundPolicy, error) {
+	policy := entities.DefaultRefundPolicy()
+
+	if days := os.Getenv("REFUND_FULL_REFUND_DAYS"); days != "" {
+		d, err := strconv.Atoi(days)
+		if err != nil {
+			return entities.RefundPolicy{}, fmt.Errorf("invalid REFUND_FULL_REFUND_DAYS: %w", err)
+		}
+		policy.FullRefundPeriod = time.Duration(d) * 24 * time.Hour
+	}
+
+	if percentage := os.Getenv("REFUND_PARTIAL_PERCENTAGE"); percentage != "" {
+		p, err := strconv.Atoi(percentage)
+		if err != nil {
+			return entities.RefundPolicy{}, fmt.Errorf("invalid REFUND_PARTIAL_PERCENTAGE: %w", err)
+		}
+		policy.PartialRefundPercentage = p
+	}
+
+	if err := policy.Validate(); err != nil {
+		return entities.RefundPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+// ticketSignerFromEnv reads TICKET_SIGNING_KEYS. Only with TICKET_SIGNING_EPHEMERAL_KEY=true it can be left unset,
+// tickets are signed with a random key then, which is lost on restart, so it's only good for development and tests.
+func ticketSignerFromEnv() (entities.TicketSigner, error) {
+	var keys []entities.TicketSigningKey
+
+	if value := os.Getenv("TICKET_SIGNING_KEYS"); value != "" {
+		var err error
+		keys, err = entities.ParseTicketSigningKeys(value)
+		if err != nil {
+			return entities.TicketSigner{}, fmt.Errorf("invalid TICKET_SIGNING_KEYS: %w", err)
+		}
+	} else {
+		ephemeral := false
+		if value := os.Getenv("TICKET_SIGNING_EPHEMERAL_KEY"); value != "" {
+			var err error
+			ephemeral, err = strconv.ParseBool(value)
+			if err != nil {
+				return entities.TicketSigner{}, fmt.Errorf("invalid TICKET_SIGNING_EPHEMERAL_KEY: %w", err)
+			}
+		}
+		if !ephemeral {
+			return entities.TicketSigner{}, errors.New("TICKET_SIGNING_KEYS is not set, set TICKET_SIGNING_EPHEMERAL_KEY=true to use a random key in development")
+		}
+
+		slog.Warn("TICKET_SIGNING_KEYS not set, tickets are signed with an ephemeral key")
+
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return entities.TicketSigner{}, fmt.Errorf("could not generate ticket signing key: %w", err)
+		}
+		keys = []entities.TicketSigningKey{{ID: "ephemeral", Secret: secret}}
+	}
+
+	return entities.NewTicketSigner(keys)
+}
+
+// ticketRendererFromEnv loads ticket templates from TICKET_TEMPLATES_DIR on top of the embedded ones.
+func ticketRendererFromEnv() (printing.Renderer, error) {
+	var dirs []fs.FS
+	if dir := os.Getenv("TICKET_TEMPLATES_DIR"); dir != "" {
+		dirs = append(dirs, os.DirFS(dir))
+	}
+
+	renderer, err := printing.NewRenderer(dirs...)
+	if err != nil {
+		return printing.Renderer{}, fmt.Errorf("invalid TICKET_TEMPLATES_DIR: %w", err)
+	}
+
+	return renderer, nil
+}
@@ -0,0 +1,59 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"tickets/entities"
+	ticketsHttp "tickets/http"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBookingCancellationReleasesSeats(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+	deadNationID := uuid.New()
+
+	createShow(t, fixtures.DB, showID, deadNationID, 1, "Show With Canceled Booking")
+
+	statusCode, body := bookTickets(t, showID, 1, "canceled-booking@example.com")
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var booking ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(body, &booking))
+
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:  uuid.NewString(),
+		BookingID: booking.BookingID.String(),
+		Status:    "confirmed",
+		Price: entities.Money{
+			Amount:   entities.MustParseDecimal("20.00"),
+			Currency: "EUR",
+		},
+		CustomerEmail: "canceled-booking@example.com",
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+
+	assertTicketStoredInRepository(t, fixtures.DB, ticket)
+
+	require.Equal(t, http.StatusAccepted, cancelBooking(t, booking.BookingID))
+	assert.Equal(t, http.StatusConflict, cancelBooking(t, booking.BookingID))
+
+	assertReceiptForTicketVoided(t, fixtures.ReceiptsService, ticket.TicketID)
+	assertPaymentRefunded(t, fixtures.PaymentsService, ticket.TicketID)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		_, ok := fixtures.SpreadsheetsAPI.FindRowByTicketID("dead-nation-bookings-to-cancel", booking.BookingID.String())
+		assert.True(t, ok, "booking %s not added to Dead Nation cancellation sheet", booking.BookingID)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	statusCode, _ = bookTickets(t, showID, 1, "next-customer@example.com")
+	assert.Equal(t, http.StatusCreated, statusCode)
+}
@@ -0,0 +1,113 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"tickets/entities"
+	ticketsHttp "tickets/http"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var printedTicketTokenRegexp = regexp.MustCompile(`<code[^>]*>([^<]+)</code>`)
+
+func testTicketCheckIn(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+	createShow(t, fixtures.DB, showID, uuid.New(), 1, "Check-in Show")
+
+	statusCode, body := bookTickets(t, showID, 1, "check-in@example.com")
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var booking ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(body, &booking))
+
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:      uuid.NewString(),
+		BookingID:     booking.BookingID.String(),
+		Status:        "confirmed",
+		Price:         entities.MustNewMoney("30.00", "EUR"),
+		CustomerEmail: "check-in@example.com",
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+	assertTicketPrinted(t, fixtures.FileAPI, ticket.TicketID)
+
+	call, ok := fixtures.FileAPI.FindPutCallByFileID(entities.TicketFileName(ticket.TicketID, 0))
+	require.True(t, ok)
+	match := printedTicketTokenRegexp.FindStringSubmatch(call.FileContent)
+	require.Len(t, match, 2, "printed ticket should contain the token")
+	token := match[1]
+
+	statusCode, body = sendCheckIn(t, token[:len(token)-2]+"xx")
+	require.Equal(t, http.StatusUnprocessableEntity, statusCode)
+	assertCheckInRejected(t, body, entities.CheckInRejectionInvalidToken)
+
+	statusCode, body = sendCheckIn(t, token)
+	require.Equal(t, http.StatusOK, statusCode)
+
+	var checkIn entities.TicketCheckIn
+	require.NoError(t, json.Unmarshal(body, &checkIn))
+	assert.Equal(t, ticket.TicketID, checkIn.TicketID)
+	assert.Equal(t, showID, checkIn.ShowID)
+	assert.NotNil(t, checkIn.CheckedInAt)
+
+	statusCode, body = sendCheckIn(t, token)
+	require.Equal(t, http.StatusConflict, statusCode)
+	assertCheckInRejected(t, body, entities.CheckInRejectionAlreadyCheckedIn)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		resp, err := http.Get("http://localhost:8080/api/ops/shows/" + showID.String() + "/attendance")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		var attendance entities.ShowAttendance
+		if !assert.NoError(t, json.NewDecoder(resp.Body).Decode(&attendance)) {
+			return
+		}
+		assert.Equal(t, 1, attendance.Booked)
+		assert.Equal(t, 1, attendance.CheckedIn)
+
+		opsBooking, ok := getOpsBooking(t, booking.BookingID)
+		if assert.True(t, ok, "ops booking not found") {
+			assert.False(t, opsBooking.Tickets[ticket.TicketID].CheckedInAt.IsZero())
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func sendCheckIn(t *testing.T, token string) (int, []byte) {
+	t.Helper()
+
+	payload, err := json.Marshal(ticketsHttp.PostCheckInRequest{Token: token})
+	require.NoError(t, err)
+
+	resp, err := http.Post("http://localhost:8080/api/check-in", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, body
+}
+
+func assertCheckInRejected(t *testing.T, body []byte, code entities.CheckInRejectionCode) {
+	t.Helper()
+
+	var rejection struct {
+		Error entities.CheckInRejection `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(body, &rejection))
+	assert.Equal(t, code, rejection.Error.Code)
+}
@@ -27,6 +27,10 @@ func TestComponent(t *testing.T) {
 		testMultipleBookingsUntilExhausted(t, fixtures)
 	})
 
+	t.Run("book_tickets_idempotency", func(t *testing.T) {
+		testBookTicketsIdempotency(t, fixtures)
+	})
+
 	t.Run("ticket_refund_voids_receipt", func(t *testing.T) {
 		testTicketRefundVoidsReceipt(t, fixtures)
 	})
@@ -34,4 +38,44 @@ func TestComponent(t *testing.T) {
 	t.Run("ticket_refund_idempotency", func(t *testing.T) {
 		testTicketRefundIdempotency(t, fixtures)
 	})
+
+	t.Run("partial_ticket_refund", func(t *testing.T) {
+		testPartialTicketRefund(t, fixtures)
+	})
+
+	t.Run("ticket_refund_policy", func(t *testing.T) {
+		testTicketRefundPolicy(t, fixtures)
+	})
+
+	t.Run("partial_ticket_refunds_within_policy", func(t *testing.T) {
+		testPartialTicketRefundsWithinPolicy(t, fixtures)
+	})
+
+	t.Run("ticket_refund_within_policy_then_show_canceled", func(t *testing.T) {
+		testTicketRefundWithinPolicyThenShowCanceled(t, fixtures)
+	})
+
+	t.Run("ticket_transfer", func(t *testing.T) {
+		testTicketTransfer(t, fixtures)
+	})
+
+	t.Run("ticket_check_in", func(t *testing.T) {
+		testTicketCheckIn(t, fixtures)
+	})
+
+	t.Run("show_cancellation_refunds_tickets", func(t *testing.T) {
+		testShowCancellationRefundsTickets(t, fixtures)
+	})
+
+	t.Run("ticket_confirmed_after_show_cancellation_is_refunded", func(t *testing.T) {
+		testTicketConfirmedAfterShowCancellationIsRefunded(t, fixtures)
+	})
+
+	t.Run("booking_cancellation_releases_seats", func(t *testing.T) {
+		testBookingCancellationReleasesSeats(t, fixtures)
+	})
+
+	t.Run("dead_letters", func(t *testing.T) {
+		testDeadLetters(t, fixtures)
+	})
 }
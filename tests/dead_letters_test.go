@@ -0,0 +1,109 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	watermillMessage "github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tickets/entities"
+)
+
+func testDeadLetters(t *testing.T, fixtures *TestFixtures) {
+	publisher := fixtures.Broker.Publisher()
+
+	correlationID := "dead-letter-test-" + uuid.NewString()
+
+	// the command can't be unmarshaled, so it fails after all retries
+	msg := watermillMessage.NewMessage(uuid.NewString(), []byte("not json"))
+	msg.Metadata.Set("name", "TransferTicket")
+	msg.Metadata.Set("correlation_id", correlationID)
+	require.NoError(t, publisher.Publish("commands.TransferTicket", msg))
+
+	deadLetter := waitForDeadLetter(t, "TransferTicket", msg.UUID, uuid.Nil)
+	assert.Equal(t, "commands.TransferTicket", deadLetter.Topic)
+	assert.Equal(t, correlationID, deadLetter.CorrelationID)
+	assert.Equal(t, "not json", deadLetter.Payload)
+	assert.NotEmpty(t, deadLetter.Error)
+	assert.Equal(t, "TransferTicket", deadLetter.Metadata["name"])
+
+	resp, err := http.Get("http://localhost:8080/api/ops/dead-letters/" + deadLetter.ID.String())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the replayed command fails again, so it's dead lettered again
+	assert.Equal(t, http.StatusAccepted, sendDeadLetterRequest(t, http.MethodPost, deadLetter.ID, "/replay"))
+	replayed := waitForDeadLetter(t, "TransferTicket", msg.UUID, deadLetter.ID)
+	assert.Equal(t, "commands.TransferTicket", replayed.Topic)
+
+	assert.Equal(t, http.StatusNotFound, sendDeadLetterRequest(t, http.MethodPost, deadLetter.ID, "/replay"))
+
+	assert.Equal(t, http.StatusNoContent, sendDeadLetterRequest(t, http.MethodDelete, replayed.ID, ""))
+	assert.Equal(t, http.StatusNotFound, sendDeadLetterRequest(t, http.MethodDelete, replayed.ID, ""))
+
+	// the command is valid JSON, but doesn't match the contract, so it's quarantined without calling the handler
+	invalid := watermillMessage.NewMessage(uuid.NewString(), []byte(`{"header": {}, "ticket_id": 1}`))
+	invalid.Metadata.Set("name", "TransferTicket")
+	require.NoError(t, publisher.Publish("commands.TransferTicket", invalid))
+
+	quarantined := waitForDeadLetter(t, "TransferTicket", invalid.UUID, uuid.Nil)
+	assert.Contains(t, quarantined.Error, "doesn't match the contract")
+	assert.Contains(t, quarantined.Error, "$.ticket_id: expected string, got integer")
+}
+
+// waitForDeadLetter waits for the dead letter of the message, other than the skipped one.
+func waitForDeadLetter(t *testing.T, handler string, messageID string, skipID uuid.UUID) entities.DeadLetter {
+	t.Helper()
+
+	var deadLetter entities.DeadLetter
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		resp, err := http.Get("http://localhost:8080/api/ops/dead-letters?handler=" + url.QueryEscape(handler))
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var deadLetters []entities.DeadLetter
+		if !assert.NoError(t, json.Unmarshal(body, &deadLetters)) {
+			return
+		}
+
+		for _, d := range deadLetters {
+			if d.MessageID == messageID && d.ID != skipID {
+				deadLetter = d
+				return
+			}
+		}
+
+		t.Errorf("dead letter of message %s not found", messageID)
+	}, 20*time.Second, 100*time.Millisecond)
+
+	return deadLetter
+}
+
+func sendDeadLetterRequest(t *testing.T, method string, id uuid.UUID, path string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, "http://localhost:8080/api/ops/dead-letters/"+id.String()+path, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
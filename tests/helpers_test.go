@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 
 	"tickets/adapters"
 	"tickets/db"
+	"tickets/entities"
 	ticketsHttp "tickets/http"
 
 	_ "github.com/lib/pq"
@@ -82,6 +84,87 @@ func sendTicketRefund(t *testing.T, ticketID string) {
 	_ = resp.Body.Close()
 }
 
+func sendTicketRefundRequest(t *testing.T, ticketID string, request ticketsHttp.PutTicketRefundRequest, idempotencyKey string) (int, []byte) {
+	t.Helper()
+
+	payload, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	httpReq, err := http.NewRequest(
+		http.MethodPut,
+		"http://localhost:8080/api/ticket-refund/"+ticketID,
+		bytes.NewBuffer(payload),
+	)
+	require.NoError(t, err)
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, body
+}
+
+func sendTicketTransfer(t *testing.T, ticketID string, customerEmail string) int {
+	t.Helper()
+
+	payload, err := json.Marshal(ticketsHttp.PutTicketTransferRequest{CustomerEmail: customerEmail})
+	require.NoError(t, err)
+
+	httpReq, err := http.NewRequest(
+		http.MethodPut,
+		"http://localhost:8080/api/tickets/"+ticketID+"/transfer",
+		bytes.NewBuffer(payload),
+	)
+	require.NoError(t, err)
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func cancelShow(t *testing.T, showID uuid.UUID) {
+	t.Helper()
+
+	httpReq, err := http.NewRequest(
+		http.MethodPost,
+		"http://localhost:8080/api/shows/"+showID.String()+"/cancel",
+		nil,
+	)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	_ = resp.Body.Close()
+}
+
+func cancelBooking(t *testing.T, bookingID uuid.UUID) int {
+	t.Helper()
+
+	httpReq, err := http.NewRequest(
+		http.MethodDelete,
+		"http://localhost:8080/api/bookings/"+bookingID.String(),
+		nil,
+	)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	return resp.StatusCode
+}
+
 func assertReceiptForTicketIssued(t *testing.T, receiptsService *adapters.ReceiptsServiceStub, ticket ticketsHttp.TicketStatusRequest) {
 	t.Helper()
 
@@ -155,7 +238,7 @@ func assertRowToSheetAdded(t *testing.T, spreadsheetsAPI *adapters.SpreadsheetsA
 		expectedRow := []string{
 			ticket.TicketID,
 			ticket.CustomerEmail,
-			ticket.Price.Amount,
+			ticket.Price.Amount.String(),
 			ticket.Price.Currency,
 		}
 
@@ -205,6 +288,17 @@ func assertTicketPrinted(t *testing.T, fileAPI *adapters.FilesAPIStub, ticketID
 		}
 
 		assert.Contains(t, call.FileContent, ticketID, "file content should contain ticket ID")
+		assert.Contains(t, call.FileContent, "<svg", "file content should contain the QR code")
+
+		pdfFileID := entities.TicketPDFFileName(ticketID, 0)
+
+		pdfCall, ok := fileAPI.FindPutCallByFileID(pdfFileID)
+		if !assert.True(t, ok, "PDF ticket file with ID %s not found", pdfFileID) {
+			return
+		}
+
+		assert.True(t, strings.HasPrefix(pdfCall.FileContent, "%PDF-"), "PDF file should start with the PDF header")
+		assert.Contains(t, pdfCall.FileContent, ticketID, "PDF file content should contain ticket ID")
 	}
 
 	assert.EventuallyWithT(t, condition, 10*time.Second, 100*time.Millisecond)
@@ -221,9 +315,22 @@ func createShow(t *testing.T, db *sqlx.DB, showID uuid.UUID, deadNationID uuid.U
 	require.NoError(t, err, "failed to create show")
 }
 
+func setShowStartTime(t *testing.T, db *sqlx.DB, showID uuid.UUID, startTime time.Time) {
+	t.Helper()
+
+	_, err := db.Exec(`UPDATE shows SET start_time = $1 WHERE show_id = $2`, startTime, showID)
+	require.NoError(t, err, "failed to update show start time")
+}
+
 func bookTickets(t *testing.T, showID uuid.UUID, numberOfTickets int, customerEmail string) (int, []byte) {
 	t.Helper()
 
+	return bookTicketsWithIdempotencyKey(t, showID, numberOfTickets, customerEmail, "")
+}
+
+func bookTicketsWithIdempotencyKey(t *testing.T, showID uuid.UUID, numberOfTickets int, customerEmail, idempotencyKey string) (int, []byte) {
+	t.Helper()
+
 	req := ticketsHttp.PostBookTicketsRequest{
 		ShowID:          showID,
 		NumberOfTickets: numberOfTickets,
@@ -241,6 +348,9 @@ func bookTickets(t *testing.T, showID uuid.UUID, numberOfTickets int, customerEm
 	require.NoError(t, err)
 
 	httpReq.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+	}
 
 	resp, err := http.DefaultClient.Do(httpReq)
 	require.NoError(t, err)
@@ -9,14 +9,14 @@ import (
 	"tickets/message"
 	"tickets/service"
 
+	"github.com/ThreeDotsLabs/watermill"
 	"github.com/jmoiron/sqlx"
-	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
 type TestFixtures struct {
 	DB              *sqlx.DB
-	RedisClient     *redis.Client
+	Broker          message.Broker
 	SpreadsheetsAPI *adapters.SpreadsheetsAPIStub
 	ReceiptsService *adapters.ReceiptsServiceStub
 	PaymentsService *adapters.PaymentsServiceStub
@@ -26,12 +26,19 @@ type TestFixtures struct {
 }
 
 func SetupComponentTest(t *testing.T) *TestFixtures {
+	if os.Getenv("TICKET_SIGNING_KEYS") == "" {
+		t.Setenv("TICKET_SIGNING_EPHEMERAL_KEY", "true")
+	}
+
 	db, err := sqlx.Open("postgres", os.Getenv("POSTGRES_URL"))
 	if err != nil {
 		panic(err)
 	}
 
-	rdb := message.NewRedisClient(os.Getenv("REDIS_ADDR"))
+	broker, err := message.NewBroker(message.BrokerKind(os.Getenv("MESSAGE_BROKER")), os.Getenv("REDIS_ADDR"), db, watermill.NopLogger{})
+	if err != nil {
+		panic(err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -42,7 +49,7 @@ func SetupComponentTest(t *testing.T) *TestFixtures {
 	deadNationStub := adapters.NewDeadNationStub()
 
 	go func() {
-		svc, err := service.New(db, rdb, spreadsheetsAPI, receiptsService, paymentsService, fileAPI, deadNationStub)
+		svc, err := service.New(db, broker, spreadsheetsAPI, receiptsService, paymentsService, fileAPI, deadNationStub)
 		if err != nil {
 			t.Errorf("failed to create service: %v", err)
 			return
@@ -55,7 +62,7 @@ func SetupComponentTest(t *testing.T) *TestFixtures {
 
 	return &TestFixtures{
 		DB:              db,
-		RedisClient:     rdb,
+		Broker:          broker,
 		SpreadsheetsAPI: spreadsheetsAPI,
 		ReceiptsService: receiptsService,
 		PaymentsService: paymentsService,
@@ -1,11 +1,15 @@
 package tests_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 
+	ticketsHttp "tickets/http"
+
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testBookingExceedsLimitReturns400(t *testing.T, fixtures *TestFixtures) {
@@ -42,3 +46,25 @@ func testMultipleBookingsUntilExhausted(t *testing.T, fixtures *TestFixtures) {
 
 	assert.Equal(t, 10, getBookedTicketsCount(t, fixtures.DB, showID))
 }
+
+func testBookTicketsIdempotency(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+	deadNationID := uuid.New()
+
+	createShow(t, fixtures.DB, showID, deadNationID, 10, "Test Show")
+
+	idempotencyKey := uuid.NewString()
+
+	statusCode, firstBody := bookTicketsWithIdempotencyKey(t, showID, 2, "retry@example.com", idempotencyKey)
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	statusCode, retriedBody := bookTicketsWithIdempotencyKey(t, showID, 2, "retry@example.com", idempotencyKey)
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var first, retried ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(firstBody, &first))
+	require.NoError(t, json.Unmarshal(retriedBody, &retried))
+
+	assert.Equal(t, first.BookingID, retried.BookingID)
+	assert.Equal(t, 2, getBookedTicketsCount(t, fixtures.DB, showID))
+}
@@ -0,0 +1,100 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"tickets/entities"
+	ticketsHttp "tickets/http"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testShowCancellationRefundsTickets(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+	deadNationID := uuid.New()
+
+	createShow(t, fixtures.DB, showID, deadNationID, 10, "Canceled Show")
+
+	statusCode, body := bookTickets(t, showID, 1, "canceled-show@example.com")
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var booking ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(body, &booking))
+
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:  uuid.NewString(),
+		BookingID: booking.BookingID.String(),
+		Status:    "confirmed",
+		Price: entities.Money{
+			Amount:   entities.MustParseDecimal("20.00"),
+			Currency: "EUR",
+		},
+		CustomerEmail: "canceled-show@example.com",
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+
+	assertTicketStoredInRepository(t, fixtures.DB, ticket)
+
+	cancelShow(t, showID)
+
+	assertReceiptForTicketVoided(t, fixtures.ReceiptsService, ticket.TicketID)
+	assertPaymentRefunded(t, fixtures.PaymentsService, ticket.TicketID)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		_, ok := fixtures.SpreadsheetsAPI.FindRowByTicketID("dead-nation-bookings-to-cancel", booking.BookingID.String())
+		assert.True(t, ok, "booking %s not added to Dead Nation cancellation sheet", booking.BookingID)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	statusCode, body = bookTickets(t, showID, 1, "too-late@example.com")
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+	assert.Contains(t, string(body), "show is canceled")
+}
+
+func testTicketConfirmedAfterShowCancellationIsRefunded(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+
+	createShow(t, fixtures.DB, showID, uuid.New(), 10, "Canceled Before Confirmation")
+
+	statusCode, body := bookTickets(t, showID, 1, "confirmed-after-cancel@example.com")
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var booking ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(body, &booking))
+
+	cancelShow(t, showID)
+
+	// the tickets of the show were refunded before this one was confirmed
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		_, ok := fixtures.SpreadsheetsAPI.FindRowByTicketID("dead-nation-bookings-to-cancel", booking.BookingID.String())
+		assert.True(t, ok, "booking %s not added to Dead Nation cancellation sheet", booking.BookingID)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:      uuid.NewString(),
+		BookingID:     booking.BookingID.String(),
+		Status:        "confirmed",
+		Price:         entities.MustNewMoney("20.00", "EUR"),
+		CustomerEmail: "confirmed-after-cancel@example.com",
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+
+	assertTicketStoredInRepository(t, fixtures.DB, ticket)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		payments := fixtures.PaymentsService.FindRefundedPayments(ticket.TicketID)
+		if assert.Len(t, payments, 1, "ticket of canceled show not refunded") {
+			assert.Equal(t, entities.RefundReasonShowCanceled, payments[0].Reason)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -15,7 +15,7 @@ func testTicketBooking(t *testing.T, fixtures *TestFixtures) {
 		TicketID: uuid.NewString(),
 		Status:   "confirmed",
 		Price: entities.Money{
-			Amount:   "50.30",
+			Amount:   entities.MustParseDecimal("50.30"),
 			Currency: "GBP",
 		},
 		CustomerEmail: "email@example.com",
@@ -37,7 +37,7 @@ func testTicketBookingIdempotency(t *testing.T, fixtures *TestFixtures) {
 		TicketID: uuid.NewString(),
 		Status:   "confirmed",
 		Price: entities.Money{
-			Amount:   "75.00",
+			Amount:   entities.MustParseDecimal("75.00"),
 			Currency: "EUR",
 		},
 		CustomerEmail: "idempotent@example.com",
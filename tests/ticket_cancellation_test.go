@@ -14,7 +14,7 @@ func testTicketCancellation(t *testing.T, fixtures *TestFixtures) {
 		TicketID: uuid.NewString(),
 		Status:   "canceled",
 		Price: entities.Money{
-			Amount:   "100.00",
+			Amount:   entities.MustParseDecimal("100.00"),
 			Currency: "USD",
 		},
 		CustomerEmail: "canceled@example.com",
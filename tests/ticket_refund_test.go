@@ -1,10 +1,17 @@
 package tests_test
 
 import (
+	"encoding/json"
+	"net/http"
 	"testing"
+	"time"
+
+	"tickets/entities"
+	ticketsHttp "tickets/http"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testTicketRefundVoidsReceipt(t *testing.T, fixtures *TestFixtures) {
@@ -29,3 +36,176 @@ func testTicketRefundIdempotency(t *testing.T, fixtures *TestFixtures) {
 	_, found = fixtures.PaymentsService.FindRefundedPayment(ticketID)
 	assert.True(t, found, "payment should be refunded exactly once (idempotency)")
 }
+
+func testPartialTicketRefund(t *testing.T, fixtures *TestFixtures) {
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:      uuid.NewString(),
+		Status:        "confirmed",
+		Price:         entities.MustNewMoney("50.00", "EUR"),
+		CustomerEmail: "partial-refund@example.com",
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+	assertTicketStoredInRepository(t, fixtures.DB, ticket)
+
+	tooMuch := entities.MustNewMoney("60.00", "EUR")
+	statusCode, _ := sendTicketRefundRequest(t, ticket.TicketID, ticketsHttp.PutTicketRefundRequest{
+		Amount: &tooMuch,
+		Reason: entities.RefundReasonGoodwill,
+	}, uuid.NewString())
+	require.Equal(t, http.StatusBadRequest, statusCode)
+
+	amount := entities.MustNewMoney("12.50", "EUR")
+	statusCode, _ = sendTicketRefundRequest(t, ticket.TicketID, ticketsHttp.PutTicketRefundRequest{
+		Amount: &amount,
+		Reason: entities.RefundReasonGoodwill,
+	}, uuid.NewString())
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		refundedPayment, ok := fixtures.PaymentsService.FindRefundedPayment(ticket.TicketID)
+		if !assert.True(t, ok, "payment not refunded") {
+			return
+		}
+
+		assert.Equal(t, entities.RefundReasonGoodwill, refundedPayment.Reason)
+		if assert.NotNil(t, refundedPayment.Amount) {
+			assert.Equal(t, "12.50 EUR", refundedPayment.Amount.String())
+		}
+
+		voidedReceipt, ok := fixtures.ReceiptsService.FindVoidedReceipt(ticket.TicketID)
+		if assert.True(t, ok, "receipt not voided") {
+			assert.Equal(t, entities.RefundReasonGoodwill, voidedReceipt.Reason)
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func testTicketRefundPolicy(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+
+	// the show starts now, so it's too late for refunds
+	createShow(t, fixtures.DB, showID, uuid.New(), 1, "Show Already Started")
+
+	statusCode, body := bookTickets(t, showID, 1, "refund-policy@example.com")
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var booking ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(body, &booking))
+
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:      uuid.NewString(),
+		BookingID:     booking.BookingID.String(),
+		Status:        "confirmed",
+		Price:         entities.MustNewMoney("20.00", "EUR"),
+		CustomerEmail: "refund-policy@example.com",
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+	assertTicketStoredInRepository(t, fixtures.DB, ticket)
+
+	statusCode, body = sendTicketRefundRequest(t, ticket.TicketID, ticketsHttp.PutTicketRefundRequest{}, uuid.NewString())
+	require.Equal(t, http.StatusUnprocessableEntity, statusCode)
+
+	var rejection struct {
+		Error entities.RefundRejection `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(body, &rejection))
+	assert.Equal(t, entities.RefundRejectionShowStarted, rejection.Error.Code)
+
+	statusCode, _ = sendTicketRefundRequest(t, ticket.TicketID, ticketsHttp.PutTicketRefundRequest{
+		PolicyOverride: &ticketsHttp.RefundPolicyOverride{
+			OverriddenBy: "ops@example.com",
+			Reason:       "customer couldn't attend because of a venue issue",
+		},
+	}, uuid.NewString())
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	assertPaymentRefunded(t, fixtures.PaymentsService, ticket.TicketID)
+}
+
+func bookTicketForRefundPolicy(t *testing.T, fixtures *TestFixtures, showID uuid.UUID, customerEmail string) ticketsHttp.TicketStatusRequest {
+	t.Helper()
+
+	// a day before the show only a part of the price is refunded
+	createShow(t, fixtures.DB, showID, uuid.New(), 1, "Show Tomorrow")
+	setShowStartTime(t, fixtures.DB, showID, time.Now().Add(24*time.Hour))
+
+	statusCode, body := bookTickets(t, showID, 1, customerEmail)
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var booking ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(body, &booking))
+
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:      uuid.NewString(),
+		BookingID:     booking.BookingID.String(),
+		Status:        "confirmed",
+		Price:         entities.MustNewMoney("20.00", "EUR"),
+		CustomerEmail: customerEmail,
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+	assertTicketStoredInRepository(t, fixtures.DB, ticket)
+
+	return ticket
+}
+
+func testPartialTicketRefundsWithinPolicy(t *testing.T, fixtures *TestFixtures) {
+	ticket := bookTicketForRefundPolicy(t, fixtures, uuid.New(), "partial-refunds-policy@example.com")
+
+	amount := entities.MustNewMoney("10.00", "EUR")
+	statusCode, _ := sendTicketRefundRequest(t, ticket.TicketID, ticketsHttp.PutTicketRefundRequest{
+		Amount: &amount,
+	}, uuid.NewString())
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	// each request is within 50% of the price, but not both together
+	statusCode, body := sendTicketRefundRequest(t, ticket.TicketID, ticketsHttp.PutTicketRefundRequest{
+		Amount: &amount,
+	}, uuid.NewString())
+	require.Equal(t, http.StatusUnprocessableEntity, statusCode)
+
+	var rejection struct {
+		Error entities.RefundRejection `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(body, &rejection))
+	assert.Equal(t, entities.RefundRejectionAmountExceedsPolicy, rejection.Error.Code)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Len(t, fixtures.PaymentsService.FindRefundedPayments(ticket.TicketID), 1)
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func testTicketRefundWithinPolicyThenShowCanceled(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+	ticket := bookTicketForRefundPolicy(t, fixtures, showID, "refund-then-show-canceled@example.com")
+
+	statusCode, _ := sendTicketRefundRequest(t, ticket.TicketID, ticketsHttp.PutTicketRefundRequest{}, uuid.NewString())
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		assert.Len(t, fixtures.PaymentsService.FindRefundedPayments(ticket.TicketID), 1)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	cancelShow(t, showID)
+
+	// the cancellation refunds the rest of the price, not the refund lowered by the policy again
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		payments := fixtures.PaymentsService.FindRefundedPayments(ticket.TicketID)
+		if !assert.Len(t, payments, 2) {
+			return
+		}
+
+		for _, payment := range payments {
+			if assert.NotNil(t, payment.Amount) {
+				assert.Equal(t, "10.00 EUR", payment.Amount.String())
+			}
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
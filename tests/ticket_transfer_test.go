@@ -0,0 +1,113 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"tickets/entities"
+	ticketsHttp "tickets/http"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTicketTransfer(t *testing.T, fixtures *TestFixtures) {
+	showID := uuid.New()
+	createShow(t, fixtures.DB, showID, uuid.New(), 1, "Ticket Transfer Show")
+
+	statusCode, body := bookTickets(t, showID, 1, "transfer-from@example.com")
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var booking ticketsHttp.PostBookTicketsResponse
+	require.NoError(t, json.Unmarshal(body, &booking))
+
+	ticket := ticketsHttp.TicketStatusRequest{
+		TicketID:      uuid.NewString(),
+		BookingID:     booking.BookingID.String(),
+		Status:        "confirmed",
+		Price:         entities.MustNewMoney("25.00", "EUR"),
+		CustomerEmail: "transfer-from@example.com",
+	}
+
+	sendTicketsStatus(t, ticketsHttp.TicketsStatusRequest{
+		Tickets: []ticketsHttp.TicketStatusRequest{ticket},
+	}, uuid.NewString())
+	assertTicketStoredInRepository(t, fixtures.DB, ticket)
+	assertTicketPrinted(t, fixtures.FileAPI, ticket.TicketID)
+
+	statusCode = sendTicketTransfer(t, uuid.NewString(), "transfer-to@example.com")
+	require.Equal(t, http.StatusNotFound, statusCode)
+
+	statusCode = sendTicketTransfer(t, ticket.TicketID, "not an email")
+	require.Equal(t, http.StatusBadRequest, statusCode)
+
+	statusCode = sendTicketTransfer(t, ticket.TicketID, "transfer-to@example.com")
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	assert.EventuallyWithT(t, func(t *assert.CollectT) {
+		var customerEmail string
+		err := fixtures.DB.Get(&customerEmail, `SELECT customer_email FROM tickets WHERE ticket_id = $1`, ticket.TicketID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "transfer-to@example.com", customerEmail)
+
+		call, ok := fixtures.FileAPI.FindPutCallByFileID(entities.TicketFileName(ticket.TicketID, 1))
+		if assert.True(t, ok, "transferred ticket not printed") {
+			assert.Contains(t, call.FileContent, "transfer-to@example.com")
+		}
+
+		opsBooking, ok := getOpsBooking(t, booking.BookingID)
+		if !assert.True(t, ok, "ops booking not found") {
+			return
+		}
+		opsTicket := opsBooking.Tickets[ticket.TicketID]
+		assert.Equal(t, "transfer-to@example.com", opsTicket.CustomerEmail)
+		assert.Equal(t, entities.TicketFileName(ticket.TicketID, 1), opsTicket.PrintedFileName)
+		assert.Equal(t, entities.TicketFileNames(ticket.TicketID, 1), opsTicket.PrintedFileNames)
+		assert.Subset(t, opsTicket.InvalidatedFileNames, entities.TicketFileNames(ticket.TicketID, 0))
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// transferring back and forth again isn't deduplicated as a retry of the first transfer
+	for i, customerEmail := range []string{"transfer-from@example.com", "transfer-to@example.com"} {
+		revision := i + 2
+
+		statusCode = sendTicketTransfer(t, ticket.TicketID, customerEmail)
+		require.Equal(t, http.StatusAccepted, statusCode)
+
+		assert.EventuallyWithT(t, func(t *assert.CollectT) {
+			call, ok := fixtures.FileAPI.FindPutCallByFileID(entities.TicketFileName(ticket.TicketID, revision))
+			if assert.True(t, ok, "ticket revision %d not printed", revision) {
+				assert.Contains(t, call.FileContent, customerEmail)
+			}
+		}, 10*time.Second, 100*time.Millisecond)
+	}
+}
+
+func getOpsBooking(t assert.TestingT, bookingID uuid.UUID) (entities.OpsBooking, bool) {
+	resp, err := http.Get("http://localhost:8080/api/ops/bookings/" + bookingID.String())
+	if !assert.NoError(t, err) {
+		return entities.OpsBooking{}, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return entities.OpsBooking{}, false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if !assert.NoError(t, err) {
+		return entities.OpsBooking{}, false
+	}
+
+	var opsBooking entities.OpsBooking
+	if !assert.NoError(t, json.Unmarshal(body, &opsBooking)) {
+		return entities.OpsBooking{}, false
+	}
+
+	return opsBooking, true
+}